@@ -4,15 +4,20 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math/rand"
+	"os"
+	"strings"
 	"time"
 
+	"github.com/gkwa/bouncingbeaver/internal/dynamodb"
 	"github.com/gkwa/bouncingbeaver/internal/logger"
 	"github.com/gkwa/bouncingbeaver/internal/models"
 )
 
 type Displayer struct {
-	logger *logger.Logger
+	logger   *logger.Logger
+	streamed int
 }
 
 func NewDisplayer(logger *logger.Logger) *Displayer {
@@ -33,13 +38,33 @@ func (d *Displayer) ShowProducts(products []models.Product, randomize bool) {
 		d.logger.Debug("Products randomized")
 	}
 
+	d.showJSON(products)
+}
+
+// ShowItems prints projected items as ShowProducts prints products, with
+// only the attributes each item holds.
+func (d *Displayer) ShowItems(items []map[string]interface{}, randomize bool) {
+	d.logger.Debug("Displaying items", "count", len(items), "randomize", randomize)
+
+	if randomize {
+		rand.Seed(time.Now().UnixNano())
+		rand.Shuffle(len(items), func(i, j int) {
+			items[i], items[j] = items[j], items[i]
+		})
+		d.logger.Debug("Items randomized")
+	}
+
+	d.showJSON(items)
+}
+
+func (d *Displayer) showJSON(value interface{}) {
 	// Create an encoder that doesn't escape HTML
 	var buf bytes.Buffer
 	encoder := json.NewEncoder(&buf)
 	encoder.SetEscapeHTML(false)
 	encoder.SetIndent("", "  ")
 
-	err := encoder.Encode(products)
+	err := encoder.Encode(value)
 	if err != nil {
 		d.logger.Error("Failed to marshal products to JSON", "error", err)
 		return
@@ -47,3 +72,179 @@ func (d *Displayer) ShowProducts(products []models.Product, randomize bool) {
 
 	fmt.Print(buf.String())
 }
+
+// StreamProduct prints one element of a JSON array as soon as it is
+// available. The output matches ShowProducts once EndStream is called.
+func (d *Displayer) StreamProduct(product models.Product) error {
+	return d.streamJSON(product)
+}
+
+// StreamItem prints a projected item as StreamProduct prints a product.
+func (d *Displayer) StreamItem(item map[string]interface{}) error {
+	return d.streamJSON(item)
+}
+
+func (d *Displayer) streamJSON(value interface{}) error {
+	// Elements sit one level deep in the array, so indent every line after
+	// the first by two spaces and write the first line's indent by hand
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("  ", "  ")
+
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Errorf("failed to marshal product to JSON: %w", err)
+	}
+
+	if d.streamed == 0 {
+		fmt.Print("[\n  ")
+	} else {
+		fmt.Print(",\n  ")
+	}
+	fmt.Print(string(bytes.TrimSuffix(buf.Bytes(), []byte("\n"))))
+
+	d.streamed++
+	return nil
+}
+
+// EndStream closes the array started by StreamProduct. When nothing was
+// streamed it prints null, as ShowProducts does for no products. It is not
+// called when reading fails, so that a partial array is not mistaken for a
+// complete result.
+func (d *Displayer) EndStream() {
+	d.logger.Debug("Displayed products", "count", d.streamed)
+
+	if d.streamed == 0 {
+		fmt.Println("null")
+		return
+	}
+	fmt.Print("\n]\n")
+}
+
+// ShowReadSummary prints what a live read returned and the read capacity it
+// consumed to stderr, keeping stdout for the items.
+func (d *Displayer) ShowReadSummary(count, scanned int, units float64) {
+	fmt.Fprintf(os.Stderr, "Read %d items (%d scanned), consuming %g RCU\n", count, scanned, units)
+}
+
+// ShowServing prints where serve-dynamodb is listening to stderr, so the
+// address is shown at any verbosity without mixing into piped output.
+func (d *Displayer) ShowServing(items int, table, address string) {
+	fmt.Fprintf(os.Stderr, "Serving %d items in table %s at http://%s\n", items, table, address)
+}
+
+// ShowWriteSummary prints what a put run wrote.
+func (d *Displayer) ShowWriteSummary(options dynamodb.WriteOptions, summary dynamodb.WriteSummary, skipped int) {
+	verb := "Wrote"
+	if options.DryRun {
+		verb = "Would write"
+	}
+
+	fmt.Printf("%s %d items to %s in %d batches (%d retries); %d failed, %d skipped\n",
+		verb, summary.Written, options.Table, summary.Batches, summary.Retries, summary.Failed, skipped)
+}
+
+// ShowMigrateProgress prints a running count of a migrate-html run to
+// stderr, keeping stdout for the summary.
+func (d *Displayer) ShowMigrateProgress(scanned int, summary dynamodb.MigrateSummary) {
+	fmt.Fprintf(os.Stderr, "Scanned %d items: %d migrated, %d unchanged, %d conflicts, %d failed\n",
+		scanned, summary.Migrated, summary.Unchanged, summary.Conflicts, summary.Failed)
+}
+
+// ShowMigrateSummary prints what a migrate-html run rewrote.
+func (d *Displayer) ShowMigrateSummary(options dynamodb.MigrateOptions, summary dynamodb.MigrateSummary) {
+	verb := "Migrated"
+	if options.DryRun {
+		verb = "Would migrate"
+	}
+
+	fmt.Printf("%s %d items in %s to %s (%d bytes to %d bytes); %d unchanged, %d conflicts, %d failed\n",
+		verb, summary.Migrated, options.Table, options.Encoding, summary.BytesBefore, summary.BytesAfter,
+		summary.Unchanged, summary.Conflicts, summary.Failed)
+}
+
+// ShowVerifySummary prints the result of the verification pass.
+func (d *Displayer) ShowVerifySummary(options dynamodb.MigrateOptions, scanned, unverified int) {
+	fmt.Printf("Verified %d items in %s; %d not stored as %s\n", scanned-unverified, options.Table, unverified, options.Encoding)
+}
+
+// ShowTTLReport prints how many items expire in each period, with a bar
+// scaled to the largest period.
+func (d *Displayer) ShowTTLReport(report *dynamodb.TTLReport) {
+	const barWidth = 40
+
+	buckets := report.Buckets()
+	largest := 0
+	for _, bucket := range buckets {
+		largest = max(largest, bucket.Count)
+	}
+
+	fmt.Printf("%d items, %d without a TTL, %d expired but not yet deleted (as of %s)\n",
+		report.Total, report.NoTTL, report.Expired, report.Now.Format(time.RFC3339))
+
+	for _, bucket := range buckets {
+		bar := strings.Repeat("#", max(bucket.Count*barWidth/largest, 1))
+		fmt.Printf("%s %8d %s\n", bucket.Start.Format(time.DateOnly), bucket.Count, bar)
+	}
+}
+
+// ShowTTLSummary prints what a ttl extend or purge run changed.
+func (d *Displayer) ShowTTLSummary(action string, options dynamodb.TTLOptions, summary dynamodb.TTLSummary) {
+	verb := "Extended"
+	if action == "purge" {
+		verb = "Purged"
+	}
+	if options.DryRun {
+		verb = "Would " + action
+	}
+
+	fmt.Printf("%s %d items in %s; %d skipped, %d conflicts, %d failed\n",
+		verb, summary.Updated, options.Table, summary.Skipped, summary.Conflicts, summary.Failed)
+}
+
+// ShowSizeReport prints size totals and then, largest first, each item at
+// or above the report's threshold with the attribute that dominates it and
+// what reading and writing it costs.
+func (d *Displayer) ShowSizeReport(report *dynamodb.SizeReport) {
+	average := 0.0
+	if report.Items > 0 {
+		average = float64(report.Bytes) / float64(report.Items)
+	}
+
+	fmt.Printf("%d items, %d bytes in total, %.0f bytes on average; largest %d bytes (%s); %d over the %d byte limit\n",
+		report.Items, report.Bytes, average, report.Largest.Bytes, report.Largest.ID, report.OverLimit, dynamodb.MaxItemSize)
+
+	near := report.Near()
+	if len(near) == 0 {
+		return
+	}
+
+	fmt.Printf("\n%-36s %10s %7s  %-24s %8s %8s %8s\n", "ID", "BYTES", "LIMIT", "LARGEST ATTRIBUTE", "RCU", "RCU(EC)", "WCU")
+	for _, size := range near {
+		name, bytes := size.Largest()
+		fmt.Printf("%-36s %10d %6.1f%%  %-24s %8g %8g %8g\n",
+			size.ID, size.Bytes, 100*float64(size.Bytes)/dynamodb.MaxItemSize,
+			fmt.Sprintf("%s (%.0f%%)", name, 100*float64(bytes)/float64(size.Bytes)),
+			size.ReadUnits(true), size.ReadUnits(false), size.WriteUnits())
+	}
+}
+
+// ShowConsistencyReport prints to w how many products were checked against
+// their product card and then each product's mismatches, marking the ones
+// that were repaired.
+func (d *Displayer) ShowConsistencyReport(report *dynamodb.ConsistencyReport, w io.Writer) {
+	fmt.Fprintf(w, "%d products, %d without a product card; %d with mismatches, %d attributes repaired, %d remaining\n",
+		report.Products, report.NoCard, len(report.Results), report.Repaired, report.Remaining)
+
+	for _, result := range report.Results {
+		fmt.Fprintf(w, "\n%s\n", result.ID)
+		for _, mismatch := range result.Mismatches {
+			repaired := ""
+			if mismatch.Repaired {
+				repaired = " (repaired)"
+			}
+			fmt.Fprintf(w, "  %-9s %s%s\n    stored: %q\n    html:   %q\n",
+				mismatch.Attribute, mismatch.Kind, repaired, mismatch.Stored, mismatch.HTML)
+		}
+	}
+}
@@ -1,41 +1,709 @@
 package app
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/gkwa/bouncingbeaver/internal/dynamodb"
 	"github.com/gkwa/bouncingbeaver/internal/logger"
+	"github.com/gkwa/bouncingbeaver/internal/memtable"
+	"github.com/gkwa/bouncingbeaver/internal/models"
 )
 
+// migrateProgressInterval is how many items migrate-html reads between
+// progress lines.
+const migrateProgressInterval = 500
+
+// ServeOptions declares the table serve-dynamodb exposes and its key
+// schema. SortKey is empty for a table with only a partition key.
+type ServeOptions struct {
+	Table        string
+	PartitionKey string
+	SortKey      string
+}
+
 type Processor struct {
 	logger   *logger.Logger
 	dynamodb *dynamodb.Client
 }
 
-func NewProcessor(verbosity int) *Processor {
+// NewProcessor returns a processor logging at verbosity. strictBase64 makes
+// rawHtml decoding accept only standard, padded base64 over zlib.
+func NewProcessor(verbosity int, strictBase64 bool) *Processor {
+	client := dynamodb.NewClient()
+	client.SetStrictBase64(strictBase64)
 	return &Processor{
 		logger:   logger.New(verbosity),
-		dynamodb: dynamodb.NewClient(),
+		dynamodb: client,
 	}
 }
 
-func (p *Processor) ProcessData(inputFile string, randomize bool) error {
-	p.logger.Info("Processing DynamoDB data", "input", inputFile)
+// ProcessData prints the items of inputFiles that pass the filter and
+// projection expressions of query.
+func (p *Processor) ProcessData(inputFiles []string, query dynamodb.QueryOptions, randomize bool) error {
+	p.logger.Info("Processing DynamoDB data", "input", strings.Join(inputFiles, ","), "filter", query.Filter, "projection", query.Projection)
 
-	sampleData, err := p.dynamodb.LoadData(inputFile)
+	filter, err := p.dynamodb.NewItemFilter(query)
 	if err != nil {
-		p.logger.Error("Failed to load data", "error", err, "input", inputFile)
+		p.logger.Error("Failed to parse expressions", "error", err)
 		return err
 	}
 
-	products, err := p.dynamodb.UnmarshalProducts(sampleData)
+	return p.displayFiles(inputFiles, filter, randomize)
+}
+
+// ProcessExport prints the items of a DynamoDB export directory that pass
+// the filter and projection expressions of query, and then checks the data
+// files against the export manifest.
+func (p *Processor) ProcessExport(exportDir string, query dynamodb.QueryOptions, randomize bool) error {
+	p.logger.Info("Processing DynamoDB export", "dir", exportDir, "filter", query.Filter, "projection", query.Projection)
+
+	filter, err := p.dynamodb.NewItemFilter(query)
 	if err != nil {
-		p.logger.Error("Failed to unmarshal products", "error", err)
+		p.logger.Error("Failed to parse expressions", "error", err)
 		return err
 	}
 
-	p.logger.Debug("Successfully unmarshaled products", "count", len(products))
+	return p.displayExport(exportDir, filter, randomize)
+}
+
+// ProcessPartiQL runs a PartiQL SELECT over the items of inputFiles, or of
+// exportDir when it is set, and prints the items it selects.
+func (p *Processor) ProcessPartiQL(inputFiles []string, exportDir string, statement string, parameters []types.AttributeValue, randomize bool) error {
+	p.logger.Info("Running PartiQL statement", "statement", statement, "parameters", len(parameters))
 
+	filter, err := p.dynamodb.NewSelectFilter(statement, parameters)
+	if err != nil {
+		p.logger.Error("Failed to parse statement", "error", err)
+		return err
+	}
+
+	if exportDir != "" {
+		return p.displayExport(exportDir, filter, randomize)
+	}
+	return p.displayFiles(inputFiles, filter, randomize)
+}
+
+func (p *Processor) displayFiles(inputFiles []string, filter *dynamodb.ItemFilter, randomize bool) error {
+	scanner := p.dynamodb.NewInputScanner(inputFiles...)
+	defer scanner.Close()
+	scanner.SetFilter(filter)
+
+	return p.display(scanner, filter.Projects(), randomize)
+}
+
+func (p *Processor) displayExport(exportDir string, filter *dynamodb.ItemFilter, randomize bool) error {
 	displayer := NewDisplayer(p.logger)
-	displayer.ShowProducts(products, randomize)
+
+	var products []models.Product
+	var items []map[string]interface{}
+	report, err := p.dynamodb.ReadExport(exportDir, func(item map[string]types.AttributeValue) error {
+		item, ok := filter.Apply(item)
+		if !ok {
+			return nil
+		}
+
+		if filter.Projects() {
+			values, err := p.dynamodb.UnmarshalItem(item)
+			if err != nil {
+				return err
+			}
+			if randomize {
+				items = append(items, values)
+				return nil
+			}
+			return displayer.StreamItem(values)
+		}
+
+		product, err := p.dynamodb.UnmarshalProduct(item)
+		if err != nil {
+			return err
+		}
+
+		if randomize {
+			products = append(products, product)
+			return nil
+		}
+		return displayer.StreamProduct(product)
+	})
+	if err != nil {
+		p.logger.Error("Failed to process export", "error", err, "dir", exportDir)
+		return err
+	}
+
+	p.logger.Info("Export processed", "files", len(report.Files), "items", report.ActualItems, "expected", report.ExpectedItems)
+
+	// A damaged export is reported without completing the output, so the
+	// items read are not taken for the whole table
+	if err := report.Err(); err != nil {
+		return err
+	}
+
+	switch {
+	case randomize && filter.Projects():
+		displayer.ShowItems(items, true)
+	case randomize:
+		displayer.ShowProducts(products, true)
+	default:
+		displayer.EndStream()
+	}
+
+	return nil
+}
+
+// ProcessScan prints the items of a live Scan. A parallel or checkpointed
+// scan is used when segments asks for one.
+func (p *Processor) ProcessScan(ctx context.Context, live dynamodb.LiveOptions, query dynamodb.QueryOptions, segments dynamodb.SegmentOptions, randomize bool) error {
+	p.logger.Info("Scanning DynamoDB table", "table", query.Table, "index", query.Index, "segments", segments.TotalSegments)
+
+	if err := p.dynamodb.Connect(ctx, live); err != nil {
+		p.logger.Error("Failed to connect", "error", err)
+		return err
+	}
+
+	if segments.TotalSegments <= 1 && segments.CheckpointFile == "" && !segments.Resume {
+		return p.displayLive(p.dynamodb.ScanTable(ctx, query), query.Projection != "", randomize)
+	}
+
+	scanner, err := p.dynamodb.ScanSegments(ctx, query, segments)
+	if err != nil {
+		p.logger.Error("Failed to start segmented scan", "error", err)
+		return err
+	}
+	defer scanner.Close()
+
+	return p.displayLive(scanner, query.Projection != "", randomize)
+}
+
+// ProcessQuery prints the items of a live Query.
+func (p *Processor) ProcessQuery(ctx context.Context, live dynamodb.LiveOptions, query dynamodb.QueryOptions, randomize bool) error {
+	p.logger.Info("Querying DynamoDB table", "table", query.Table, "index", query.Index, "key_condition", query.KeyCondition)
+
+	if err := p.dynamodb.Connect(ctx, live); err != nil {
+		p.logger.Error("Failed to connect", "error", err)
+		return err
+	}
+
+	return p.displayLive(p.dynamodb.QueryTable(ctx, query), query.Projection != "", randomize)
+}
+
+// ProcessPut writes the items read from inputFiles, or from exportDir when
+// it is set, to a table. Stream records only contribute their NewImage, so
+// replaying a stream never writes back an old or deleted item. An export
+// is checked against its manifest first, and nothing is written unless
+// every data file matches.
+func (p *Processor) ProcessPut(ctx context.Context, live dynamodb.LiveOptions, inputFiles []string, exportDir string, options dynamodb.WriteOptions) error {
+	p.logger.Info("Writing products", "table", options.Table, "dry_run", options.DryRun)
+
+	// Items are written while the data files are still being hashed, so the
+	// export is checked against its manifest before anything is written
+	if exportDir != "" {
+		if err := p.readInput(nil, exportDir, func(dynamodb.Record) error { return nil }); err != nil {
+			return err
+		}
+	}
+
+	if !options.DryRun {
+		if err := p.dynamodb.Connect(ctx, live); err != nil {
+			p.logger.Error("Failed to connect", "error", err)
+			return err
+		}
+	}
+
+	writer := p.dynamodb.NewBatchWriter(ctx, options)
+	skipped, invalid := 0, 0
+
+	put := func(record dynamodb.Record) error {
+		if record.StreamImage != "" && record.StreamImage != "NewImage" {
+			p.logger.Debug("Skipping stream image", "image", record.StreamImage, "event", record.EventName)
+			skipped++
+			return nil
+		}
+
+		item, err := p.dynamodb.RemarshalItem(record.Item)
+		if err != nil {
+			p.logger.Error("Failed to marshal product", "error", err)
+			invalid++
+			return nil
+		}
+		return writer.Put(item)
+	}
+
+	if err := p.readInput(inputFiles, exportDir, put); err != nil {
+		return err
+	}
+
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	summary := writer.Summary()
+	summary.Failed += invalid
+
+	NewDisplayer(p.logger).ShowWriteSummary(options, summary, skipped)
+
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d items failed to write to %s", summary.Failed, options.Table)
+	}
+	return nil
+}
+
+// ProcessMigrateHTML rewrites the rawHtml of every item in a table in the
+// encoding options names, printing progress as it goes. With verify set, a
+// second scan then checks that every item is stored in that encoding.
+func (p *Processor) ProcessMigrateHTML(ctx context.Context, live dynamodb.LiveOptions, query dynamodb.QueryOptions, segments dynamodb.SegmentOptions, options dynamodb.MigrateOptions, verify bool) error {
+	p.logger.Info("Migrating HTML", "table", options.Table, "encoding", options.Encoding, "dry_run", options.DryRun)
+
+	if err := p.dynamodb.Connect(ctx, live); err != nil {
+		p.logger.Error("Failed to connect", "error", err)
+		return err
+	}
+
+	displayer := NewDisplayer(p.logger)
+	migrator := p.dynamodb.NewHTMLMigrator(ctx, options)
+
+	err := p.scanItems(ctx, query, segments, func(item map[string]types.AttributeValue, scanned int) error {
+		if err := migrator.Migrate(item); err != nil {
+			return err
+		}
+		if scanned%migrateProgressInterval == 0 {
+			displayer.ShowMigrateProgress(scanned, migrator.Summary())
+		}
+		return nil
+	})
+	if err != nil {
+		p.logger.Error("Migration stopped", "error", err)
+		return err
+	}
+
+	summary := migrator.Summary()
+	displayer.ShowMigrateSummary(options, summary)
+
+	var unverified int
+	if verify && !options.DryRun {
+		// Checkpoints record the migration, so the verification always
+		// reads the whole table
+		unverified, err = p.verifyHTML(ctx, query, options)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case summary.Failed > 0:
+		return fmt.Errorf("%d items failed to migrate in %s", summary.Failed, options.Table)
+	case unverified > 0:
+		return fmt.Errorf("%d items in %s are not stored as %s", unverified, options.Table, options.Encoding)
+	}
+	return nil
+}
+
+// verifyHTML scans the table and counts the items whose rawHtml is not
+// stored in the target encoding or does not decode.
+func (p *Processor) verifyHTML(ctx context.Context, query dynamodb.QueryOptions, options dynamodb.MigrateOptions) (int, error) {
+	displayer := NewDisplayer(p.logger)
+	unverified := 0
+
+	var scanned int
+	err := p.scanItems(ctx, query, dynamodb.SegmentOptions{}, func(item map[string]types.AttributeValue, n int) error {
+		scanned = n
+		if err := p.dynamodb.VerifyHTML(item, options.Encoding); err != nil {
+			p.logger.Warn("Item failed verification", "id", itemID(item), "error", err)
+			unverified++
+		}
+		return nil
+	})
+	if err != nil {
+		p.logger.Error("Verification stopped", "error", err)
+		return 0, err
+	}
+
+	displayer.ShowVerifySummary(options, scanned, unverified)
+
+	return unverified, nil
+}
+
+// scanItems calls fn with each item of a live Scan and the number of items
+// read so far, using a parallel or checkpointed scan when segments asks for
+// one.
+func (p *Processor) scanItems(ctx context.Context, query dynamodb.QueryOptions, segments dynamodb.SegmentOptions, fn func(item map[string]types.AttributeValue, scanned int) error) error {
+	var scanner *dynamodb.ItemScanner
+	if segments.TotalSegments <= 1 && segments.CheckpointFile == "" && !segments.Resume {
+		scanner = p.dynamodb.ScanTable(ctx, query)
+	} else {
+		var err error
+		scanner, err = p.dynamodb.ScanSegments(ctx, query, segments)
+		if err != nil {
+			return err
+		}
+	}
+	defer scanner.Close()
+
+	scanned := 0
+	for scanner.Scan() {
+		scanned++
+		if err := fn(scanner.Record().Item, scanned); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func itemID(item map[string]types.AttributeValue) string {
+	if s, ok := item["id"].(*types.AttributeValueMemberS); ok {
+		return s.Value
+	}
+	return ""
+}
+
+// ProcessTTLReport prints a histogram of the expiry dates of the items read
+// from inputFiles, from exportDir, or, when query names a table, from a
+// live Scan.
+func (p *Processor) ProcessTTLReport(ctx context.Context, live dynamodb.LiveOptions, query dynamodb.QueryOptions, inputFiles []string, exportDir string, period dynamodb.TTLPeriod) error {
+	report := dynamodb.NewTTLReport(period, time.Now().UTC())
+
+	if err := p.readItems(ctx, live, query, inputFiles, exportDir, report.Add); err != nil {
+		return err
+	}
+
+	NewDisplayer(p.logger).ShowTTLReport(report)
+
+	return nil
+}
+
+// ProcessSize prints the size of the items read from inputFiles, from
+// exportDir, or, when query names a table, from a live Scan, listing those
+// at or above threshold bytes.
+func (p *Processor) ProcessSize(ctx context.Context, live dynamodb.LiveOptions, query dynamodb.QueryOptions, inputFiles []string, exportDir string, threshold int) error {
+	report := dynamodb.NewSizeReport(threshold)
+
+	if err := p.readItems(ctx, live, query, inputFiles, exportDir, report.Add); err != nil {
+		return err
+	}
+
+	NewDisplayer(p.logger).ShowSizeReport(report)
+
+	return nil
+}
+
+// ProcessVerify compares the name, price, imageUrl and url attributes of
+// the items read from inputFiles, from exportDir, or, when query names a
+// table, from a live Scan, with the product card in their HTML and reports
+// the mismatches by product ID. With repair the products are printed with
+// missing and truncated attributes replaced from the card, and the report
+// goes to stderr instead. It fails when mismatches remain.
+func (p *Processor) ProcessVerify(ctx context.Context, live dynamodb.LiveOptions, query dynamodb.QueryOptions, inputFiles []string, exportDir string, repair bool) error {
+	report := dynamodb.NewConsistencyReport(repair)
+	displayer := NewDisplayer(p.logger)
+
+	var failed error
+	err := p.readItems(ctx, live, query, inputFiles, exportDir, func(item map[string]types.AttributeValue) {
+		if failed != nil {
+			return
+		}
+
+		product, err := p.dynamodb.UnmarshalProduct(item)
+		if err != nil {
+			failed = fmt.Errorf("failed to unmarshal product: %w", err)
+			return
+		}
+
+		product = report.Add(product)
+		if repair {
+			failed = displayer.StreamProduct(product)
+		}
+	})
+	if err == nil {
+		err = failed
+	}
+	if err != nil {
+		p.logger.Error("Verification stopped", "error", err)
+		return err
+	}
+
+	if repair {
+		displayer.EndStream()
+		displayer.ShowConsistencyReport(report, os.Stderr)
+	} else {
+		displayer.ShowConsistencyReport(report, os.Stdout)
+	}
+
+	if report.Remaining > 0 {
+		return fmt.Errorf("%d attributes disagree with the product card HTML", report.Remaining)
+	}
+	return nil
+}
+
+// readItems calls fn with each item stored in a table: read with a live
+// Scan when query names a table, and otherwise from inputFiles or
+// exportDir. Only the current image of a stream record is passed on, since
+// it is the one still in the table.
+func (p *Processor) readItems(ctx context.Context, live dynamodb.LiveOptions, query dynamodb.QueryOptions, inputFiles []string, exportDir string, fn func(map[string]types.AttributeValue)) error {
+	if query.Table == "" {
+		return p.readInput(inputFiles, exportDir, func(record dynamodb.Record) error {
+			if record.StreamImage == "" || record.StreamImage == "NewImage" {
+				fn(record.Item)
+			}
+			return nil
+		})
+	}
+
+	p.logger.Info("Scanning DynamoDB table", "table", query.Table)
+
+	if err := p.dynamodb.Connect(ctx, live); err != nil {
+		p.logger.Error("Failed to connect", "error", err)
+		return err
+	}
+
+	err := p.scanItems(ctx, query, dynamodb.SegmentOptions{}, func(item map[string]types.AttributeValue, scanned int) error {
+		fn(item)
+		return nil
+	})
+	if err != nil {
+		p.logger.Error("Failed to scan table", "error", err)
+		return err
+	}
+	return nil
+}
+
+// ProcessTTLExtend pushes the TTL of every item query selects forward by
+// options.By.
+func (p *Processor) ProcessTTLExtend(ctx context.Context, live dynamodb.LiveOptions, query dynamodb.QueryOptions, options dynamodb.TTLOptions) error {
+	p.logger.Info("Extending TTLs", "table", options.Table, "by", options.By, "filter", query.Filter, "dry_run", options.DryRun)
+
+	return p.writeTTLs(ctx, live, query, options, "extend", (*dynamodb.TTLWriter).Extend)
+}
+
+// ProcessTTLPurge deletes every item query selects whose TTL has passed.
+func (p *Processor) ProcessTTLPurge(ctx context.Context, live dynamodb.LiveOptions, query dynamodb.QueryOptions, options dynamodb.TTLOptions) error {
+	p.logger.Info("Purging expired items", "table", options.Table, "filter", query.Filter, "dry_run", options.DryRun)
+
+	return p.writeTTLs(ctx, live, query, options, "purge", (*dynamodb.TTLWriter).Purge)
+}
+
+func (p *Processor) writeTTLs(ctx context.Context, live dynamodb.LiveOptions, query dynamodb.QueryOptions, options dynamodb.TTLOptions, action string, write func(*dynamodb.TTLWriter, map[string]types.AttributeValue) error) error {
+	if err := p.dynamodb.Connect(ctx, live); err != nil {
+		p.logger.Error("Failed to connect", "error", err)
+		return err
+	}
+
+	writer := p.dynamodb.NewTTLWriter(ctx, options)
+
+	err := p.scanItems(ctx, query, dynamodb.SegmentOptions{}, func(item map[string]types.AttributeValue, scanned int) error {
+		return write(writer, item)
+	})
+	if err != nil {
+		p.logger.Error("Failed to scan table", "error", err)
+		return err
+	}
+
+	summary := writer.Summary()
+	NewDisplayer(p.logger).ShowTTLSummary(action, options, summary)
+
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d items failed to %s in %s", summary.Failed, action, options.Table)
+	}
+	return nil
+}
+
+// readInput calls fn with each record read from inputFiles, or from
+// exportDir when it is set.
+func (p *Processor) readInput(inputFiles []string, exportDir string, fn func(dynamodb.Record) error) error {
+	if exportDir != "" {
+		report, err := p.dynamodb.ReadExport(exportDir, func(item map[string]types.AttributeValue) error {
+			return fn(dynamodb.Record{Item: item})
+		})
+		if err != nil {
+			p.logger.Error("Failed to read export", "error", err, "dir", exportDir)
+			return err
+		}
+		if err := report.Err(); err != nil {
+			p.logger.Error("Export is incomplete", "error", err, "dir", exportDir)
+			return err
+		}
+		return nil
+	}
+
+	scanner := p.dynamodb.NewInputScanner(inputFiles...)
+	defer scanner.Close()
+
+	for scanner.Scan() {
+		if err := fn(scanner.Record()); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		p.logger.Error("Failed to load data", "error", err)
+		return err
+	}
+	return nil
+}
+
+// ProcessServe loads the items in inputFiles into an in-memory table and
+// answers DynamoDB requests for it on address until ctx is cancelled.
+func (p *Processor) ProcessServe(ctx context.Context, address string, inputFiles []string, options ServeOptions) error {
+	db := memtable.New()
+	db.CreateTable(options.Table, options.PartitionKey, options.SortKey)
+
+	loaded := 0
+	for _, inputFile := range inputFiles {
+		items, err := p.dynamodb.LoadData(inputFile)
+		if err != nil {
+			p.logger.Error("Failed to load data", "error", err, "file", inputFile)
+			return err
+		}
+
+		for i, item := range items {
+			if err := db.Put(options.Table, item); err != nil {
+				return fmt.Errorf("failed to load item %d of %s: %w", i, inputFile, err)
+			}
+		}
+		loaded += len(items)
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+
+	server := &http.Server{Handler: p.dynamodb.NewServer(db)}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	stored := len(db.Items(options.Table))
+	NewDisplayer(p.logger).ShowServing(stored, options.Table, listener.Addr().String())
+	if stored < loaded {
+		p.logger.Warn("Items with the same key replaced each other", "loaded", loaded, "stored", stored)
+	}
+
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	return nil
+}
+
+// ParseExpressionValues decodes --values flags given in DynamoDB JSON.
+func (p *Processor) ParseExpressionValues(data string) (map[string]types.AttributeValue, error) {
+	return p.dynamodb.ParseExpressionValues(data)
+}
+
+// ParseParameters decodes the --parameters flag of partiql.
+func (p *Processor) ParseParameters(data string) ([]types.AttributeValue, error) {
+	return p.dynamodb.ParseParameters(data)
+}
+
+// display prints the scanned products or, when the read was projected,
+// the projected items as they are, since they no longer hold a whole
+// product.
+func (p *Processor) display(scanner *dynamodb.ItemScanner, projected, randomize bool) error {
+	displayer := NewDisplayer(p.logger)
+
+	// Shuffling needs every product up front; otherwise print each product
+	// as soon as it has been read
+	if randomize {
+		return p.showAll(scanner, displayer, projected)
+	}
+
+	return p.stream(scanner, displayer, projected)
+}
+
+// displayLive prints the items of a live read and then reports what the read
+// cost, including when it stopped early.
+func (p *Processor) displayLive(scanner *dynamodb.ItemScanner, projected, randomize bool) error {
+	err := p.display(scanner, projected, randomize)
+
+	response := scanner.Response()
+	var units float64
+	if consumed, ok := response.ConsumedCapacity.(*types.ConsumedCapacity); ok {
+		units = aws.ToFloat64(consumed.CapacityUnits)
+	}
+	p.logger.Info("Live read finished", "count", response.Count, "scanned_count", response.ScannedCount, "consumed_rcu", units)
+	NewDisplayer(p.logger).ShowReadSummary(response.Count, response.ScannedCount, units)
+
+	return err
+}
+
+func (p *Processor) showAll(scanner *dynamodb.ItemScanner, displayer *Displayer, projected bool) error {
+	var products []models.Product
+	var items []map[string]interface{}
+
+	for scanner.Scan() {
+		if projected {
+			item, err := p.dynamodb.UnmarshalItem(scanner.Record().Item)
+			if err != nil {
+				p.logger.Error("Failed to unmarshal items", "error", err)
+				return err
+			}
+			items = append(items, item)
+			continue
+		}
+
+		product, err := p.dynamodb.UnmarshalRecord(scanner.Record())
+		if err != nil {
+			p.logger.Error("Failed to unmarshal products", "error", err)
+			return err
+		}
+		products = append(products, product)
+	}
+	if err := scanner.Err(); err != nil {
+		p.logger.Error("Failed to load data", "error", err)
+		return err
+	}
+
+	if projected {
+		displayer.ShowItems(items, true)
+		return nil
+	}
+
+	p.logger.Debug("Successfully unmarshaled products", "count", len(products))
+
+	displayer.ShowProducts(products, true)
+
+	return nil
+}
+
+func (p *Processor) stream(scanner *dynamodb.ItemScanner, displayer *Displayer, projected bool) error {
+	for scanner.Scan() {
+		if projected {
+			item, err := p.dynamodb.UnmarshalItem(scanner.Record().Item)
+			if err != nil {
+				p.logger.Error("Failed to unmarshal items", "error", err)
+				return err
+			}
+			if err := displayer.StreamItem(item); err != nil {
+				p.logger.Error("Failed to display item", "error", err)
+				return err
+			}
+			continue
+		}
+
+		product, err := p.dynamodb.UnmarshalRecord(scanner.Record())
+		if err != nil {
+			p.logger.Error("Failed to unmarshal products", "error", err)
+			return err
+		}
+
+		if err := displayer.StreamProduct(product); err != nil {
+			p.logger.Error("Failed to display product", "error", err)
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		p.logger.Error("Failed to load data", "error", err)
+		return err
+	}
+
+	displayer.EndStream()
 
 	return nil
 }
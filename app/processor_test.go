@@ -0,0 +1,178 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gkwa/bouncingbeaver/internal/dynamodb"
+	"github.com/gkwa/bouncingbeaver/internal/memtable"
+)
+
+const projectionInput = `{
+	"Items": [
+		{"id": {"S": "a"}, "name": {"S": "Bag of Ice"}, "price": {"S": "$2.29"}, "ttl": {"N": "1750481534"}},
+		{"id": {"S": "b"}, "name": {"S": "Bass Comb"}, "price": {"S": "$8.99"}, "ttl": {"N": "1750481535"}}
+	]
+}`
+
+// captureStdout returns what fn prints to stdout.
+func captureStdout(t *testing.T, fn func() error) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		output <- string(data)
+	}()
+
+	fnErr := fn()
+	w.Close()
+	os.Stdout = stdout
+	if fnErr != nil {
+		t.Fatalf("Unexpected error: %v", fnErr)
+	}
+	return <-output
+}
+
+// captureFailure returns what fn prints to stdout before failing.
+func captureFailure(t *testing.T, fn func() error) string {
+	t.Helper()
+
+	var err error
+	output := captureStdout(t, func() error {
+		err = fn()
+		return nil
+	})
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	return output
+}
+
+func writeInput(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "input.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestProcessData_PrintsProjectedItems(t *testing.T) {
+	input := writeInput(t, projectionInput)
+	query := dynamodb.QueryOptions{
+		Filter:                    "price = :p",
+		Projection:                "id, price",
+		ExpressionAttributeValues: map[string]types.AttributeValue{":p": &types.AttributeValueMemberS{Value: "$8.99"}},
+	}
+
+	output := captureStdout(t, func() error {
+		return NewProcessor(0, false).ProcessData([]string{input}, query, false)
+	})
+
+	expected := "[\n  {\n    \"id\": \"b\",\n    \"price\": \"$8.99\"\n  }\n]\n"
+	if output != expected {
+		t.Errorf("Expected only the projected attributes:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestProcessPartiQL_PrintsSelectList(t *testing.T) {
+	input := writeInput(t, projectionInput)
+
+	output := captureStdout(t, func() error {
+		return NewProcessor(0, false).ProcessPartiQL([]string{input}, "", `SELECT name, ttl FROM products WHERE id = 'a'`, nil, false)
+	})
+
+	expected := "[\n  {\n    \"name\": \"Bag of Ice\",\n    \"ttl\": 1750481534\n  }\n]\n"
+	if output != expected {
+		t.Errorf("Expected only the selected attributes:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestProcessData_PrintsNullForNoItems(t *testing.T) {
+	input := writeInput(t, `{"Items": []}`)
+
+	output := captureStdout(t, func() error {
+		return NewProcessor(0, false).ProcessData([]string{input}, dynamodb.QueryOptions{}, false)
+	})
+
+	if output != "null\n" {
+		t.Errorf("Expected null, got %q", output)
+	}
+}
+
+func TestProcessData_LeavesArrayOpenOnError(t *testing.T) {
+	input := writeInput(t, projectionInput)
+	missing := filepath.Join(t.TempDir(), "missing.json")
+
+	output := captureFailure(t, func() error {
+		return NewProcessor(0, false).ProcessData([]string{input, missing}, dynamodb.QueryOptions{Projection: "id"}, false)
+	})
+
+	// The items read before the error are printed, but the array is not
+	// closed, so JSON consumers do not take them for the whole result
+	expected := "[\n  {\n    \"id\": \"a\"\n  },\n  {\n    \"id\": \"b\"\n  }"
+	if output != expected {
+		t.Errorf("Expected an unterminated array:\n%s\ngot:\n%s", expected, output)
+	}
+	var items []interface{}
+	if json.Unmarshal([]byte(output), &items) == nil {
+		t.Error("Expected the partial output not to parse as JSON")
+	}
+}
+
+func TestProcessPut_RejectsDamagedExport(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_CONFIG_FILE", "/dev/null")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/dev/null")
+
+	db := memtable.New()
+	db.CreateTable("products", "id", "")
+	server := httptest.NewServer(dynamodb.NewClient().NewServer(db))
+	defer server.Close()
+
+	// Copy the sample export with one data file's checksum changed
+	source := "../internal/dynamodb/testdata/export"
+	dir := t.TempDir()
+	for _, name := range []string{"manifest-summary.json", "manifest-files.json", "data/uwf2ixvyba3j7m5kcbfizvxd7q.json.gz", "data/x7hbn5ebiy6pvo5pymmb2kmxey.json.gz"} {
+		data, err := os.ReadFile(filepath.Join(source, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name == "manifest-files.json" {
+			data = []byte(strings.Replace(string(data), "6HajM2llw14eK7YPFlducA==", "AAAAAAAAAAAAAAAAAAAAAA==", 1))
+		}
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := NewProcessor(0, false).ProcessPut(context.Background(), dynamodb.LiveOptions{EndpointURL: server.URL}, nil, dir, dynamodb.WriteOptions{Table: "products"})
+	if err == nil {
+		t.Fatal("Expected the damaged export to be rejected")
+	}
+	if items := db.Items("products"); len(items) != 0 {
+		t.Errorf("Expected nothing written, got %d items", len(items))
+	}
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"github.com/gkwa/bouncingbeaver/app"
+	"github.com/gkwa/bouncingbeaver/internal/dynamodb"
+	"github.com/spf13/cobra"
+)
+
+var (
+	liveOptions  dynamodb.LiveOptions
+	queryOptions dynamodb.QueryOptions
+	namesJSON    string
+	valuesJSON   string
+)
+
+// addLiveFlags registers the connection and selection flags shared by the
+// commands that read a live table.
+func addLiveFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&queryOptions.Table, "table", "", "table name")
+	cmd.Flags().StringVar(&queryOptions.Index, "index", "", "global or local secondary index name")
+	cmd.Flags().StringVar(&queryOptions.Filter, "filter", "", "filter expression")
+	cmd.Flags().StringVar(&queryOptions.Projection, "projection", "", "projection expression naming the attributes to return")
+	cmd.Flags().StringVar(&namesJSON, "names", "", "expression attribute names as JSON, e.g. '{\"#ts\":\"timestamp\"}'")
+	cmd.Flags().StringVar(&valuesJSON, "values", "", "expression attribute values as DynamoDB JSON, e.g. '{\":d\":{\"S\":\"2025\"}}'")
+	cmd.Flags().Int32Var(&queryOptions.PageSize, "page-size", 0, "items per request (default: as many as fit in 1 MB)")
+	cmd.Flags().BoolVar(&queryOptions.ConsistentRead, "consistent-read", false, "use strongly consistent reads")
+	cmd.Flags().Float64Var(&queryOptions.MaxRCU, "max-rcu", 0, "maximum read capacity units to consume per second (default unlimited)")
+	addConnectionFlags(cmd)
+	cmd.MarkFlagRequired("table")
+}
+
+// addConnectionFlags registers the flags that choose which DynamoDB to talk
+// to.
+func addConnectionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&liveOptions.Region, "region", "", "AWS region (default from AWS configuration)")
+	cmd.Flags().StringVar(&liveOptions.Profile, "profile", "", "AWS shared configuration profile")
+	cmd.Flags().StringVar(&liveOptions.EndpointURL, "endpoint-url", "", "DynamoDB endpoint, e.g. http://localhost:8000 for DynamoDB Local")
+}
+
+// parseExpressionFlags fills queryOptions from the --names and --values flags.
+func parseExpressionFlags(processor *app.Processor) error {
+	names, err := dynamodb.ParseExpressionNames(namesJSON)
+	if err != nil {
+		return err
+	}
+
+	values, err := processor.ParseExpressionValues(valuesJSON)
+	if err != nil {
+		return err
+	}
+
+	queryOptions.ExpressionAttributeNames = names
+	queryOptions.ExpressionAttributeValues = values
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"github.com/gkwa/bouncingbeaver/app"
+	"github.com/gkwa/bouncingbeaver/internal/dynamodb"
+	"github.com/gkwa/bouncingbeaver/internal/processing"
+	"github.com/spf13/cobra"
+)
+
+var (
+	migrateOptions  dynamodb.MigrateOptions
+	migrateEncoding string
+	migrateVerify   bool
+)
+
+var migrateHTMLCmd = &cobra.Command{
+	Use:   "migrate-html",
+	Short: "Rewrite the rawHtml encoding of every item in a table",
+	Long: `Scans a table, decodes each item's rawHtml and stores it again in the encoding
+given by --to, using a conditional UpdateItem so that items rewritten by the
+scraper in the meantime are left alone. Encodings: base64-zlib (the scraper's
+format), zlib, gzip and zstd (binary attributes) and text.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		encoding, err := processing.ParseHTMLEncoding(migrateEncoding)
+		if err != nil {
+			return err
+		}
+		migrateOptions.Table = queryOptions.Table
+		migrateOptions.Encoding = encoding
+
+		processor := app.NewProcessor(verbose, strictBase64)
+		return processor.ProcessMigrateHTML(cmd.Context(), liveOptions, queryOptions, segmentOptions, migrateOptions, migrateVerify)
+	},
+}
+
+func init() {
+	migrateHTMLCmd.Flags().StringVar(&queryOptions.Table, "table", "", "table name")
+	migrateHTMLCmd.Flags().StringVar(&migrateEncoding, "to", "", "target encoding: base64-zlib, zlib, gzip, zstd or text")
+	migrateHTMLCmd.Flags().BoolVar(&migrateOptions.DryRun, "dry-run", false, "re-encode and count items without writing them")
+	migrateHTMLCmd.Flags().BoolVar(&migrateVerify, "verify", true, "scan the table again afterwards and check every item's encoding")
+	migrateHTMLCmd.Flags().Int32Var(&queryOptions.PageSize, "page-size", 0, "items per request (default: as many as fit in 1 MB)")
+	migrateHTMLCmd.Flags().Float64Var(&queryOptions.MaxRCU, "max-rcu", 0, "maximum read capacity units to consume per second (default unlimited)")
+	migrateHTMLCmd.Flags().IntVar(&segmentOptions.TotalSegments, "segments", 0, "number of parallel scan segments (default 1, or the checkpoint's count with --resume)")
+	migrateHTMLCmd.Flags().StringVar(&segmentOptions.CheckpointFile, "checkpoint", "", "file recording each segment's progress")
+	migrateHTMLCmd.Flags().BoolVar(&segmentOptions.Resume, "resume", false, "continue the migration recorded in --checkpoint")
+	addConnectionFlags(migrateHTMLCmd)
+	migrateHTMLCmd.MarkFlagRequired("table")
+	migrateHTMLCmd.MarkFlagRequired("to")
+	rootCmd.AddCommand(migrateHTMLCmd)
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"github.com/gkwa/bouncingbeaver/app"
+	"github.com/spf13/cobra"
+)
+
+var (
+	partiqlFiles      []string
+	partiqlExportDir  string
+	partiqlParameters string
+)
+
+var partiqlCmd = &cobra.Command{
+	Use:   "partiql STATEMENT",
+	Short: "Run a PartiQL SELECT against local data",
+	Long: `Runs a PartiQL SELECT statement, as entered in the DynamoDB console, over the
+items of a file or export directory and prints the products it selects`,
+	Example: `  bouncingbeaver partiql -f dump.json "SELECT name, price FROM products WHERE domain = 'delivery.pccmarkets.com'"
+  bouncingbeaver partiql -f dump.json "SELECT * FROM products WHERE ttl < ?" --parameters '[{"N": "1750000000"}]'`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		processor := app.NewProcessor(verbose, strictBase64)
+
+		parameters, err := processor.ParseParameters(partiqlParameters)
+		if err != nil {
+			return err
+		}
+
+		return processor.ProcessPartiQL(partiqlFiles, partiqlExportDir, args[0], parameters, randomize)
+	},
+}
+
+func init() {
+	partiqlCmd.Flags().StringArrayVarP(&partiqlFiles, "file", "f", nil, "input file (use '-' for stdin); repeat to read Scan pages saved one per file")
+	partiqlCmd.Flags().StringVar(&partiqlExportDir, "export-dir", "", "DynamoDB export directory containing manifest-summary.json")
+	partiqlCmd.Flags().StringVar(&partiqlParameters, "parameters", "", "values for the statement's ? markers as a JSON array of DynamoDB JSON, e.g. '[{\"S\":\"2025\"}]'")
+	partiqlCmd.Flags().BoolVar(&randomize, "randomize", false, "randomize the order of output products")
+	partiqlCmd.MarkFlagsMutuallyExclusive("file", "export-dir")
+	partiqlCmd.MarkFlagsOneRequired("file", "export-dir")
+	rootCmd.AddCommand(partiqlCmd)
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/gkwa/bouncingbeaver/app"
+	"github.com/gkwa/bouncingbeaver/internal/dynamodb"
+	"github.com/spf13/cobra"
+)
+
+var (
+	putFiles     []string
+	putExportDir string
+	writeOptions dynamodb.WriteOptions
+)
+
+var putCmd = &cobra.Command{
+	Use:   "put",
+	Short: "Write products back to a DynamoDB table",
+	Long:  "Reads the same inputs as unmarshal and writes each product to a table with BatchWriteItem",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		processor := app.NewProcessor(verbose, strictBase64)
+		return processor.ProcessPut(cmd.Context(), liveOptions, putFiles, putExportDir, writeOptions)
+	},
+}
+
+func init() {
+	putCmd.Flags().StringArrayVarP(&putFiles, "file", "f", nil, "input file (use '-' for stdin); repeat to read several files")
+	putCmd.Flags().StringVar(&putExportDir, "export-dir", "", "DynamoDB export directory containing manifest-summary.json")
+	putCmd.Flags().StringVar(&writeOptions.Table, "table", "", "table to write to")
+	putCmd.Flags().BoolVar(&writeOptions.DryRun, "dry-run", false, "build the batches without writing them")
+	addConnectionFlags(putCmd)
+	putCmd.MarkFlagRequired("table")
+	putCmd.MarkFlagsMutuallyExclusive("file", "export-dir")
+	putCmd.MarkFlagsOneRequired("file", "export-dir")
+	rootCmd.AddCommand(putCmd)
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"github.com/gkwa/bouncingbeaver/app"
+	"github.com/spf13/cobra"
+)
+
+var queryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Query a live DynamoDB table",
+	Long:  "Reads the items matching a key condition with Query and prints the unmarshaled products",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		processor := app.NewProcessor(verbose, strictBase64)
+		if err := parseExpressionFlags(processor); err != nil {
+			return err
+		}
+		return processor.ProcessQuery(cmd.Context(), liveOptions, queryOptions, randomize)
+	},
+}
+
+func init() {
+	addLiveFlags(queryCmd)
+	queryCmd.Flags().StringVar(&queryOptions.KeyCondition, "key-condition", "", "key condition expression, e.g. 'id = :id'")
+	queryCmd.Flags().BoolVar(&randomize, "randomize", false, "randomize the order of output products")
+	queryCmd.MarkFlagRequired("key-condition")
+	rootCmd.AddCommand(queryCmd)
+}
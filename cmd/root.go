@@ -8,8 +8,9 @@ import (
 )
 
 var (
-	cfgFile string
-	verbose int
+	cfgFile      string
+	verbose      int
+	strictBase64 bool
 )
 
 var rootCmd = &cobra.Command{
@@ -26,6 +27,7 @@ func init() {
 	cobra.OnInitialize(initConfig)
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.bouncingbeaver.yaml)")
 	rootCmd.PersistentFlags().CountVarP(&verbose, "verbose", "v", "verbose output (can be used multiple times)")
+	rootCmd.PersistentFlags().BoolVar(&strictBase64, "strict-base64", false, "decode rawHtml only as standard padded base64 over zlib, rejecting other encodings")
 	viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
 }
 
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/gkwa/bouncingbeaver/app"
+	"github.com/gkwa/bouncingbeaver/internal/dynamodb"
+	"github.com/spf13/cobra"
+)
+
+var segmentOptions dynamodb.SegmentOptions
+
+var scanCmd = &cobra.Command{
+	Use:   "scan",
+	Short: "Scan a live DynamoDB table",
+	Long:  "Reads every item of a table or index with Scan and prints the unmarshaled products",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		processor := app.NewProcessor(verbose, strictBase64)
+		if err := parseExpressionFlags(processor); err != nil {
+			return err
+		}
+		return processor.ProcessScan(cmd.Context(), liveOptions, queryOptions, segmentOptions, randomize)
+	},
+}
+
+func init() {
+	addLiveFlags(scanCmd)
+	scanCmd.Flags().IntVar(&segmentOptions.TotalSegments, "segments", 0, "number of parallel scan segments (default 1, or the checkpoint's count with --resume)")
+	scanCmd.Flags().StringVar(&segmentOptions.CheckpointFile, "checkpoint", "", "file recording each segment's progress")
+	scanCmd.Flags().BoolVar(&segmentOptions.Resume, "resume", false, "continue the scan recorded in --checkpoint")
+	scanCmd.Flags().BoolVar(&randomize, "randomize", false, "randomize the order of output products")
+	// Shuffling holds the output back until the scan ends, after every page
+	// has been checkpointed, so a killed run would resume past items never
+	// printed
+	scanCmd.MarkFlagsMutuallyExclusive("randomize", "checkpoint")
+	rootCmd.AddCommand(scanCmd)
+}
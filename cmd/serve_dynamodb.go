@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gkwa/bouncingbeaver/app"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveFiles   []string
+	serveAddress string
+	serveOptions app.ServeOptions
+)
+
+var serveDynamoDBCmd = &cobra.Command{
+	Use:   "serve-dynamodb",
+	Short: "Serve items from files over the DynamoDB API",
+	Long: `Loads the items in the given files into an in-memory table and answers Scan,
+Query, GetItem and DescribeTable requests for it over the DynamoDB HTTP API,
+so the aws CLI and SDKs can read a frozen snapshot with --endpoint-url`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		processor := app.NewProcessor(verbose, strictBase64)
+		return processor.ProcessServe(ctx, serveAddress, serveFiles, serveOptions)
+	},
+}
+
+func init() {
+	serveDynamoDBCmd.Flags().StringArrayVarP(&serveFiles, "file", "f", nil, "input file (use '-' for stdin); repeat to load several files")
+	serveDynamoDBCmd.Flags().StringVar(&serveAddress, "listen", "localhost:8000", "address to listen on")
+	serveDynamoDBCmd.Flags().StringVar(&serveOptions.Table, "table", "products", "name of the served table")
+	serveDynamoDBCmd.Flags().StringVar(&serveOptions.PartitionKey, "partition-key", "id", "partition key attribute")
+	serveDynamoDBCmd.Flags().StringVar(&serveOptions.SortKey, "sort-key", "", "sort key attribute, if the table has one")
+	serveDynamoDBCmd.MarkFlagRequired("file")
+	rootCmd.AddCommand(serveDynamoDBCmd)
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/gkwa/bouncingbeaver/app"
+	"github.com/gkwa/bouncingbeaver/internal/dynamodb"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sizeFiles     []string
+	sizeExportDir string
+	sizeThreshold float64
+)
+
+var sizeCmd = &cobra.Command{
+	Use:   "size",
+	Short: "Audit item sizes against the 400 KB limit",
+	Long: `Computes each item's size by DynamoDB's rules and lists the items at or above
+--threshold percent of the 400 KB item limit, with the attribute that takes
+up most of each and the read and write capacity units it costs`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if sizeThreshold < 0 {
+			return fmt.Errorf("--threshold must not be negative")
+		}
+		threshold := int(sizeThreshold / 100 * dynamodb.MaxItemSize)
+
+		processor := app.NewProcessor(verbose, strictBase64)
+		return processor.ProcessSize(cmd.Context(), liveOptions, queryOptions, sizeFiles, sizeExportDir, threshold)
+	},
+}
+
+func init() {
+	sizeCmd.Flags().StringArrayVarP(&sizeFiles, "file", "f", nil, "input file (use '-' for stdin); repeat to read several files")
+	sizeCmd.Flags().StringVar(&sizeExportDir, "export-dir", "", "DynamoDB export directory containing manifest-summary.json")
+	sizeCmd.Flags().StringVar(&queryOptions.Table, "table", "", "scan a live table instead of reading files")
+	sizeCmd.Flags().Float64Var(&queryOptions.MaxRCU, "max-rcu", 0, "maximum read capacity units to consume per second (default unlimited)")
+	sizeCmd.Flags().Float64Var(&sizeThreshold, "threshold", 80, "list items at or above this percentage of the item size limit; 0 lists every item")
+	addConnectionFlags(sizeCmd)
+	sizeCmd.MarkFlagsMutuallyExclusive("file", "export-dir", "table")
+	sizeCmd.MarkFlagsOneRequired("file", "export-dir", "table")
+	rootCmd.AddCommand(sizeCmd)
+}
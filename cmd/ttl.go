@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"github.com/gkwa/bouncingbeaver/app"
+	"github.com/gkwa/bouncingbeaver/internal/dynamodb"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ttlFiles     []string
+	ttlExportDir string
+	ttlPeriod    string
+	ttlBy        string
+	ttlOptions   dynamodb.TTLOptions
+)
+
+var ttlCmd = &cobra.Command{
+	Use:   "ttl",
+	Short: "Report on and manage item TTLs",
+	Long:  "Reports the expiry dates held in the ttl attribute, extends them, and deletes expired items DynamoDB has not removed yet",
+}
+
+var ttlReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Show a histogram of expiry dates",
+	Long:  "Reads the same inputs as unmarshal, or a live table with --table, and counts the items expiring in each day, week or month",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		period, err := dynamodb.ParseTTLPeriod(ttlPeriod)
+		if err != nil {
+			return err
+		}
+
+		processor := app.NewProcessor(verbose, strictBase64)
+		return processor.ProcessTTLReport(cmd.Context(), liveOptions, queryOptions, ttlFiles, ttlExportDir, period)
+	},
+}
+
+var ttlExtendCmd = &cobra.Command{
+	Use:   "extend",
+	Short: "Push TTLs forward",
+	Long:  "Adds --by to the ttl of every item matching --where, using a conditional UpdateItem so TTLs changed meanwhile are left alone",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		by, err := dynamodb.ParseTTLDuration(ttlBy)
+		if err != nil {
+			return err
+		}
+		ttlOptions.By = by
+
+		processor := app.NewProcessor(verbose, strictBase64)
+		if err := parseTTLFlags(processor); err != nil {
+			return err
+		}
+		return processor.ProcessTTLExtend(cmd.Context(), liveOptions, queryOptions, ttlOptions)
+	},
+}
+
+var ttlPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Delete items whose TTL has passed",
+	Long:  "Deletes every expired item matching --where that DynamoDB has not removed yet, using a conditional DeleteItem so items whose TTL was extended meanwhile are kept",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		processor := app.NewProcessor(verbose, strictBase64)
+		if err := parseTTLFlags(processor); err != nil {
+			return err
+		}
+		return processor.ProcessTTLPurge(cmd.Context(), liveOptions, queryOptions, ttlOptions)
+	},
+}
+
+// addTTLWriteFlags registers the flags shared by extend and purge.
+func addTTLWriteFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&queryOptions.Table, "table", "", "table name")
+	cmd.Flags().StringVar(&queryOptions.Filter, "where", "", "filter expression selecting the items to change")
+	cmd.Flags().StringVar(&namesJSON, "names", "", "expression attribute names as JSON, e.g. '{\"#ts\":\"timestamp\"}'")
+	cmd.Flags().StringVar(&valuesJSON, "values", "", "expression attribute values as DynamoDB JSON, e.g. '{\":d\":{\"S\":\"2025\"}}'")
+	cmd.Flags().Float64Var(&queryOptions.MaxRCU, "max-rcu", 0, "maximum read capacity units to consume per second (default unlimited)")
+	cmd.Flags().BoolVar(&ttlOptions.DryRun, "dry-run", false, "count the items without changing them")
+	addConnectionFlags(cmd)
+	cmd.MarkFlagRequired("table")
+}
+
+// parseTTLFlags fills the expression values and table shared by extend and
+// purge.
+func parseTTLFlags(processor *app.Processor) error {
+	ttlOptions.Table = queryOptions.Table
+	return parseExpressionFlags(processor)
+}
+
+func init() {
+	ttlReportCmd.Flags().StringArrayVarP(&ttlFiles, "file", "f", nil, "input file (use '-' for stdin); repeat to read several files")
+	ttlReportCmd.Flags().StringVar(&ttlExportDir, "export-dir", "", "DynamoDB export directory containing manifest-summary.json")
+	ttlReportCmd.Flags().StringVar(&queryOptions.Table, "table", "", "scan a live table instead of reading files")
+	ttlReportCmd.Flags().Float64Var(&queryOptions.MaxRCU, "max-rcu", 0, "maximum read capacity units to consume per second (default unlimited)")
+	ttlReportCmd.Flags().StringVar(&ttlPeriod, "period", "day", "histogram bucket: day, week or month")
+	addConnectionFlags(ttlReportCmd)
+	ttlReportCmd.MarkFlagsMutuallyExclusive("file", "export-dir", "table")
+	ttlReportCmd.MarkFlagsOneRequired("file", "export-dir", "table")
+
+	ttlExtendCmd.Flags().StringVar(&ttlBy, "by", "", "how far to extend each TTL, e.g. 30d, 2w or 36h")
+	addTTLWriteFlags(ttlExtendCmd)
+	ttlExtendCmd.MarkFlagRequired("by")
+
+	addTTLWriteFlags(ttlPurgeCmd)
+
+	ttlCmd.AddCommand(ttlReportCmd, ttlExtendCmd, ttlPurgeCmd)
+	rootCmd.AddCommand(ttlCmd)
+}
@@ -6,22 +6,36 @@ import (
 )
 
 var (
-	inputFile string
-	randomize bool
+	inputFiles []string
+	exportDir  string
+	randomize  bool
 )
 
 var unmarshalCmd = &cobra.Command{
 	Use:   "unmarshal",
 	Short: "Unmarshal DynamoDB data example",
-	Long:  "Demonstrates unmarshaling DynamoDB AttributeValue format to Go structs",
+	Long:  "Demonstrates unmarshaling DynamoDB AttributeValue format to Go structs, optionally selecting items with --filter and attributes with --projection as Scan would",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		processor := app.NewProcessor(verbose)
-		return processor.ProcessData(inputFile, randomize)
+		processor := app.NewProcessor(verbose, strictBase64)
+		if err := parseExpressionFlags(processor); err != nil {
+			return err
+		}
+
+		if exportDir != "" {
+			return processor.ProcessExport(exportDir, queryOptions, randomize)
+		}
+		return processor.ProcessData(inputFiles, queryOptions, randomize)
 	},
 }
 
 func init() {
-	unmarshalCmd.Flags().StringVarP(&inputFile, "file", "f", "internal/dynamodb/testdata/sample_input.json", "input file (use '-' for stdin)")
+	unmarshalCmd.Flags().StringArrayVarP(&inputFiles, "file", "f", []string{"internal/dynamodb/testdata/sample_input.json"}, "input file (use '-' for stdin); repeat to read Scan pages saved one per file")
+	unmarshalCmd.Flags().StringVar(&exportDir, "export-dir", "", "DynamoDB export directory containing manifest-summary.json")
+	unmarshalCmd.Flags().StringVar(&queryOptions.Filter, "filter", "", "filter expression selecting the items to print")
+	unmarshalCmd.Flags().StringVar(&queryOptions.Projection, "projection", "", "projection expression naming the attributes to keep")
+	unmarshalCmd.Flags().StringVar(&namesJSON, "names", "", "expression attribute names as JSON, e.g. '{\"#ts\":\"timestamp\"}'")
+	unmarshalCmd.Flags().StringVar(&valuesJSON, "values", "", "expression attribute values as DynamoDB JSON, e.g. '{\":d\":{\"S\":\"2025\"}}'")
 	unmarshalCmd.Flags().BoolVar(&randomize, "randomize", false, "randomize the order of output products")
+	unmarshalCmd.MarkFlagsMutuallyExclusive("file", "export-dir")
 	rootCmd.AddCommand(unmarshalCmd)
 }
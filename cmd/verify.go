@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"github.com/gkwa/bouncingbeaver/app"
+	"github.com/spf13/cobra"
+)
+
+var (
+	verifyFiles     []string
+	verifyExportDir string
+	verifyRepair    bool
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check stored attributes against the product card HTML",
+	Long: `Parses the product card in each item's rawHtml and compares it with the name,
+price, imageUrl and url attributes stored beside it, listing the mismatches by
+product ID. With --repair the products are printed with missing and truncated
+attributes replaced from the HTML, and the report goes to stderr. Exits
+non-zero when mismatches remain.`,
+	Example: `  bouncingbeaver verify -f dump.json
+  bouncingbeaver verify -f dump.json --repair > repaired.json
+  bouncingbeaver verify --table products --max-rcu 50`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// The flags have been accepted by now, so mismatches and read errors
+		// should not be followed by the usage text
+		cmd.SilenceUsage = true
+
+		processor := app.NewProcessor(verbose, strictBase64)
+		return processor.ProcessVerify(cmd.Context(), liveOptions, queryOptions, verifyFiles, verifyExportDir, verifyRepair)
+	},
+}
+
+func init() {
+	verifyCmd.Flags().StringArrayVarP(&verifyFiles, "file", "f", nil, "input file (use '-' for stdin); repeat to read several files")
+	verifyCmd.Flags().StringVar(&verifyExportDir, "export-dir", "", "DynamoDB export directory containing manifest-summary.json")
+	verifyCmd.Flags().StringVar(&queryOptions.Table, "table", "", "scan a live table instead of reading files")
+	verifyCmd.Flags().Float64Var(&queryOptions.MaxRCU, "max-rcu", 0, "maximum read capacity units to consume per second (default unlimited)")
+	verifyCmd.Flags().BoolVar(&verifyRepair, "repair", false, "print the products with missing and truncated attributes replaced from the HTML")
+	addConnectionFlags(verifyCmd)
+	verifyCmd.MarkFlagsMutuallyExclusive("file", "export-dir", "table")
+	verifyCmd.MarkFlagsOneRequired("file", "export-dir", "table")
+	rootCmd.AddCommand(verifyCmd)
+}
@@ -0,0 +1,30 @@
+package dynamodb
+
+import (
+	"context"
+
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// API is the part of the DynamoDB service the live commands use. The SDK
+// client satisfies it, as does the in-memory table in internal/memtable
+// that tests use in its place.
+type API interface {
+	Scan(ctx context.Context, input *awsdynamodb.ScanInput, optFns ...func(*awsdynamodb.Options)) (*awsdynamodb.ScanOutput, error)
+	Query(ctx context.Context, input *awsdynamodb.QueryInput, optFns ...func(*awsdynamodb.Options)) (*awsdynamodb.QueryOutput, error)
+	GetItem(ctx context.Context, input *awsdynamodb.GetItemInput, optFns ...func(*awsdynamodb.Options)) (*awsdynamodb.GetItemOutput, error)
+	BatchWriteItem(ctx context.Context, input *awsdynamodb.BatchWriteItemInput, optFns ...func(*awsdynamodb.Options)) (*awsdynamodb.BatchWriteItemOutput, error)
+	UpdateItem(ctx context.Context, input *awsdynamodb.UpdateItemInput, optFns ...func(*awsdynamodb.Options)) (*awsdynamodb.UpdateItemOutput, error)
+	DeleteItem(ctx context.Context, input *awsdynamodb.DeleteItemInput, optFns ...func(*awsdynamodb.Options)) (*awsdynamodb.DeleteItemOutput, error)
+	DescribeTable(ctx context.Context, input *awsdynamodb.DescribeTableInput, optFns ...func(*awsdynamodb.Options)) (*awsdynamodb.DescribeTableOutput, error)
+}
+
+var _ API = (*awsdynamodb.Client)(nil)
+
+// NewClientWithAPI returns a client that makes its live calls through api
+// instead of connecting with Connect.
+func NewClientWithAPI(api API) *Client {
+	c := NewClient()
+	c.api = api
+	return c
+}
@@ -1,6 +1,11 @@
 package dynamodb
 
 import (
+	"encoding/base64"
+	"maps"
+	"strings"
+	"sync"
+
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/gkwa/bouncingbeaver/internal/logger"
@@ -10,42 +15,174 @@ import (
 
 type Client struct {
 	htmlExtractor *processing.HTMLExtractor
+	verifier      *processing.HTMLExtractor // always strict, for VerifyHTML
 	logger        *logger.Logger
+	api           API // set by Connect or NewClientWithAPI for live calls
+
+	mu         sync.Mutex
+	keySchemas map[string]KeySchema // described tables, by name
 }
 
 func NewClient() *Client {
 	return &Client{
 		htmlExtractor: processing.NewHTMLExtractor(),
+		verifier:      newVerifier(),
 		logger:        logger.New(0), // Basic logger for debugging
 	}
 }
 
+func newVerifier() *processing.HTMLExtractor {
+	verifier := processing.NewHTMLExtractor()
+	verifier.SetStrictBase64(true)
+	return verifier
+}
+
+// SetStrictBase64 makes rawHtml decoding accept only standard, padded
+// base64, as it did before the URL-safe and unpadded variants were
+// recognised.
+func (c *Client) SetStrictBase64(strict bool) {
+	c.htmlExtractor.SetStrictBase64(strict)
+}
+
 func (c *Client) UnmarshalProducts(items []map[string]types.AttributeValue) ([]models.Product, error) {
 	var products []models.Product
-	err := attributevalue.UnmarshalListOfMaps(items, &products)
+	normalized := make([]map[string]types.AttributeValue, len(items))
+	for i, item := range items {
+		normalized[i] = normalizeRawHTML(item)
+	}
+	err := attributevalue.UnmarshalListOfMaps(normalized, &products)
 	if err != nil {
 		return nil, err
 	}
 
 	// Post-process to extract HTML
 	for i := range products {
-		c.logger.Debug("Processing product", "id", products[i].ID, "rawhtml_length", len(products[i].RawHTML))
+		c.extractHTML(&products[i], isBinaryHTML(items[i]))
+		c.parseTextContent(&products[i])
+	}
 
-		if products[i].RawHTML == "" {
-			products[i].RawHTMLExtracted = "NO_RAW_HTML_DATA"
-			c.logger.Debug("No raw HTML data for product", "id", products[i].ID)
-			continue
-		}
+	return products, nil
+}
 
-		extractedHTML, err := c.htmlExtractor.ExtractHTML(products[i].RawHTML)
-		if err != nil {
-			products[i].RawHTMLExtracted = "EXTRACTION_FAILED: " + err.Error()
-			c.logger.Error("HTML extraction failed", "id", products[i].ID, "error", err)
-		} else {
-			products[i].RawHTMLExtracted = extractedHTML
-			c.logger.Debug("HTML extraction successful", "id", products[i].ID, "extracted_length", len(extractedHTML))
-		}
+// UnmarshalProduct converts a single item, for use with ItemScanner.
+func (c *Client) UnmarshalProduct(item map[string]types.AttributeValue) (models.Product, error) {
+	var product models.Product
+	if err := attributevalue.UnmarshalMap(normalizeRawHTML(item), &product); err != nil {
+		return models.Product{}, err
 	}
 
-	return products, nil
+	c.extractHTML(&product, isBinaryHTML(item))
+	c.parseTextContent(&product)
+
+	return product, nil
+}
+
+// UnmarshalItem converts an item to plain values without going through
+// models.Product, for items reduced by a projection whose attributes need
+// not be a product's. rawHtml is left as stored.
+func (c *Client) UnmarshalItem(item map[string]types.AttributeValue) (map[string]interface{}, error) {
+	var values map[string]interface{}
+	if err := attributevalue.UnmarshalMap(item, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// UnmarshalRecord converts a scanned record, carrying its stream event
+// details and source table over to the product.
+func (c *Client) UnmarshalRecord(record Record) (models.Product, error) {
+	product, err := c.UnmarshalProduct(record.Item)
+	if err != nil {
+		return models.Product{}, err
+	}
+
+	product.EventName = record.EventName
+	product.StreamImage = record.StreamImage
+	product.SourceTable = record.Table
+
+	return product, nil
+}
+
+// normalizeRawHTML returns item with a binary rawHtml, as written by
+// migrate-html, replaced by its base64 text. Product.RawHTML is a string,
+// and the extractor recognises the compression once the base64 is decoded.
+func normalizeRawHTML(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	binary, ok := item[rawHTMLAttribute].(*types.AttributeValueMemberB)
+	if !ok {
+		return item
+	}
+
+	normalized := maps.Clone(item)
+	normalized[rawHTMLAttribute] = &types.AttributeValueMemberS{Value: base64.StdEncoding.EncodeToString(binary.Value)}
+	return normalized
+}
+
+func isBinaryHTML(item map[string]types.AttributeValue) bool {
+	_, ok := item[rawHTMLAttribute].(*types.AttributeValueMemberB)
+	return ok
+}
+
+// extractHTML fills in the product's HTML and the codecs it was stored with.
+// A binary rawHtml has been turned into base64 text by normalizeRawHTML, so
+// that layer is taken off again to report the chain as it is stored.
+func (c *Client) extractHTML(product *models.Product, binary bool) {
+	c.logger.Debug("Processing product", "id", product.ID, "rawhtml_length", len(product.RawHTML))
+
+	if product.RawHTML == "" {
+		product.RawHTMLExtracted = "NO_RAW_HTML_DATA"
+		c.logger.Debug("No raw HTML data for product", "id", product.ID)
+		return
+	}
+
+	data := []byte(product.RawHTML)
+	if binary {
+		data, _ = base64.StdEncoding.DecodeString(product.RawHTML)
+	}
+
+	extractedHTML, chain, err := c.htmlExtractor.Extract(data)
+	product.RawHTMLChain = chain
+	if err != nil {
+		product.RawHTMLExtracted = "EXTRACTION_FAILED: " + err.Error()
+		c.logger.Error("HTML extraction failed", "id", product.ID, "error", err)
+	} else {
+		product.RawHTMLExtracted = extractedHTML
+		c.logger.Debug("HTML extraction successful", "id", product.ID, "extracted_length", len(extractedHTML), "chain", strings.Join(chain, ","))
+		c.parseCard(product)
+	}
+}
+
+// parseTextContent fills in the product fields read from rawTextContent.
+func (c *Client) parseTextContent(product *models.Product) {
+	if product.RawTextContent == "" {
+		return
+	}
+
+	text := processing.ParseTextContent(product.RawTextContent)
+	if text.Uncertain {
+		c.logger.Debug("rawTextContent did not parse cleanly", "id", product.ID, "text", product.RawTextContent)
+	}
+
+	product.TextCurrentPrice = text.CurrentPrice
+	product.TextOriginalPrice = text.OriginalPrice
+	product.Quantity = text.Quantity
+	product.Unit = text.Unit
+	product.StockText = text.Stock
+	product.CallToAction = text.CallToAction
+	product.TextUncertain = text.Uncertain
+}
+
+// parseCard fills in the product fields read from its product card HTML.
+func (c *Client) parseCard(product *models.Product) {
+	card, err := processing.ParseProductCard(product.RawHTMLExtracted)
+	if err != nil {
+		c.logger.Debug("No product card parsed", "id", product.ID, "error", err)
+		return
+	}
+
+	product.ParsedName = card.Name
+	product.CurrentPrice = card.CurrentPrice
+	product.OriginalPrice = card.OriginalPrice
+	product.SizeText = card.Size
+	product.ImageSrcset = card.ImageSrcset
+	product.ProductLink = card.Link
 }
@@ -0,0 +1,166 @@
+package dynamodb
+
+import (
+	"strings"
+
+	"github.com/gkwa/bouncingbeaver/internal/models"
+	"github.com/gkwa/bouncingbeaver/internal/processing"
+)
+
+// MismatchKind says how a stored attribute differs from the product card.
+type MismatchKind string
+
+const (
+	// MismatchMissing is an attribute that is empty although the card has
+	// a value for it.
+	MismatchMissing MismatchKind = "missing"
+
+	// MismatchTruncated is a URL attribute holding the card's value up to
+	// a comma, where the scraper cut it while splitting the image srcset,
+	// as with imageUrl values ending at filters:fill(FFFFFF.
+	MismatchTruncated MismatchKind = "truncated"
+
+	// MismatchDifferent is an attribute that disagrees with the card.
+	MismatchDifferent MismatchKind = "different"
+)
+
+// Mismatch is a stored attribute that disagrees with the value parsed from
+// the product card HTML.
+type Mismatch struct {
+	Attribute string
+	Kind      MismatchKind
+	Stored    string
+	HTML      string
+
+	// Repaired is set by RepairProduct once the card's value replaced the
+	// stored one
+	Repaired bool
+}
+
+// Repairable reports whether the card's value can replace the stored one:
+// missing and truncated values are, but a value that disagrees may be the
+// newer of the two.
+func (m Mismatch) Repairable() bool {
+	return m.Kind == MismatchMissing || m.Kind == MismatchTruncated
+}
+
+// cardFields pairs each attribute the scraper stores separately with the
+// product field holding it and the value read from the product card. Only
+// the URLs can have been truncated; a name or price that is the start of the
+// card's value, such as $1 for $1.99, is a different value.
+var cardFields = []struct {
+	attribute   string
+	truncatable bool
+	stored      func(*models.Product) *string
+	html        func(*models.Product) string
+}{
+	{"name", false, func(p *models.Product) *string { return &p.Name }, func(p *models.Product) string { return p.ParsedName }},
+	{"price", false, func(p *models.Product) *string { return &p.Price }, func(p *models.Product) string { return p.CurrentPrice }},
+	{"imageUrl", true, func(p *models.Product) *string { return &p.ImageURL }, func(p *models.Product) string {
+		if urls := processing.SrcsetURLs(p.ImageSrcset); len(urls) > 0 {
+			return urls[0]
+		}
+		return ""
+	}},
+	{"url", true, func(p *models.Product) *string { return &p.URL }, func(p *models.Product) string { return p.ProductLink }},
+}
+
+// CompareProduct checks the name, price, imageUrl and url attributes of a
+// product against the values parsed from its product card. Attributes the
+// card has no value for are not checked, so a product without a card has no
+// mismatches.
+func CompareProduct(product models.Product) []Mismatch {
+	var mismatches []Mismatch
+	for _, field := range cardFields {
+		html := field.html(&product)
+		stored := *field.stored(&product)
+		if html == "" || strings.Join(strings.Fields(stored), " ") == html {
+			continue
+		}
+
+		kind := MismatchDifferent
+		switch {
+		case strings.TrimSpace(stored) == "":
+			kind = MismatchMissing
+		case field.truncatable && cutAtComma(stored, html):
+			kind = MismatchTruncated
+		}
+		mismatches = append(mismatches, Mismatch{Attribute: field.attribute, Kind: kind, Stored: stored, HTML: html})
+	}
+	return mismatches
+}
+
+// cutAtComma reports whether stored is html up to, but not including, one of
+// its commas.
+func cutAtComma(stored, html string) bool {
+	return stored != "" && strings.HasPrefix(html, stored) && html[len(stored)] == ','
+}
+
+// RepairProduct replaces the attributes of product that mismatches found
+// missing or truncated with the values from its product card, marks those
+// mismatches repaired, and returns how many it replaced.
+func RepairProduct(product *models.Product, mismatches []Mismatch) int {
+	repaired := 0
+	for i, mismatch := range mismatches {
+		if !mismatch.Repairable() {
+			continue
+		}
+		for _, field := range cardFields {
+			if field.attribute == mismatch.Attribute {
+				*field.stored(product) = mismatch.HTML
+				mismatches[i].Repaired = true
+				repaired++
+			}
+		}
+	}
+	return repaired
+}
+
+// ProductMismatches lists the mismatches found in one product.
+type ProductMismatches struct {
+	ID         string
+	Mismatches []Mismatch
+}
+
+// ConsistencyReport collects the mismatches between stored attributes and
+// product cards across the products passed to Add.
+type ConsistencyReport struct {
+	repair bool
+
+	Products  int
+	NoCard    int // products whose HTML held no product card
+	Repaired  int // attributes replaced from the card
+	Remaining int // mismatches left in the output
+	Results   []ProductMismatches
+}
+
+// NewConsistencyReport returns an empty report. With repair, Add replaces
+// missing and truncated attributes with the card's values.
+func NewConsistencyReport(repair bool) *ConsistencyReport {
+	return &ConsistencyReport{repair: repair}
+}
+
+// Add compares product with its card and returns it, repaired if the report
+// repairs.
+func (r *ConsistencyReport) Add(product models.Product) models.Product {
+	r.Products++
+	if product.ParsedName == "" && product.CurrentPrice == "" && product.ImageSrcset == "" && product.ProductLink == "" {
+		r.NoCard++
+		return product
+	}
+
+	mismatches := CompareProduct(product)
+	if len(mismatches) == 0 {
+		return product
+	}
+	r.Results = append(r.Results, ProductMismatches{ID: product.ID, Mismatches: mismatches})
+
+	repaired := 0
+	if r.repair {
+		repaired = RepairProduct(&product, mismatches)
+	}
+	r.Repaired += repaired
+	r.Remaining += len(mismatches) - repaired
+
+	return product
+}
@@ -0,0 +1,116 @@
+package dynamodb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gkwa/bouncingbeaver/internal/models"
+)
+
+func TestConsistencyReport_SampleData(t *testing.T) {
+	client := NewClient()
+
+	items, err := client.LoadData("testdata/sample_input.json")
+	if err != nil {
+		t.Fatalf("Failed to load data: %v", err)
+	}
+	products, err := client.UnmarshalProducts(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := NewConsistencyReport(true)
+	for _, product := range products {
+		repaired := report.Add(product)
+		if !strings.HasPrefix(repaired.ImageURL, product.ImageURL) || !strings.HasSuffix(repaired.ImageURL, ".png") {
+			t.Errorf("Expected %s to be completed from the HTML, got %s", product.ImageURL, repaired.ImageURL)
+		}
+	}
+
+	// The scraper cut every imageUrl at the comma inside fill(FFFFFF,true)
+	if len(report.Results) != len(products) || report.Repaired != len(products) || report.Remaining != 0 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	for _, result := range report.Results {
+		if len(result.Mismatches) != 1 || result.Mismatches[0].Attribute != "imageUrl" || result.Mismatches[0].Kind != MismatchTruncated {
+			t.Errorf("Expected only a truncated imageUrl for %s, got %+v", result.ID, result.Mismatches)
+		}
+	}
+}
+
+func TestCompareProduct(t *testing.T) {
+	product := models.Product{
+		ID:           "p1",
+		Name:         "Lime",
+		Price:        "$1.00",
+		ImageURL:     "",
+		URL:          "https://store.example/products/1",
+		ParsedName:   "Key Lime",
+		CurrentPrice: "$0.89",
+		ImageSrcset:  "https://img.example/lime.png 1x, https://img.example/lime@2x.png 2x",
+		ProductLink:  "https://store.example/products/1-lime",
+	}
+
+	mismatches := CompareProduct(product)
+	kinds := map[string]MismatchKind{}
+	for _, mismatch := range mismatches {
+		kinds[mismatch.Attribute] = mismatch.Kind
+	}
+	expected := map[string]MismatchKind{
+		"name":     MismatchDifferent,
+		"price":    MismatchDifferent,
+		"imageUrl": MismatchMissing,
+		"url":      MismatchDifferent,
+	}
+	if len(kinds) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, kinds)
+	}
+	for attribute, kind := range expected {
+		if kinds[attribute] != kind {
+			t.Errorf("%s: expected %s, got %s", attribute, kind, kinds[attribute])
+		}
+	}
+
+	if repaired := RepairProduct(&product, mismatches); repaired != 1 {
+		t.Errorf("Expected 1 repair, got %d", repaired)
+	}
+	if product.ImageURL != "https://img.example/lime.png" || product.URL != "https://store.example/products/1" || product.Price != "$1.00" {
+		t.Errorf("Expected only the missing value replaced, got %+v", product)
+	}
+
+	if mismatches := CompareProduct(models.Product{Name: "No card"}); len(mismatches) != 0 {
+		t.Errorf("Expected a product without a card to have no mismatches, got %v", mismatches)
+	}
+}
+
+func TestCompareProduct_Truncated(t *testing.T) {
+	product := models.Product{
+		ID:           "p2",
+		Name:         "Bass",
+		Price:        "$1",
+		ImageURL:     "https://img.example/197x197/filters:fill(FFFFFF",
+		ParsedName:   "Bass Comb",
+		CurrentPrice: "$1.99",
+		ImageSrcset:  "https://img.example/197x197/filters:fill(FFFFFF,true)/a.png 1x",
+	}
+
+	report := NewConsistencyReport(true)
+	repaired := report.Add(product)
+
+	// Only the URL cut at a comma is truncation; the shorter name and price
+	// are different values and are left alone
+	if repaired.Price != "$1" || repaired.Name != "Bass" {
+		t.Errorf("Expected the name and price kept, got %q and %q", repaired.Name, repaired.Price)
+	}
+	if repaired.ImageURL != "https://img.example/197x197/filters:fill(FFFFFF,true)/a.png" {
+		t.Errorf("Expected the imageUrl completed, got %s", repaired.ImageURL)
+	}
+	if report.Repaired != 1 || report.Remaining != 2 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	for _, mismatch := range report.Results[0].Mismatches {
+		if mismatch.Attribute != "imageUrl" && mismatch.Kind != MismatchDifferent {
+			t.Errorf("Expected %s to be different, got %s", mismatch.Attribute, mismatch.Kind)
+		}
+	}
+}
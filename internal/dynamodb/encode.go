@@ -0,0 +1,73 @@
+package dynamodb
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// encodeItem writes an item back out in DynamoDB JSON, the inverse of
+// parseItem.
+func encodeItem(item map[string]types.AttributeValue) (map[string]json.RawMessage, error) {
+	if item == nil {
+		return nil, nil
+	}
+
+	encoded := make(map[string]json.RawMessage, len(item))
+	for name, value := range item {
+		raw, err := encodeAttributeValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		encoded[name] = raw
+	}
+
+	return encoded, nil
+}
+
+func encodeAttributeValue(value types.AttributeValue) (json.RawMessage, error) {
+	var descriptor interface{}
+
+	switch v := value.(type) {
+	case *types.AttributeValueMemberS:
+		descriptor = map[string]string{"S": v.Value}
+	case *types.AttributeValueMemberN:
+		descriptor = map[string]string{"N": v.Value}
+	case *types.AttributeValueMemberB:
+		descriptor = map[string][]byte{"B": v.Value}
+	case *types.AttributeValueMemberBOOL:
+		descriptor = map[string]bool{"BOOL": v.Value}
+	case *types.AttributeValueMemberNULL:
+		descriptor = map[string]bool{"NULL": true}
+	case *types.AttributeValueMemberSS:
+		descriptor = map[string][]string{"SS": v.Value}
+	case *types.AttributeValueMemberNS:
+		descriptor = map[string][]string{"NS": v.Value}
+	case *types.AttributeValueMemberBS:
+		descriptor = map[string][][]byte{"BS": v.Value}
+	case *types.AttributeValueMemberL:
+		list := make([]json.RawMessage, len(v.Value))
+		for i, element := range v.Value {
+			raw, err := encodeAttributeValue(element)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			list[i] = raw
+		}
+		descriptor = map[string][]json.RawMessage{"L": list}
+	case *types.AttributeValueMemberM:
+		members, err := encodeItem(v.Value)
+		if err != nil {
+			return nil, err
+		}
+		if members == nil {
+			members = map[string]json.RawMessage{}
+		}
+		descriptor = map[string]map[string]json.RawMessage{"M": members}
+	default:
+		return nil, fmt.Errorf("unsupported attribute value type %T", value)
+	}
+
+	return json.Marshal(descriptor)
+}
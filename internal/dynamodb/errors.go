@@ -0,0 +1,55 @@
+package dynamodb
+
+import (
+	"errors"
+	"fmt"
+)
+
+// AttributeError reports a malformed attribute value descriptor along with the
+// index of the item that contains it and the path to the offending value,
+// for example Items[3].rawHtml.S.
+type AttributeError struct {
+	// Source names the input file holding the item when several files are
+	// read in turn; it is empty for a single reader.
+	Source string
+
+	// Array names the array holding the item within its page or document,
+	// such as Records for stream records or Responses for TransactGetItems
+	// output, and Item is its index there. Empty means Items; for input
+	// holding one Item per document, such as an export data file, Item is
+	// the index of the document.
+	Array   string
+	Item    int
+	Path    string
+	Problem string
+}
+
+func (e *AttributeError) Error() string {
+	array := e.Array
+	if array == "" {
+		array = "Items"
+	}
+	message := fmt.Sprintf("%s[%d].%s: %s", array, e.Item, e.Path, e.Problem)
+	if e.Source != "" {
+		return e.Source + ": " + message
+	}
+	return message
+}
+
+// locateAttributeError records where the item holding a malformed value
+// sits, if err is an *AttributeError.
+func locateAttributeError(err error, array string, item int) error {
+	var attrErr *AttributeError
+	if errors.As(err, &attrErr) {
+		attrErr.Array = array
+		attrErr.Item = item
+	}
+	return err
+}
+
+func newAttributeError(path, format string, args ...interface{}) *AttributeError {
+	return &AttributeError{
+		Path:    path,
+		Problem: fmt.Sprintf(format, args...),
+	}
+}
@@ -0,0 +1,262 @@
+package dynamodb
+
+import (
+	"bufio"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	exportSummaryFile = "manifest-summary.json"
+	exportFilesFile   = "manifest-files.json"
+)
+
+// ExportSummary is the manifest-summary.json written alongside a DynamoDB
+// export to S3.
+type ExportSummary struct {
+	Version            string `json:"version"`
+	ExportArn          string `json:"exportArn"`
+	TableArn           string `json:"tableArn"`
+	ExportTime         string `json:"exportTime"`
+	ManifestFilesS3Key string `json:"manifestFilesS3Key"`
+	ItemCount          int    `json:"itemCount"`
+	OutputFormat       string `json:"outputFormat"`
+}
+
+// ExportDataFile is one line of manifest-files.json.
+type ExportDataFile struct {
+	ItemCount     int    `json:"itemCount"`
+	MD5Checksum   string `json:"md5Checksum"`
+	ETag          string `json:"etag"`
+	DataFileS3Key string `json:"dataFileS3Key"`
+}
+
+// ExportFileResult records how a single data file compared to its manifest
+// entry. Problem is empty when the file was read completely and matched.
+type ExportFileResult struct {
+	Path          string
+	ExpectedItems int
+	ActualItems   int
+	Problem       string
+}
+
+// ExportReport summarises a whole export directory.
+type ExportReport struct {
+	Summary       ExportSummary
+	Files         []ExportFileResult
+	ExpectedItems int
+	ActualItems   int
+}
+
+// Problems returns the results for data files that were missing, truncated
+// or did not match the manifest.
+func (r *ExportReport) Problems() []ExportFileResult {
+	var problems []ExportFileResult
+	for _, file := range r.Files {
+		if file.Problem != "" {
+			problems = append(problems, file)
+		}
+	}
+	return problems
+}
+
+// Err summarises the report as an error, or returns nil when every data file
+// matched the manifest.
+func (r *ExportReport) Err() error {
+	problems := r.Problems()
+	if len(problems) == 0 && r.ActualItems == r.ExpectedItems {
+		return nil
+	}
+
+	var details []string
+	for _, problem := range problems {
+		details = append(details, fmt.Sprintf("%s: %s", problem.Path, problem.Problem))
+	}
+	if r.ActualItems != r.ExpectedItems {
+		details = append(details, fmt.Sprintf("read %d items, manifest summary lists %d", r.ActualItems, r.ExpectedItems))
+	}
+
+	return fmt.Errorf("export verification failed: %s", strings.Join(details, "; "))
+}
+
+// ReadExport reads the manifests in dir, a local copy of an export's
+// AWSDynamoDB/<export-id> prefix, and calls fn for every item of every data
+// file the manifest lists. Item counts and MD5 checksums are checked against
+// the manifest. A missing or damaged data file is recorded in the report and
+// the remaining files are still read; the returned error is reserved for
+// unreadable manifests and errors returned by fn.
+func (c *Client) ReadExport(dir string, fn func(map[string]types.AttributeValue) error) (*ExportReport, error) {
+	summary, err := readExportSummary(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	switch summary.OutputFormat {
+	case "", "DYNAMODB_JSON", "ION":
+	default:
+		return nil, fmt.Errorf("unsupported export output format %s", summary.OutputFormat)
+	}
+
+	files, err := readExportFiles(dir, summary)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ExportReport{
+		Summary:       summary,
+		ExpectedItems: summary.ItemCount,
+	}
+
+	for _, file := range files {
+		result, err := c.readExportFile(dir, file, fn)
+		if err != nil {
+			return nil, err
+		}
+
+		if result.Problem != "" {
+			c.logger.Error("Export data file problem", "file", result.Path, "problem", result.Problem)
+		}
+
+		report.Files = append(report.Files, result)
+		report.ActualItems += result.ActualItems
+	}
+
+	return report, nil
+}
+
+func (c *Client) readExportFile(dir string, file ExportDataFile, fn func(map[string]types.AttributeValue) error) (ExportFileResult, error) {
+	result := ExportFileResult{
+		Path:          resolveExportPath(dir, file.DataFileS3Key),
+		ExpectedItems: file.ItemCount,
+	}
+
+	source, err := os.Open(result.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			result.Problem = "missing"
+		} else {
+			result.Problem = err.Error()
+		}
+		return result, nil
+	}
+	defer source.Close()
+
+	// The checksum covers the compressed bytes, so hash the file as it is
+	// read rather than the decompressed stream
+	digest := md5.New()
+	reader, err := decompress(io.NopCloser(io.TeeReader(source, digest)))
+	if err != nil {
+		result.Problem = err.Error()
+		return result, nil
+	}
+	defer reader.Close()
+
+	scanner := c.NewItemScanner(reader)
+	for scanner.Scan() {
+		result.ActualItems++
+		if err := fn(scanner.Item()); err != nil {
+			return result, err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		result.Problem = fmt.Sprintf("truncated or unreadable after %d items: %v", result.ActualItems, err)
+		return result, nil
+	}
+
+	if result.ActualItems != result.ExpectedItems {
+		result.Problem = fmt.Sprintf("expected %d items, found %d", result.ExpectedItems, result.ActualItems)
+		return result, nil
+	}
+
+	if file.MD5Checksum != "" {
+		if err := verifyChecksum(source, digest, file.MD5Checksum); err != nil {
+			result.Problem = err.Error()
+		}
+	}
+
+	return result, nil
+}
+
+func verifyChecksum(source io.Reader, digest hash.Hash, expected string) error {
+	// Trailing bytes the decoder never needed still belong to the file
+	if _, err := io.Copy(digest, source); err != nil {
+		return fmt.Errorf("failed to read for checksum: %w", err)
+	}
+
+	actual := base64.StdEncoding.EncodeToString(digest.Sum(nil))
+	if actual != expected {
+		return fmt.Errorf("md5 checksum mismatch: manifest lists %s, file has %s", expected, actual)
+	}
+
+	return nil
+}
+
+func readExportSummary(dir string) (ExportSummary, error) {
+	var summary ExportSummary
+
+	data, err := os.ReadFile(filepath.Join(dir, exportSummaryFile))
+	if err != nil {
+		return summary, fmt.Errorf("failed to read export summary: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return summary, fmt.Errorf("failed to parse %s: %w", exportSummaryFile, err)
+	}
+
+	return summary, nil
+}
+
+func readExportFiles(dir string, summary ExportSummary) ([]ExportDataFile, error) {
+	name := exportFilesFile
+	if summary.ManifestFilesS3Key != "" {
+		name = path.Base(summary.ManifestFilesS3Key)
+	}
+
+	file, err := os.Open(filepath.Join(dir, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export file manifest: %w", err)
+	}
+	defer file.Close()
+
+	var files []ExportDataFile
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var entry ExportDataFile
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse %s line %d: %w", name, line, err)
+		}
+		files = append(files, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	return files, nil
+}
+
+// resolveExportPath maps an S3 key such as
+// AWSDynamoDB/<export-id>/data/<name>.json.gz onto the local export directory.
+func resolveExportPath(dir, key string) string {
+	if i := strings.LastIndex(key, "/data/"); i >= 0 {
+		return filepath.Join(dir, filepath.FromSlash(key[i+1:]))
+	}
+	return filepath.Join(dir, "data", path.Base(key))
+}
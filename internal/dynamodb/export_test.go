@@ -0,0 +1,136 @@
+package dynamodb
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestReadExport(t *testing.T) {
+	client := NewClient()
+
+	var ids []string
+	report, err := client.ReadExport("testdata/export", func(item map[string]types.AttributeValue) error {
+		ids = append(ids, item["id"].(*types.AttributeValueMemberS).Value)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+
+	if err := report.Err(); err != nil {
+		t.Errorf("Expected a clean export, got %v", err)
+	}
+
+	if strings.Join(ids, ",") != "p-1,p-2,p-3" {
+		t.Errorf("Expected items p-1,p-2,p-3 in manifest order, got %v", ids)
+	}
+
+	if len(report.Files) != 2 || report.ActualItems != 3 || report.ExpectedItems != 3 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+}
+
+func TestReadExport_MissingAndTruncatedFiles(t *testing.T) {
+	client := NewClient()
+
+	dir := copyExport(t, "testdata/export")
+
+	// Remove the first data file and cut the second one short
+	first := filepath.Join(dir, "data", "uwf2ixvyba3j7m5kcbfizvxd7q.json.gz")
+	if err := os.Remove(first); err != nil {
+		t.Fatal(err)
+	}
+
+	second := filepath.Join(dir, "data", "x7hbn5ebiy6pvo5pymmb2kmxey.json.gz")
+	data, err := os.ReadFile(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(second, data[:len(data)/2], 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := client.ReadExport(dir, func(map[string]types.AttributeValue) error { return nil })
+	if err != nil {
+		t.Fatalf("Expected problems in the report rather than an error: %v", err)
+	}
+
+	problems := report.Problems()
+	if len(problems) != 2 {
+		t.Fatalf("Expected 2 problems, got %+v", problems)
+	}
+
+	if problems[0].Problem != "missing" {
+		t.Errorf("Expected first file to be missing, got %q", problems[0].Problem)
+	}
+
+	if !strings.HasPrefix(problems[1].Problem, "truncated") {
+		t.Errorf("Expected second file to be truncated, got %q", problems[1].Problem)
+	}
+
+	if report.Err() == nil {
+		t.Error("Expected report error")
+	}
+}
+
+func TestReadExport_ChecksumMismatch(t *testing.T) {
+	client := NewClient()
+
+	dir := copyExport(t, "testdata/export")
+
+	manifest := filepath.Join(dir, "manifest-files.json")
+	data, err := os.ReadFile(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = []byte(strings.Replace(string(data), "6HajM2llw14eK7YPFlducA==", "AAAAAAAAAAAAAAAAAAAAAA==", 1))
+	if err := os.WriteFile(manifest, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := client.ReadExport(dir, func(map[string]types.AttributeValue) error { return nil })
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+
+	problems := report.Problems()
+	if len(problems) != 1 || !strings.Contains(problems[0].Problem, "checksum") {
+		t.Errorf("Expected one checksum problem, got %+v", problems)
+	}
+}
+
+func copyExport(t *testing.T, src string) string {
+	t.Helper()
+
+	dst := t.TempDir()
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		if info.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0o644)
+	})
+	if err != nil {
+		t.Fatalf("Failed to copy export: %v", err)
+	}
+
+	return dst
+}
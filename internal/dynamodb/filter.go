@@ -0,0 +1,110 @@
+package dynamodb
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gkwa/bouncingbeaver/internal/expression"
+)
+
+// ItemFilter applies the filter and projection expressions of a
+// QueryOptions, or the WHERE clause and select list of a PartiQL SELECT, to
+// items read from files, so the expressions sent to a live table select the
+// same items from a local copy of it.
+type ItemFilter struct {
+	condition  *expression.Condition
+	projection *expression.Projection
+
+	// table is the table a SELECT reads. Records listed under another table
+	// in a BatchGetItem response are skipped.
+	table string
+}
+
+// NewItemFilter parses options.Filter and options.Projection against
+// options' expression attribute names and values. Either expression may be
+// empty, in which case items are kept or left whole.
+func (c *Client) NewItemFilter(options QueryOptions) (*ItemFilter, error) {
+	filter := &ItemFilter{}
+
+	if options.Filter != "" {
+		condition, err := expression.ParseCondition(options.Filter, options.ExpressionAttributeNames, options.ExpressionAttributeValues)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse filter expression: %w", err)
+		}
+		filter.condition = condition
+	}
+
+	if options.Projection != "" {
+		projection, err := expression.ParseProjection(options.Projection, options.ExpressionAttributeNames)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse projection expression: %w", err)
+		}
+		filter.projection = projection
+	}
+
+	return filter, nil
+}
+
+// NewSelectFilter parses a PartiQL SELECT statement, with parameters
+// standing in for its ? markers. Reading through a secondary index is
+// rejected, since files hold no index to read.
+func (c *Client) NewSelectFilter(statement string, parameters []types.AttributeValue) (*ItemFilter, error) {
+	parsed, err := expression.ParseSelect(statement, parameters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PartiQL statement: %w", err)
+	}
+	if parsed.Index != "" {
+		return nil, fmt.Errorf("failed to parse PartiQL statement: index %s of %s cannot be read from local data", parsed.Index, parsed.Table)
+	}
+
+	return &ItemFilter{
+		condition:  parsed.Where,
+		projection: parsed.Projection,
+		table:      parsed.Table,
+	}, nil
+}
+
+// Apply reports whether item passes the filter and returns it reduced to the
+// projected attributes.
+func (f *ItemFilter) Apply(item map[string]types.AttributeValue) (map[string]types.AttributeValue, bool) {
+	if f.condition != nil && !f.condition.Matches(item) {
+		return nil, false
+	}
+	if f.projection != nil {
+		item = f.projection.Apply(item)
+	}
+	return item, true
+}
+
+// Projects reports whether the filter reduces items to a projection, in
+// which case they no longer hold a whole product.
+func (f *ItemFilter) Projects() bool {
+	return f.projection != nil
+}
+
+// applyRecord is Apply for a record, which also has to belong to the table
+// the filter reads when the input says which table it came from.
+func (f *ItemFilter) applyRecord(record Record) (Record, bool) {
+	if f.table != "" && record.Table != "" && record.Table != f.table {
+		return Record{}, false
+	}
+
+	item, ok := f.Apply(record.Item)
+	if !ok {
+		return Record{}, false
+	}
+	record.Item = item
+	return record, true
+}
+
+// FilterItems applies the filter to every item, such as those returned by
+// LoadData, keeping the ones that pass in their original order.
+func (f *ItemFilter) FilterItems(items []map[string]types.AttributeValue) []map[string]types.AttributeValue {
+	var kept []map[string]types.AttributeValue
+	for _, item := range items {
+		if projected, ok := f.Apply(item); ok {
+			kept = append(kept, projected)
+		}
+	}
+	return kept
+}
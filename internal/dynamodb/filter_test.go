@@ -0,0 +1,132 @@
+package dynamodb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestItemFilter_FilterItems(t *testing.T) {
+	client := NewClient()
+
+	items, err := client.LoadData("testdata/sample_input.json")
+	if err != nil {
+		t.Fatalf("Failed to load data: %v", err)
+	}
+
+	values, err := client.ParseExpressionValues(`{":d": {"S": "2025-05-22#"}, ":na": {"S": "N/A"}, ":ice": {"S": "Ice"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filter, err := client.NewItemFilter(QueryOptions{
+		Filter:                    "begins_with(#ts, :d) AND pricePerUnit = :na AND contains(#n, :ice)",
+		Projection:                "id, #n",
+		ExpressionAttributeNames:  map[string]string{"#ts": "timestamp", "#n": "name"},
+		ExpressionAttributeValues: values,
+	})
+	if err != nil {
+		t.Fatalf("NewItemFilter failed: %v", err)
+	}
+
+	kept := filter.FilterItems(items)
+	if len(kept) == 0 || len(kept) >= len(items) {
+		t.Fatalf("Expected the filter to keep some of the %d items, kept %d", len(items), len(kept))
+	}
+	for _, item := range kept {
+		name := item["name"].(*types.AttributeValueMemberS).Value
+		if len(item) != 2 || !strings.Contains(name, "Ice") {
+			t.Errorf("Unexpected item: %v", item)
+		}
+	}
+
+	products, err := client.UnmarshalProducts(kept)
+	if err != nil || len(products) != len(kept) {
+		t.Errorf("Expected projected items to unmarshal, got %d products, %v", len(products), err)
+	}
+}
+
+func TestItemScanner_SetFilter(t *testing.T) {
+	client := NewClient()
+
+	filter, err := client.NewItemFilter(QueryOptions{
+		Filter:                    "n > :n",
+		ExpressionAttributeValues: map[string]types.AttributeValue{":n": &types.AttributeValueMemberN{Value: "1"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := `{"Items": [{"n": {"N": "1"}}, {"n": {"N": "2"}}, {"n": {"N": "0"}}, {"n": {"N": "3"}}]}`
+	scanner := client.NewItemScanner(strings.NewReader(input))
+	scanner.SetFilter(filter)
+
+	var kept []string
+	for scanner.Scan() {
+		kept = append(kept, scanner.Item()["n"].(*types.AttributeValueMemberN).Value)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(kept, ",") != "2,3" {
+		t.Errorf("Expected 2,3, got %v", kept)
+	}
+}
+
+func TestNewItemFilter_Errors(t *testing.T) {
+	client := NewClient()
+
+	for _, options := range []QueryOptions{
+		{Filter: "a = :missing"},
+		{Filter: "a ="},
+		{Projection: "a, a.b"},
+		{Projection: "#missing"},
+	} {
+		if _, err := client.NewItemFilter(options); err == nil {
+			t.Errorf("Expected %+v to be rejected", options)
+		}
+	}
+}
+
+func TestNewSelectFilter(t *testing.T) {
+	client := NewClient()
+
+	parameters, err := client.ParseParameters(`[{"N": "1"}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filter, err := client.NewSelectFilter(`SELECT id FROM "orders" WHERE n > ?`, parameters)
+	if err != nil {
+		t.Fatalf("NewSelectFilter failed: %v", err)
+	}
+
+	input := `{"Responses": {
+		"orders": [{"id": {"S": "o1"}, "n": {"N": "2"}}, {"id": {"S": "o2"}, "n": {"N": "1"}}],
+		"customers": [{"id": {"S": "c1"}, "n": {"N": "5"}}]
+	}}`
+	scanner := client.NewItemScanner(strings.NewReader(input))
+	scanner.SetFilter(filter)
+
+	var kept []string
+	for scanner.Scan() {
+		if len(scanner.Item()) != 1 {
+			t.Errorf("Expected only id to be selected, got %v", scanner.Item())
+		}
+		kept = append(kept, scanner.Item()["id"].(*types.AttributeValueMemberS).Value)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(kept, ",") != "o1" {
+		t.Errorf("Expected only o1 from the orders table, got %v", kept)
+	}
+
+	if _, err := client.NewSelectFilter(`SELECT * FROM orders."by-date"`, nil); err == nil {
+		t.Error("Expected reading an index to be rejected")
+	}
+	if _, err := client.ParseParameters(`[{"X": "1"}]`); err == nil {
+		t.Error("Expected an invalid parameter to be rejected")
+	}
+}
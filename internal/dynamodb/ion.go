@@ -0,0 +1,775 @@
+package dynamodb
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ionSource reads the Amazon Ion text written by a DynamoDB export in ION
+// format. Each top-level value is a struct such as
+//
+//	$ion_1_0 {Item:{id:"abc",price:9.99,tags:$dynamodb_SS::["a","b"]}}
+//
+// Only the subset of Ion that DynamoDB produces is understood: structs,
+// lists, strings, symbols used as field names and annotations, numbers,
+// booleans, nulls and blobs.
+type ionSource struct {
+	reader *bufio.Reader
+	line   int
+	items  int // items read so far
+}
+
+type ionKind int
+
+const (
+	ionNull ionKind = iota
+	ionBool
+	ionNumber
+	ionString
+	ionSymbol
+	ionBlob
+	ionList
+	ionSexp
+	ionStruct
+)
+
+func (k ionKind) String() string {
+	return [...]string{"null", "bool", "number", "string", "symbol", "blob", "list", "sexp", "struct"}[k]
+}
+
+type ionValue struct {
+	kind        ionKind
+	annotations []string
+	text        string
+	blob        []byte
+	boolean     bool
+	elements    []ionValue
+	fields      []ionField
+}
+
+type ionField struct {
+	name  string
+	value ionValue
+}
+
+func newIonSource(reader *bufio.Reader) *ionSource {
+	return &ionSource{
+		reader: reader,
+		line:   1,
+	}
+}
+
+func (s *ionSource) next() (Record, error) {
+	for {
+		if err := s.skipSpace(); err != nil {
+			return Record{}, err
+		}
+
+		value, err := s.parseValue()
+		if err != nil {
+			return Record{}, err
+		}
+
+		// Version markers and local symbol tables carry no items
+		if value.kind == ionSymbol && len(value.annotations) == 0 && strings.HasPrefix(value.text, "$ion_") {
+			continue
+		}
+		if value.hasAnnotation("$ion_symbol_table") {
+			continue
+		}
+
+		if value.kind != ionStruct {
+			return Record{}, s.errorf("expected a struct holding an Item, got %s", value.kind)
+		}
+
+		item := value.field("Item")
+		if item == nil {
+			return Record{}, s.errorf("expected an Item field")
+		}
+		if item.kind != ionStruct {
+			return Record{}, s.errorf("expected Item to be a struct, got %s", item.kind)
+		}
+
+		attributes := make(map[string]types.AttributeValue, len(item.fields))
+		for _, field := range item.fields {
+			attributeValue, err := field.value.attributeValue(field.name)
+			if err != nil {
+				return Record{}, locateAttributeError(err, "", s.items)
+			}
+			attributes[field.name] = attributeValue
+		}
+
+		s.items++
+		return Record{Item: attributes}, nil
+	}
+}
+
+// attributeValue maps an Ion value onto the DynamoDB type an ION export
+// uses for it.
+func (v ionValue) attributeValue(path string) (types.AttributeValue, error) {
+	switch {
+	case v.hasAnnotation("$dynamodb_SS"):
+		ss := make([]string, 0, len(v.elements))
+		for i, element := range v.elements {
+			if element.kind != ionString {
+				return nil, newAttributeError(fmt.Sprintf("%s[%d]", path, i), "expected a string in string set, got %s", element.kind)
+			}
+			ss = append(ss, element.text)
+		}
+		return &types.AttributeValueMemberSS{Value: ss}, nil
+
+	case v.hasAnnotation("$dynamodb_NS"):
+		ns := make([]string, 0, len(v.elements))
+		for i, element := range v.elements {
+			if element.kind != ionNumber {
+				return nil, newAttributeError(fmt.Sprintf("%s[%d]", path, i), "expected a number in number set, got %s", element.kind)
+			}
+			ns = append(ns, element.text)
+		}
+		return &types.AttributeValueMemberNS{Value: ns}, nil
+
+	case v.hasAnnotation("$dynamodb_BS"):
+		bs := make([][]byte, 0, len(v.elements))
+		for i, element := range v.elements {
+			if element.kind != ionBlob {
+				return nil, newAttributeError(fmt.Sprintf("%s[%d]", path, i), "expected a blob in binary set, got %s", element.kind)
+			}
+			bs = append(bs, element.blob)
+		}
+		return &types.AttributeValueMemberBS{Value: bs}, nil
+	}
+
+	switch v.kind {
+	case ionNull:
+		return &types.AttributeValueMemberNULL{Value: true}, nil
+	case ionBool:
+		return &types.AttributeValueMemberBOOL{Value: v.boolean}, nil
+	case ionNumber:
+		return &types.AttributeValueMemberN{Value: v.text}, nil
+	case ionString:
+		return &types.AttributeValueMemberS{Value: v.text}, nil
+	case ionBlob:
+		return &types.AttributeValueMemberB{Value: v.blob}, nil
+	case ionList:
+		list := make([]types.AttributeValue, 0, len(v.elements))
+		for i, element := range v.elements {
+			attributeValue, err := element.attributeValue(fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, attributeValue)
+		}
+		return &types.AttributeValueMemberL{Value: list}, nil
+	case ionStruct:
+		m := make(map[string]types.AttributeValue, len(v.fields))
+		for _, field := range v.fields {
+			attributeValue, err := field.value.attributeValue(path + "." + field.name)
+			if err != nil {
+				return nil, err
+			}
+			m[field.name] = attributeValue
+		}
+		return &types.AttributeValueMemberM{Value: m}, nil
+	default:
+		return nil, newAttributeError(path, "unsupported Ion %s value", v.kind)
+	}
+}
+
+func (v ionValue) hasAnnotation(annotation string) bool {
+	for _, a := range v.annotations {
+		if a == annotation {
+			return true
+		}
+	}
+	return false
+}
+
+func (v ionValue) field(name string) *ionValue {
+	for i := range v.fields {
+		if v.fields[i].name == name {
+			return &v.fields[i].value
+		}
+	}
+	return nil
+}
+
+func (s *ionSource) parseValue() (ionValue, error) {
+	var annotations []string
+
+	for {
+		r, err := s.peek()
+		if err != nil {
+			return ionValue{}, s.unexpectedEOF(err)
+		}
+
+		var value ionValue
+		switch {
+		case r == '{':
+			value, err = s.parseBraces()
+		case r == '[':
+			s.read()
+			value = ionValue{kind: ionList}
+			value.elements, err = s.parseSequence(']')
+		case r == '(':
+			s.read()
+			value = ionValue{kind: ionSexp}
+			value.elements, err = s.parseSequence(')')
+		case r == '"':
+			value = ionValue{kind: ionString}
+			value.text, err = s.parseQuoted('"')
+		case r == '\'':
+			var long bool
+			long, err = s.hasPrefix("'''")
+			if err != nil {
+				return ionValue{}, err
+			}
+			if long {
+				value = ionValue{kind: ionString}
+				value.text, err = s.parseLongString()
+				break
+			}
+			value = ionValue{kind: ionSymbol}
+			value.text, err = s.parseQuoted('\'')
+		case r == '-' || r == '+' || (r >= '0' && r <= '9'):
+			value, err = s.parseNumber()
+		case isIdentifierStart(r):
+			value, err = s.parseIdentifier()
+		default:
+			return ionValue{}, s.errorf("unexpected character %q", r)
+		}
+		if err != nil {
+			return ionValue{}, err
+		}
+
+		// A symbol followed by :: annotates the value that comes next
+		if value.kind == ionSymbol {
+			if err := s.skipSpace(); err != nil && err != io.EOF {
+				return ionValue{}, err
+			}
+			annotation, err := s.hasPrefix("::")
+			if err != nil {
+				return ionValue{}, err
+			}
+			if annotation {
+				s.read()
+				s.read()
+				annotations = append(annotations, value.text)
+				if err := s.skipSpace(); err != nil {
+					return ionValue{}, s.unexpectedEOF(err)
+				}
+				continue
+			}
+		}
+
+		value.annotations = annotations
+		return value, nil
+	}
+}
+
+func (s *ionSource) parseBraces() (ionValue, error) {
+	s.read()
+
+	blob, err := s.hasPrefix("{")
+	if err != nil {
+		return ionValue{}, err
+	}
+	if blob {
+		s.read()
+		return s.parseLob()
+	}
+
+	value := ionValue{kind: ionStruct}
+	for {
+		if err := s.skipSpace(); err != nil {
+			return ionValue{}, s.unexpectedEOF(err)
+		}
+
+		r, _ := s.peek()
+		if r == '}' {
+			s.read()
+			return value, nil
+		}
+
+		name, err := s.parseFieldName()
+		if err != nil {
+			return ionValue{}, err
+		}
+
+		if err := s.skipSpace(); err != nil {
+			return ionValue{}, s.unexpectedEOF(err)
+		}
+		if r, _ := s.read(); r != ':' {
+			return ionValue{}, s.errorf("expected ':' after field name %q", name)
+		}
+		if err := s.skipSpace(); err != nil {
+			return ionValue{}, s.unexpectedEOF(err)
+		}
+
+		fieldValue, err := s.parseValue()
+		if err != nil {
+			return ionValue{}, err
+		}
+		value.fields = append(value.fields, ionField{name: name, value: fieldValue})
+
+		if err := s.endOfElement('}'); err != nil {
+			return ionValue{}, err
+		}
+	}
+}
+
+func (s *ionSource) parseFieldName() (string, error) {
+	r, err := s.peek()
+	if err != nil {
+		return "", s.unexpectedEOF(err)
+	}
+
+	switch {
+	case r == '"':
+		return s.parseQuoted('"')
+	case r == '\'':
+		long, err := s.hasPrefix("'''")
+		if err != nil {
+			return "", err
+		}
+		if long {
+			return s.parseLongString()
+		}
+		return s.parseQuoted('\'')
+	case isIdentifierStart(r):
+		return s.readWhile(isIdentifierPart)
+	default:
+		return "", s.errorf("unexpected character %q in field name", r)
+	}
+}
+
+func (s *ionSource) parseSequence(closing rune) ([]ionValue, error) {
+	var elements []ionValue
+	for {
+		if err := s.skipSpace(); err != nil {
+			return nil, s.unexpectedEOF(err)
+		}
+
+		r, _ := s.peek()
+		if r == closing {
+			s.read()
+			return elements, nil
+		}
+
+		element, err := s.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+
+		if closing == ')' {
+			continue
+		}
+		if err := s.endOfElement(closing); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// endOfElement consumes the comma after a list element or struct field, or
+// leaves the closing delimiter for the caller.
+func (s *ionSource) endOfElement(closing rune) error {
+	if err := s.skipSpace(); err != nil {
+		return s.unexpectedEOF(err)
+	}
+
+	r, _ := s.peek()
+	switch r {
+	case ',':
+		s.read()
+		return nil
+	case closing:
+		return nil
+	default:
+		return s.errorf("expected ',' or %q, got %q", closing, r)
+	}
+}
+
+// parseLob reads the remainder of a {{ }} blob or clob.
+func (s *ionSource) parseLob() (ionValue, error) {
+	if err := s.skipSpace(); err != nil {
+		return ionValue{}, s.unexpectedEOF(err)
+	}
+
+	value := ionValue{kind: ionBlob}
+
+	r, err := s.peek()
+	if err != nil {
+		return ionValue{}, s.unexpectedEOF(err)
+	}
+
+	if r == '"' {
+		text, err := s.parseQuoted('"')
+		if err != nil {
+			return ionValue{}, err
+		}
+		value.blob = []byte(text)
+	} else {
+		var encoded strings.Builder
+		for {
+			r, err := s.peek()
+			if err != nil {
+				return ionValue{}, s.unexpectedEOF(err)
+			}
+			if r == '}' {
+				break
+			}
+			s.read()
+			if r != ' ' && r != '\t' && r != '\n' && r != '\r' {
+				encoded.WriteRune(r)
+			}
+		}
+
+		value.blob, err = base64.StdEncoding.DecodeString(encoded.String())
+		if err != nil {
+			return ionValue{}, s.errorf("invalid blob: %v", err)
+		}
+	}
+
+	if err := s.skipSpace(); err != nil {
+		return ionValue{}, s.unexpectedEOF(err)
+	}
+	closed, err := s.hasPrefix("}}")
+	if err != nil {
+		return ionValue{}, err
+	}
+	if !closed {
+		return ionValue{}, s.errorf("expected '}}' to close blob")
+	}
+	s.read()
+	s.read()
+
+	return value, nil
+}
+
+func (s *ionSource) parseIdentifier() (ionValue, error) {
+	word, err := s.readWhile(isIdentifierPart)
+	if err != nil {
+		return ionValue{}, err
+	}
+
+	switch word {
+	case "true", "false":
+		return ionValue{kind: ionBool, boolean: word == "true"}, nil
+	case "null":
+		// Typed nulls such as null.string are all DynamoDB NULLs
+		if r, err := s.peek(); err == nil && r == '.' {
+			s.read()
+			if _, err := s.readWhile(isIdentifierPart); err != nil {
+				return ionValue{}, err
+			}
+		}
+		return ionValue{kind: ionNull}, nil
+	case "nan":
+		return ionValue{}, s.errorf("nan cannot be stored as a DynamoDB number")
+	default:
+		return ionValue{kind: ionSymbol, text: word}, nil
+	}
+}
+
+func (s *ionSource) parseNumber() (ionValue, error) {
+	text, err := s.readWhile(func(r rune) bool {
+		return r == '.' || r == '_' || r == '+' || r == '-' || r == ':' ||
+			(r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+	})
+	if err != nil {
+		return ionValue{}, err
+	}
+
+	number, err := ionNumberToDynamoDB(text)
+	if err != nil {
+		return ionValue{}, s.errorf("%v", err)
+	}
+
+	return ionValue{kind: ionNumber, text: number}, nil
+}
+
+// ionNumberToDynamoDB converts Ion int, decimal and float literals into the
+// string form DynamoDB uses for N values.
+func ionNumberToDynamoDB(text string) (string, error) {
+	if text == "+inf" || text == "-inf" {
+		return "", fmt.Errorf("%s cannot be stored as a DynamoDB number", text)
+	}
+
+	digits := strings.ReplaceAll(text, "_", "")
+	unsigned := strings.TrimPrefix(digits, "-")
+
+	if len(unsigned) > 2 && unsigned[0] == '0' && strings.ContainsRune("xXbB", rune(unsigned[1])) {
+		n, ok := new(big.Int).SetString(digits, 0)
+		if !ok {
+			return "", fmt.Errorf("invalid integer %q", text)
+		}
+		return n.String(), nil
+	}
+
+	if i := strings.IndexAny(digits, "dD"); i >= 0 {
+		mantissa := strings.TrimSuffix(digits[:i], ".")
+		exponent := digits[i+1:]
+		digits = mantissa + "e" + exponent
+	} else {
+		digits = strings.TrimSuffix(digits, ".")
+	}
+
+	if !numberPattern.MatchString(digits) {
+		return "", fmt.Errorf("unsupported Ion number %q", text)
+	}
+
+	return digits, nil
+}
+
+// parseQuoted reads a "string" or 'symbol' including escape sequences.
+func (s *ionSource) parseQuoted(quote rune) (string, error) {
+	s.read()
+
+	var text strings.Builder
+	for {
+		r, err := s.read()
+		if err != nil {
+			return "", s.unexpectedEOF(err)
+		}
+
+		switch r {
+		case quote:
+			return text.String(), nil
+		case '\\':
+			if err := s.parseEscape(&text); err != nil {
+				return "", err
+			}
+		case '\n':
+			return "", s.errorf("unterminated string")
+		default:
+			text.WriteRune(r)
+		}
+	}
+}
+
+// parseLongString reads one or more adjacent triple-quoted long strings, which Ion
+// concatenates.
+func (s *ionSource) parseLongString() (string, error) {
+	var text strings.Builder
+	for {
+		for i := 0; i < 3; i++ {
+			s.read()
+		}
+
+		for {
+			closing, err := s.hasPrefix("'''")
+			if err != nil {
+				return "", err
+			}
+			if closing {
+				for i := 0; i < 3; i++ {
+					s.read()
+				}
+				break
+			}
+
+			r, err := s.read()
+			if err != nil {
+				return "", s.unexpectedEOF(err)
+			}
+			if r == '\\' {
+				if err := s.parseEscape(&text); err != nil {
+					return "", err
+				}
+				continue
+			}
+			text.WriteRune(r)
+		}
+
+		if err := s.skipSpace(); err != nil && err != io.EOF {
+			return "", err
+		}
+		more, err := s.hasPrefix("'''")
+		if err != nil {
+			return "", err
+		}
+		if !more {
+			return text.String(), nil
+		}
+	}
+}
+
+func (s *ionSource) parseEscape(text *strings.Builder) error {
+	r, err := s.read()
+	if err != nil {
+		return s.unexpectedEOF(err)
+	}
+
+	simple := map[rune]string{
+		'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", 'n': "\n", 'f': "\f", 'r': "\r", 'v': "\v",
+		'"': "\"", '\'': "'", '?': "?", '\\': "\\", '/': "/",
+	}
+	if replacement, ok := simple[r]; ok {
+		text.WriteString(replacement)
+		return nil
+	}
+
+	var width int
+	switch r {
+	case '\n':
+		// Escaped newlines join lines
+		return nil
+	case 'x':
+		width = 2
+	case 'u':
+		width = 4
+	case 'U':
+		width = 8
+	default:
+		return s.errorf("invalid escape sequence \\%c", r)
+	}
+
+	var hex strings.Builder
+	for i := 0; i < width; i++ {
+		h, err := s.read()
+		if err != nil {
+			return s.unexpectedEOF(err)
+		}
+		hex.WriteRune(h)
+	}
+
+	code, err := strconv.ParseUint(hex.String(), 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		return s.errorf("invalid escape sequence \\%c%s", r, hex.String())
+	}
+	text.WriteRune(rune(code))
+
+	return nil
+}
+
+// skipSpace skips whitespace and comments, returning io.EOF at the end of
+// the input.
+func (s *ionSource) skipSpace() error {
+	for {
+		r, err := s.peek()
+		if err != nil {
+			return err
+		}
+
+		switch r {
+		case ' ', '\t', '\r', '\n', '\v', '\f':
+			s.read()
+			continue
+		case '/':
+			lineComment, err := s.hasPrefix("//")
+			if err != nil {
+				return err
+			}
+			blockComment, err := s.hasPrefix("/*")
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case lineComment:
+				if _, err := s.readWhile(func(r rune) bool { return r != '\n' }); err != nil {
+					return err
+				}
+				continue
+			case blockComment:
+				s.read()
+				s.read()
+				for {
+					end, err := s.hasPrefix("*/")
+					if err != nil {
+						return err
+					}
+					if end {
+						s.read()
+						s.read()
+						break
+					}
+					if _, err := s.read(); err != nil {
+						return s.unexpectedEOF(err)
+					}
+				}
+				continue
+			}
+		}
+
+		return nil
+	}
+}
+
+func (s *ionSource) readWhile(accept func(rune) bool) (string, error) {
+	var text strings.Builder
+	for {
+		r, err := s.peek()
+		if err == io.EOF {
+			return text.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if !accept(r) {
+			return text.String(), nil
+		}
+		s.read()
+		text.WriteRune(r)
+	}
+}
+
+func (s *ionSource) hasPrefix(prefix string) (bool, error) {
+	peeked, err := s.reader.Peek(len(prefix))
+	if err == io.EOF {
+		return false, nil
+	}
+	if err != nil && err != bufio.ErrBufferFull {
+		return false, err
+	}
+	return string(peeked) == prefix, nil
+}
+
+func (s *ionSource) peek() (rune, error) {
+	r, _, err := s.reader.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	if err := s.reader.UnreadRune(); err != nil {
+		return 0, err
+	}
+	return r, nil
+}
+
+func (s *ionSource) read() (rune, error) {
+	r, _, err := s.reader.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	if r == '\n' {
+		s.line++
+	}
+	return r, nil
+}
+
+func (s *ionSource) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("failed to parse Ion at line %d: %s", s.line, fmt.Sprintf(format, args...))
+}
+
+func (s *ionSource) unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return s.errorf("unexpected end of input")
+	}
+	return err
+}
+
+func isIdentifierStart(r rune) bool {
+	return r == '$' || r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func isIdentifierPart(r rune) bool {
+	return isIdentifierStart(r) || (r >= '0' && r <= '9')
+}
@@ -0,0 +1,100 @@
+package dynamodb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestLoadData_IonExport(t *testing.T) {
+	client := NewClient()
+
+	input := `$ion_1_0 {Item:{id:"p-1",name:"Arctic Glacier Bag of Ice",price:"$2.29",ttl:1750481534.,weight:7.5,scaled:15d-1,active:true,badge:null,blob:{{aGVsbG8=}},tags:$dynamodb_SS::["ice","frozen"],sizes:$dynamodb_NS::[1.,2.5],history:[1.,"two",{nested:false}],'quoted field':"aéb"}}
+$ion_1_0 {Item:{id:"p-2", // comment
+  dims:{w:0x10, h:1_000}}}
+`
+
+	items, err := client.LoadDataFromReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Failed to load Ion data: %v", err)
+	}
+
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(items))
+	}
+
+	item := items[0]
+
+	checks := map[string]string{
+		"ttl":    "1750481534",
+		"weight": "7.5",
+		"scaled": "15e-1",
+	}
+	for name, want := range checks {
+		if got := item[name].(*types.AttributeValueMemberN).Value; got != want {
+			t.Errorf("Expected %s %q, got %q", name, want, got)
+		}
+	}
+
+	if v := item["price"].(*types.AttributeValueMemberS).Value; v != "$2.29" {
+		t.Errorf("Expected price '$2.29', got %q", v)
+	}
+
+	if _, ok := item["badge"].(*types.AttributeValueMemberNULL); !ok {
+		t.Errorf("Expected badge to be NULL, got %T", item["badge"])
+	}
+
+	if v := item["blob"].(*types.AttributeValueMemberB).Value; string(v) != "hello" {
+		t.Errorf("Expected blob 'hello', got %q", v)
+	}
+
+	if v := item["tags"].(*types.AttributeValueMemberSS).Value; len(v) != 2 || v[0] != "ice" {
+		t.Errorf("Expected tags [ice frozen], got %v", v)
+	}
+
+	if v := item["sizes"].(*types.AttributeValueMemberNS).Value; len(v) != 2 || v[0] != "1" {
+		t.Errorf("Expected sizes [1 2.5], got %v", v)
+	}
+
+	history := item["history"].(*types.AttributeValueMemberL).Value
+	nested := history[2].(*types.AttributeValueMemberM).Value
+	if nested["nested"].(*types.AttributeValueMemberBOOL).Value {
+		t.Error("Expected history[2].nested to be false")
+	}
+
+	if v := item["quoted field"].(*types.AttributeValueMemberS).Value; v != "aéb" {
+		t.Errorf("Expected quoted field 'aéb', got %q", v)
+	}
+
+	dims := items[1]["dims"].(*types.AttributeValueMemberM).Value
+	if w := dims["w"].(*types.AttributeValueMemberN).Value; w != "16" {
+		t.Errorf("Expected dims.w '16', got %q", w)
+	}
+	if h := dims["h"].(*types.AttributeValueMemberN).Value; h != "1000" {
+		t.Errorf("Expected dims.h '1000', got %q", h)
+	}
+}
+
+func TestLoadData_IonErrors(t *testing.T) {
+	client := NewClient()
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"missing Item", `$ion_1_0 {Other:{id:"a"}}`},
+		{"unterminated struct", `$ion_1_0 {Item:{id:"a"`},
+		{"infinite number", `$ion_1_0 {Item:{n:+inf}}`},
+		{"timestamp", `$ion_1_0 {Item:{at:2007-02-23T12:14Z}}`},
+		{"string in number set", `$ion_1_0 {Item:{n:$dynamodb_NS::["1"]}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := client.LoadDataFromReader(strings.NewReader(tt.input)); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
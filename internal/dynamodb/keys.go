@@ -0,0 +1,84 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// KeySchema names the key attributes of a table. SortKey is empty for a
+// table with only a partition key.
+type KeySchema struct {
+	PartitionKey string
+	SortKey      string
+}
+
+// DescribeKeySchema reads a table's key schema with DescribeTable. The
+// schema is remembered, so it is only described once per table.
+func (c *Client) DescribeKeySchema(ctx context.Context, table string) (KeySchema, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if schema, ok := c.keySchemas[table]; ok {
+		return schema, nil
+	}
+	if c.api == nil {
+		return KeySchema{}, errNotConnected
+	}
+
+	output, err := c.api.DescribeTable(ctx, &awsdynamodb.DescribeTableInput{TableName: aws.String(table)})
+	if err != nil {
+		return KeySchema{}, fmt.Errorf("failed to describe table %s: %w", table, err)
+	}
+
+	var schema KeySchema
+	for _, element := range output.Table.KeySchema {
+		switch element.KeyType {
+		case types.KeyTypeHash:
+			schema.PartitionKey = aws.ToString(element.AttributeName)
+		case types.KeyTypeRange:
+			schema.SortKey = aws.ToString(element.AttributeName)
+		}
+	}
+	if schema.PartitionKey == "" {
+		return KeySchema{}, fmt.Errorf("table %s has no partition key", table)
+	}
+
+	if c.keySchemas == nil {
+		c.keySchemas = make(map[string]KeySchema)
+	}
+	c.keySchemas[table] = schema
+	return schema, nil
+}
+
+// Key returns the key attributes of item, for use in a GetItem, UpdateItem
+// or DeleteItem request.
+func (k KeySchema) Key(item map[string]types.AttributeValue) (map[string]types.AttributeValue, error) {
+	key := make(map[string]types.AttributeValue, 2)
+	for _, name := range []string{k.PartitionKey, k.SortKey} {
+		if name == "" {
+			continue
+		}
+		value, ok := item[name]
+		if !ok {
+			return nil, fmt.Errorf("item has no %s key attribute", name)
+		}
+		key[name] = value
+	}
+	return key, nil
+}
+
+// keyString identifies an item by its key in DynamoDB JSON, such as
+// {"id":{"S":"a"}}, for log messages and for telling keys apart.
+func keyString(key map[string]types.AttributeValue) string {
+	encoded, err := encodeItem(key)
+	if err != nil {
+		return fmt.Sprint(key)
+	}
+	data, _ := json.Marshal(encoded)
+	return string(data)
+}
@@ -0,0 +1,322 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/config"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+)
+
+// LiveOptions configures the connection to DynamoDB. EndpointURL points the
+// client at DynamoDB Local or another compatible endpoint.
+type LiveOptions struct {
+	Region      string
+	Profile     string
+	EndpointURL string
+}
+
+// QueryOptions selects what a live Scan or Query reads. KeyCondition is
+// required for Query and ignored by Scan.
+type QueryOptions struct {
+	Table                     string
+	Index                     string
+	KeyCondition              string
+	Filter                    string
+	Projection                string
+	ExpressionAttributeNames  map[string]string
+	ExpressionAttributeValues map[string]types.AttributeValue
+	PageSize                  int32
+	ConsistentRead            bool
+
+	// MaxRCU caps the read capacity units consumed per second; zero leaves
+	// the rate up to the table.
+	MaxRCU float64
+}
+
+// Connect creates the SDK client used by ScanTable and QueryTable from the
+// default AWS configuration chain.
+func (c *Client) Connect(ctx context.Context, options LiveOptions) error {
+	var loadOptions []func(*config.LoadOptions) error
+	if options.Region != "" {
+		loadOptions = append(loadOptions, config.WithRegion(options.Region))
+	}
+	if options.Profile != "" {
+		loadOptions = append(loadOptions, config.WithSharedConfigProfile(options.Profile))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, loadOptions...)
+	if err != nil {
+		return fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+
+	c.api = awsdynamodb.NewFromConfig(cfg, func(o *awsdynamodb.Options) {
+		if options.EndpointURL != "" {
+			o.BaseEndpoint = aws.String(options.EndpointURL)
+		}
+		o.Retryer = newRetryer()
+	})
+
+	c.logger.Debug("Connected to DynamoDB", "region", cfg.Region, "endpoint", options.EndpointURL)
+
+	return nil
+}
+
+// newRetryer returns the SDK's standard retryer without its retries of
+// ProvisionedThroughputExceededException. The capacity limiter and
+// BatchWriter back off from that error themselves, and the limiter lowers
+// its rate when it sees it, which it never would if the SDK retried first.
+func newRetryer() aws.Retryer {
+	return retry.NewStandard(func(o *retry.StandardOptions) {
+		notThrottled := retry.IsErrorRetryableFunc(func(err error) aws.Ternary {
+			var apiErr smithy.APIError
+			if errors.As(err, &apiErr) && apiErr.ErrorCode() == "ProvisionedThroughputExceededException" {
+				return aws.FalseTernary
+			}
+			return aws.UnknownTernary
+		})
+		o.Retryables = append([]retry.IsErrorRetryable{notThrottled}, o.Retryables...)
+	})
+}
+
+// ScanTable reads a table or index page by page with Scan. Items are
+// fetched as the scanner asks for them, so output can start after the first
+// page.
+func (c *Client) ScanTable(ctx context.Context, options QueryOptions) *ItemScanner {
+	limiter := c.newCapacityLimiter(options.MaxRCU)
+
+	return c.newLiveScanner(ctx, options.Table, limiter, func(ctx context.Context, startKey map[string]types.AttributeValue) (livePage, error) {
+		return c.scanPage(ctx, limiter, options.scanInput(startKey))
+	})
+}
+
+// QueryTable reads the items matching options.KeyCondition page by page.
+func (c *Client) QueryTable(ctx context.Context, options QueryOptions) *ItemScanner {
+	limiter := c.newCapacityLimiter(options.MaxRCU)
+
+	return c.newLiveScanner(ctx, options.Table, limiter, func(ctx context.Context, startKey map[string]types.AttributeValue) (livePage, error) {
+		if options.KeyCondition == "" {
+			return livePage{}, fmt.Errorf("query of %s requires a key condition expression", options.Table)
+		}
+
+		input := &awsdynamodb.QueryInput{
+			TableName:                 aws.String(options.Table),
+			KeyConditionExpression:    aws.String(options.KeyCondition),
+			ExclusiveStartKey:         startKey,
+			ExpressionAttributeNames:  options.ExpressionAttributeNames,
+			ExpressionAttributeValues: options.ExpressionAttributeValues,
+			IndexName:                 optionalString(options.Index),
+			FilterExpression:          optionalString(options.Filter),
+			ProjectionExpression:      optionalString(options.Projection),
+			Limit:                     optionalInt32(options.PageSize),
+			ConsistentRead:            aws.Bool(options.ConsistentRead),
+			ReturnConsumedCapacity:    types.ReturnConsumedCapacityTotal,
+		}
+
+		var output *awsdynamodb.QueryOutput
+		err := limiter.do(ctx, func() (*types.ConsumedCapacity, error) {
+			var err error
+			output, err = c.api.Query(ctx, input)
+			if err != nil {
+				return nil, err
+			}
+			return output.ConsumedCapacity, nil
+		})
+		if err != nil {
+			return livePage{}, fmt.Errorf("query of %s failed: %w", options.Table, err)
+		}
+
+		return livePage{
+			items:            output.Items,
+			lastEvaluatedKey: output.LastEvaluatedKey,
+			count:            int(output.Count),
+			scannedCount:     int(output.ScannedCount),
+		}, nil
+	})
+}
+
+func (options QueryOptions) scanInput(startKey map[string]types.AttributeValue) *awsdynamodb.ScanInput {
+	return &awsdynamodb.ScanInput{
+		TableName:                 aws.String(options.Table),
+		ExclusiveStartKey:         startKey,
+		ExpressionAttributeNames:  options.ExpressionAttributeNames,
+		ExpressionAttributeValues: options.ExpressionAttributeValues,
+		IndexName:                 optionalString(options.Index),
+		FilterExpression:          optionalString(options.Filter),
+		ProjectionExpression:      optionalString(options.Projection),
+		Limit:                     optionalInt32(options.PageSize),
+		ConsistentRead:            aws.Bool(options.ConsistentRead),
+		ReturnConsumedCapacity:    types.ReturnConsumedCapacityTotal,
+	}
+}
+
+func (c *Client) scanPage(ctx context.Context, limiter *capacityLimiter, input *awsdynamodb.ScanInput) (livePage, error) {
+	var output *awsdynamodb.ScanOutput
+	err := limiter.do(ctx, func() (*types.ConsumedCapacity, error) {
+		var err error
+		output, err = c.api.Scan(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		return output.ConsumedCapacity, nil
+	})
+	if err != nil {
+		return livePage{}, fmt.Errorf("scan of %s failed: %w", aws.ToString(input.TableName), err)
+	}
+
+	return livePage{
+		items:            output.Items,
+		lastEvaluatedKey: output.LastEvaluatedKey,
+		count:            int(output.Count),
+		scannedCount:     int(output.ScannedCount),
+	}, nil
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return aws.String(s)
+}
+
+func optionalInt32(n int32) *int32 {
+	if n <= 0 {
+		return nil
+	}
+	return aws.Int32(n)
+}
+
+// ParseExpressionValues decodes ExpressionAttributeValues written in
+// DynamoDB JSON, as accepted by the aws CLI's --expression-attribute-values.
+func (c *Client) ParseExpressionValues(data string) (map[string]types.AttributeValue, error) {
+	if data == "" {
+		return nil, nil
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(data), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse expression attribute values: %w", err)
+	}
+
+	values, err := c.parseItem(raw, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse expression attribute values: %w", err)
+	}
+
+	return values, nil
+}
+
+// ParseParameters decodes the values for the ? markers of a PartiQL
+// statement, written as a JSON array of DynamoDB JSON values as accepted by
+// the aws CLI's execute-statement --parameters.
+func (c *Client) ParseParameters(data string) ([]types.AttributeValue, error) {
+	if data == "" {
+		return nil, nil
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal([]byte(data), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse parameters: %w", err)
+	}
+
+	parameters := make([]types.AttributeValue, len(raw))
+	for i, value := range raw {
+		parsed, err := c.parseAttributeValue(value, fmt.Sprintf("[%d]", i))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse parameters: %w", err)
+		}
+		parameters[i] = parsed
+	}
+
+	return parameters, nil
+}
+
+// ParseExpressionNames decodes ExpressionAttributeNames written as a JSON
+// object, as accepted by the aws CLI's --expression-attribute-names.
+func ParseExpressionNames(data string) (map[string]string, error) {
+	if data == "" {
+		return nil, nil
+	}
+
+	var names map[string]string
+	if err := json.Unmarshal([]byte(data), &names); err != nil {
+		return nil, fmt.Errorf("failed to parse expression attribute names: %w", err)
+	}
+
+	return names, nil
+}
+
+// errNotConnected is returned when a live read is attempted before Connect.
+var errNotConnected = errors.New("not connected to DynamoDB")
+
+type livePage struct {
+	items            []map[string]types.AttributeValue
+	lastEvaluatedKey map[string]types.AttributeValue
+	count            int
+	scannedCount     int
+}
+
+type pageFetcher func(ctx context.Context, startKey map[string]types.AttributeValue) (livePage, error)
+
+// liveSource fetches one page at a time and hands out its items.
+type liveSource struct {
+	ctx      context.Context
+	table    string
+	limiter  *capacityLimiter
+	fetch    pageFetcher
+	items    []map[string]types.AttributeValue
+	startKey map[string]types.AttributeValue
+	started  bool
+	response DynamoDBResponse
+	err      error
+}
+
+func (c *Client) newLiveScanner(ctx context.Context, table string, limiter *capacityLimiter, fetch pageFetcher) *ItemScanner {
+	source := &liveSource{ctx: ctx, table: table, limiter: limiter, fetch: fetch}
+	if c.api == nil {
+		source.err = errNotConnected
+	}
+
+	return &ItemScanner{
+		client: c,
+		source: source,
+		pages:  &pageChain{},
+	}
+}
+
+func (s *liveSource) next() (Record, error) {
+	if s.err != nil {
+		return Record{}, s.err
+	}
+
+	for len(s.items) == 0 {
+		// A page without a LastEvaluatedKey is the last one
+		if s.started && s.startKey == nil {
+			return Record{}, io.EOF
+		}
+
+		page, err := s.fetch(s.ctx, s.startKey)
+		if err != nil {
+			return Record{}, err
+		}
+
+		s.started = true
+		s.items = page.items
+		s.startKey = page.lastEvaluatedKey
+		s.response.Count += page.count
+		s.response.ScannedCount += page.scannedCount
+		s.response.ConsumedCapacity = s.limiter.consumedCapacity(s.table)
+	}
+
+	item := s.items[0]
+	s.items = s.items[1:]
+
+	return Record{Item: item}, nil
+}
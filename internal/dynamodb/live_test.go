@@ -0,0 +1,270 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gkwa/bouncingbeaver/internal/memtable"
+)
+
+// newFakeService answers requests for target with respond, which returns
+// the HTTP status and response body for each decoded request.
+func newFakeService(t *testing.T, target string, respond func(request map[string]interface{}) (int, string)) *httptest.Server {
+	t.Helper()
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_CONFIG_FILE", "/dev/null")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/dev/null")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Amz-Target"); got != "DynamoDB_20120810."+target {
+			t.Errorf("Expected target %s, got %s", target, got)
+			http.Error(w, "unexpected target", http.StatusBadRequest)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		var request map[string]interface{}
+		json.Unmarshal(body, &request)
+
+		status, response := respond(request)
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		w.WriteHeader(status)
+		io.WriteString(w, response)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// newFakeEndpoint serves the given pages in order for Scan or Query and
+// records the request bodies it received.
+func newFakeEndpoint(t *testing.T, target string, pages []string) (*httptest.Server, *[]map[string]interface{}) {
+	t.Helper()
+
+	var requests []map[string]interface{}
+
+	server := newFakeService(t, target, func(request map[string]interface{}) (int, string) {
+		requests = append(requests, request)
+		if len(requests) > len(pages) {
+			t.Errorf("Unexpected request %d", len(requests))
+			return http.StatusBadRequest, `{"__type": "com.amazon.coral.validate#ValidationException", "message": "no more pages"}`
+		}
+		return http.StatusOK, pages[len(requests)-1]
+	})
+
+	return server, &requests
+}
+
+func TestScanTable_FollowsLastEvaluatedKey(t *testing.T) {
+	server, requests := newFakeEndpoint(t, "Scan", []string{
+		`{"Items": [{"id": {"S": "a"}}, {"id": {"S": "b"}}], "Count": 2, "ScannedCount": 2, "LastEvaluatedKey": {"id": {"S": "b"}},
+		  "ConsumedCapacity": {"TableName": "products", "CapacityUnits": 1.0}}`,
+		`{"Items": [{"id": {"S": "c"}}], "Count": 1, "ScannedCount": 1, "ConsumedCapacity": {"TableName": "products", "CapacityUnits": 0.5}}`,
+	})
+
+	client := NewClient()
+	ctx := context.Background()
+	if err := client.Connect(ctx, LiveOptions{EndpointURL: server.URL}); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	scanner := client.ScanTable(ctx, QueryOptions{Table: "products", PageSize: 2})
+
+	var ids []string
+	for scanner.Scan() {
+		ids = append(ids, scanner.Item()["id"].(*types.AttributeValueMemberS).Value)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	if len(ids) != 3 || ids[0] != "a" || ids[2] != "c" {
+		t.Errorf("Expected items a, b, c, got %v", ids)
+	}
+
+	response := scanner.Response()
+	if response.Count != 3 || response.ScannedCount != 3 {
+		t.Errorf("Expected totals of 3 items, got %d and %d", response.Count, response.ScannedCount)
+	}
+	capacity, ok := response.ConsumedCapacity.(*types.ConsumedCapacity)
+	if !ok || *capacity.CapacityUnits != 1.5 || *capacity.TableName != "products" {
+		t.Errorf("Expected 1.5 units consumed on products, got %#v", response.ConsumedCapacity)
+	}
+
+	if len(*requests) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(*requests))
+	}
+
+	first := (*requests)[0]
+	if first["TableName"] != "products" || first["Limit"] != float64(2) || first["ReturnConsumedCapacity"] != "TOTAL" {
+		t.Errorf("Unexpected first request: %v", first)
+	}
+	if _, ok := first["ExclusiveStartKey"]; ok {
+		t.Errorf("Expected no ExclusiveStartKey on the first request")
+	}
+
+	startKey, ok := (*requests)[1]["ExclusiveStartKey"].(map[string]interface{})
+	if !ok || startKey["id"].(map[string]interface{})["S"] != "b" {
+		t.Errorf("Expected second request to start after b, got %v", (*requests)[1]["ExclusiveStartKey"])
+	}
+}
+
+func TestQueryTable_SendsExpressions(t *testing.T) {
+	server, requests := newFakeEndpoint(t, "Query", []string{
+		`{"Items": [{"id": {"S": "a"}}], "Count": 1, "ScannedCount": 1}`,
+	})
+
+	client := NewClient()
+	ctx := context.Background()
+	if err := client.Connect(ctx, LiveOptions{EndpointURL: server.URL}); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	names, err := ParseExpressionNames(`{"#id": "id"}`)
+	if err != nil {
+		t.Fatalf("ParseExpressionNames failed: %v", err)
+	}
+	values, err := client.ParseExpressionValues(`{":id": {"S": "a"}}`)
+	if err != nil {
+		t.Fatalf("ParseExpressionValues failed: %v", err)
+	}
+
+	scanner := client.QueryTable(ctx, QueryOptions{
+		Table:                     "products",
+		Index:                     "by-id",
+		KeyCondition:              "#id = :id",
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	})
+
+	count := 0
+	for scanner.Scan() {
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 item, got %d", count)
+	}
+
+	request := (*requests)[0]
+	if request["KeyConditionExpression"] != "#id = :id" || request["IndexName"] != "by-id" {
+		t.Errorf("Unexpected request: %v", request)
+	}
+	if _, ok := request["FilterExpression"]; ok {
+		t.Errorf("Expected no FilterExpression when none was given")
+	}
+}
+
+func TestConnect_LeavesThrottlingToTheLimiter(t *testing.T) {
+	requests := 0
+	server := newFakeService(t, "Scan", func(map[string]interface{}) (int, string) {
+		requests++
+		return http.StatusBadRequest, `{"__type": "com.amazonaws.dynamodb.v20120810#ProvisionedThroughputExceededException", "message": "slow down"}`
+	})
+
+	client := NewClient()
+	ctx := context.Background()
+	if err := client.Connect(ctx, LiveOptions{EndpointURL: server.URL}); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	_, err := client.api.Scan(ctx, QueryOptions{Table: "products"}.scanInput(nil))
+	var exceeded *types.ProvisionedThroughputExceededException
+	if !errors.As(err, &exceeded) {
+		t.Fatalf("Expected ProvisionedThroughputExceededException, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected the SDK not to retry throttling, got %d requests", requests)
+	}
+}
+
+func TestScanTable_NotConnected(t *testing.T) {
+	scanner := NewClient().ScanTable(context.Background(), QueryOptions{Table: "products"})
+	if scanner.Scan() {
+		t.Fatal("Expected no items without a connection")
+	}
+	if scanner.Err() == nil {
+		t.Error("Expected an error without a connection")
+	}
+}
+
+func TestParseExpressionValues_Invalid(t *testing.T) {
+	client := NewClient()
+
+	if _, err := client.ParseExpressionValues(`{":n": {"N": "abc"}}`); err == nil {
+		t.Error("Expected an error for an invalid number")
+	}
+
+	values, err := client.ParseExpressionValues("")
+	if err != nil || values != nil {
+		t.Errorf("Expected nil values for empty input, got %v, %v", values, err)
+	}
+}
+
+func TestQueryTable_Memtable(t *testing.T) {
+	fake := memtable.New()
+	fake.CreateTable("orders", "customer", "placed")
+	for i := 1; i <= 5; i++ {
+		fake.Put("orders", map[string]types.AttributeValue{
+			"customer": &types.AttributeValueMemberS{Value: "alice"},
+			"placed":   &types.AttributeValueMemberN{Value: strconv.Itoa(i)},
+		})
+	}
+
+	// Throttle the first two requests to check that the reader backs off
+	// and retries rather than failing
+	throttled := 0
+	fake.Fail = func(operation string, input interface{}) error {
+		if throttled < 2 {
+			throttled++
+			return &types.ProvisionedThroughputExceededException{Message: aws.String("slow down")}
+		}
+		return nil
+	}
+
+	client := NewClientWithAPI(fake)
+	values, err := client.ParseExpressionValues(`{":c": {"S": "alice"}, ":p": {"N": "2"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scanner := client.QueryTable(context.Background(), QueryOptions{
+		Table:                     "orders",
+		KeyCondition:              "customer = :c AND placed > :p",
+		ExpressionAttributeValues: values,
+		PageSize:                  2,
+		MaxRCU:                    100,
+	})
+
+	var placed []string
+	for scanner.Scan() {
+		placed = append(placed, scanner.Item()["placed"].(*types.AttributeValueMemberN).Value)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	if strings.Join(placed, " ") != "3 4 5" {
+		t.Errorf("Expected items 3 4 5, got %v", placed)
+	}
+	if calls := fake.Calls("Query"); calls != 4 {
+		t.Errorf("Expected 2 throttled and 2 successful calls, got %d", calls)
+	}
+	if response := scanner.Response(); response.Count != 3 {
+		t.Errorf("Expected a count of 3, got %d", response.Count)
+	}
+}
@@ -1,10 +1,16 @@
 package dynamodb
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"regexp"
+	"sort"
 
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
@@ -13,77 +19,353 @@ type DynamoDBResponse struct {
 	Items            []map[string]json.RawMessage `json:"Items"`
 	Count            int                          `json:"Count"`
 	ScannedCount     int                          `json:"ScannedCount"`
-	ConsumedCapacity interface{}                  `json:"ConsumedCapacity"`
+	LastEvaluatedKey map[string]json.RawMessage   `json:"LastEvaluatedKey"`
+	NextToken        string                       `json:"NextToken"`
+	UnprocessedKeys  map[string]struct {
+		Keys []map[string]json.RawMessage `json:"Keys"`
+	} `json:"UnprocessedKeys"`
+	ConsumedCapacity interface{} `json:"ConsumedCapacity"`
 }
 
 func (c *Client) LoadData(input string) ([]map[string]types.AttributeValue, error) {
-	var reader io.Reader
+	reader, err := c.Open(input)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
 
+	return c.LoadDataFromReader(reader)
+}
+
+// Open returns a reader for input, which is either a file path or "-" for
+// stdin. Gzip compressed input, such as the .json.gz data files of a DynamoDB
+// export, is detected from its header and decompressed transparently.
+func (c *Client) Open(input string) (io.ReadCloser, error) {
 	if input == "-" {
-		reader = os.Stdin
-	} else {
-		file, err := os.Open(input)
+		return decompress(io.NopCloser(os.Stdin))
+	}
+
+	file, err := os.Open(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", input, err)
+	}
+
+	reader, err := decompress(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to open file %s: %w", input, err)
+	}
+
+	return reader, nil
+}
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+func decompress(source io.ReadCloser) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(source)
+
+	header, err := buffered.Peek(len(gzipMagic))
+	if err != nil || !bytes.Equal(header, gzipMagic) {
+		// Short inputs are left for the JSON decoder to report
+		return readCloser{Reader: buffered, closers: []io.Closer{source}}, nil
+	}
+
+	gz, err := gzip.NewReader(buffered)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gzip header: %w", err)
+	}
+
+	return readCloser{Reader: gz, closers: []io.Closer{gz, source}}, nil
+}
+
+// readCloser closes every layer of a stacked reader in order.
+type readCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r readCloser) Close() error {
+	var first error
+	for _, closer := range r.closers {
+		if err := closer.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// LoadDataFromReader collects every item from reader. Use NewItemScanner
+// instead when the input may be too large to hold in memory.
+func (c *Client) LoadDataFromReader(reader io.Reader) ([]map[string]types.AttributeValue, error) {
+	var items []map[string]types.AttributeValue
+
+	scanner := c.NewItemScanner(reader)
+	for scanner.Scan() {
+		items = append(items, scanner.Item())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if items == nil {
+		items = make([]map[string]types.AttributeValue, 0)
+	}
+
+	return items, nil
+}
+
+// parseItem decodes every attribute of item. Attribute paths in errors are
+// prefixed with prefix, which is empty for top-level items.
+func (c *Client) parseItem(item map[string]json.RawMessage, prefix string) (map[string]types.AttributeValue, error) {
+	// Walk attributes in a stable order so the first reported error does not
+	// depend on map iteration
+	keys := make([]string, 0, len(item))
+	for key := range item {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	attributeMap := make(map[string]types.AttributeValue, len(item))
+	for _, key := range keys {
+		attributeValue, err := c.parseAttributeValue(item[key], prefix+key)
 		if err != nil {
-			return nil, fmt.Errorf("failed to open file %s: %w", input, err)
+			return nil, err
 		}
-		defer file.Close()
-		reader = file
+		attributeMap[key] = attributeValue
 	}
 
-	return c.LoadDataFromReader(reader)
+	return attributeMap, nil
 }
 
-func (c *Client) LoadDataFromReader(reader io.Reader) ([]map[string]types.AttributeValue, error) {
-	data, err := io.ReadAll(reader)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read data: %w", err)
+var numberPattern = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+
+// parseAttributeValue decodes a single DynamoDB JSON descriptor such as
+// {"S": "value"}. Every problem is returned as an *AttributeError whose path
+// starts at path.
+func (c *Client) parseAttributeValue(raw json.RawMessage, path string) (types.AttributeValue, error) {
+	var temp map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &temp); err != nil || temp == nil {
+		return nil, newAttributeError(path, "expected an attribute value object, got %s", describeJSON(raw))
 	}
 
-	var response DynamoDBResponse
-	if err := json.Unmarshal(data, &response); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	if len(temp) != 1 {
+		keys := make([]string, 0, len(temp))
+		for key := range temp {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return nil, newAttributeError(path, "expected exactly one type key, got %v", keys)
 	}
 
-	items := make([]map[string]types.AttributeValue, 0, len(response.Items))
+	for typeKey, value := range temp {
+		valuePath := path + "." + typeKey
 
-	for _, item := range response.Items {
-		attributeMap := make(map[string]types.AttributeValue)
+		switch typeKey {
+		case "S":
+			s, err := parseString(value, valuePath)
+			if err != nil {
+				return nil, err
+			}
+			return &types.AttributeValueMemberS{Value: s}, nil
 
-		for key, rawValue := range item {
-			attributeValue, err := c.parseAttributeValue(rawValue)
+		case "N":
+			n, err := parseNumber(value, valuePath)
 			if err != nil {
-				return nil, fmt.Errorf("failed to parse attribute %s: %w", key, err)
+				return nil, err
 			}
-			attributeMap[key] = attributeValue
-		}
+			return &types.AttributeValueMemberN{Value: n}, nil
 
-		items = append(items, attributeMap)
+		case "B":
+			b, err := parseBinary(value, valuePath)
+			if err != nil {
+				return nil, err
+			}
+			return &types.AttributeValueMemberB{Value: b}, nil
+
+		case "BOOL":
+			var b bool
+			if err := json.Unmarshal(value, &b); err != nil || isJSONNull(value) {
+				return nil, newAttributeError(valuePath, "expected a boolean, got %s", describeJSON(value))
+			}
+			return &types.AttributeValueMemberBOOL{Value: b}, nil
+
+		case "NULL":
+			var b bool
+			if err := json.Unmarshal(value, &b); err != nil || !b {
+				return nil, newAttributeError(valuePath, "expected true, got %s", describeJSON(value))
+			}
+			return &types.AttributeValueMemberNULL{Value: true}, nil
+
+		case "SS":
+			elements, err := parseSet(value, valuePath)
+			if err != nil {
+				return nil, err
+			}
+			ss := make([]string, 0, len(elements))
+			for i, element := range elements {
+				s, err := parseString(element, fmt.Sprintf("%s[%d]", valuePath, i))
+				if err != nil {
+					return nil, err
+				}
+				ss = append(ss, s)
+			}
+			if err := checkDuplicates(ss, valuePath); err != nil {
+				return nil, err
+			}
+			return &types.AttributeValueMemberSS{Value: ss}, nil
+
+		case "NS":
+			elements, err := parseSet(value, valuePath)
+			if err != nil {
+				return nil, err
+			}
+			ns := make([]string, 0, len(elements))
+			for i, element := range elements {
+				n, err := parseNumber(element, fmt.Sprintf("%s[%d]", valuePath, i))
+				if err != nil {
+					return nil, err
+				}
+				ns = append(ns, n)
+			}
+			if err := checkDuplicates(ns, valuePath); err != nil {
+				return nil, err
+			}
+			return &types.AttributeValueMemberNS{Value: ns}, nil
+
+		case "BS":
+			elements, err := parseSet(value, valuePath)
+			if err != nil {
+				return nil, err
+			}
+			bs := make([][]byte, 0, len(elements))
+			seen := make([]string, 0, len(elements))
+			for i, element := range elements {
+				b, err := parseBinary(element, fmt.Sprintf("%s[%d]", valuePath, i))
+				if err != nil {
+					return nil, err
+				}
+				bs = append(bs, b)
+				seen = append(seen, string(b))
+			}
+			if err := checkDuplicates(seen, valuePath); err != nil {
+				return nil, err
+			}
+			return &types.AttributeValueMemberBS{Value: bs}, nil
+
+		case "L":
+			var elements []json.RawMessage
+			if err := json.Unmarshal(value, &elements); err != nil || elements == nil {
+				return nil, newAttributeError(valuePath, "expected an array, got %s", describeJSON(value))
+			}
+
+			list := make([]types.AttributeValue, 0, len(elements))
+			for i, element := range elements {
+				attributeValue, err := c.parseAttributeValue(element, fmt.Sprintf("%s[%d]", valuePath, i))
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, attributeValue)
+			}
+			return &types.AttributeValueMemberL{Value: list}, nil
+
+		case "M":
+			var members map[string]json.RawMessage
+			if err := json.Unmarshal(value, &members); err != nil || members == nil {
+				return nil, newAttributeError(valuePath, "expected an object, got %s", describeJSON(value))
+			}
+
+			keys := make([]string, 0, len(members))
+			for key := range members {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			m := make(map[string]types.AttributeValue, len(members))
+			for _, key := range keys {
+				attributeValue, err := c.parseAttributeValue(members[key], valuePath+"."+key)
+				if err != nil {
+					return nil, err
+				}
+				m[key] = attributeValue
+			}
+			return &types.AttributeValueMemberM{Value: m}, nil
+
+		default:
+			return nil, newAttributeError(valuePath, "unsupported attribute value type %q", typeKey)
+		}
 	}
 
-	return items, nil
+	return nil, newAttributeError(path, "empty attribute value")
 }
 
-func (c *Client) parseAttributeValue(raw json.RawMessage) (types.AttributeValue, error) {
-	var temp map[string]interface{}
-	if err := json.Unmarshal(raw, &temp); err != nil {
-		return nil, err
+func parseString(raw json.RawMessage, path string) (string, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil || isJSONNull(raw) {
+		return "", newAttributeError(path, "expected a string, got %s", describeJSON(raw))
 	}
+	return s, nil
+}
 
-	if s, exists := temp["S"]; exists {
-		return &types.AttributeValueMemberS{Value: s.(string)}, nil
+func parseNumber(raw json.RawMessage, path string) (string, error) {
+	var n string
+	if err := json.Unmarshal(raw, &n); err != nil || isJSONNull(raw) {
+		return "", newAttributeError(path, "expected a number encoded as a string, got %s", describeJSON(raw))
 	}
+	if !numberPattern.MatchString(n) {
+		return "", newAttributeError(path, "invalid number %q", n)
+	}
+	return n, nil
+}
 
-	if n, exists := temp["N"]; exists {
-		return &types.AttributeValueMemberN{Value: n.(string)}, nil
+func parseBinary(raw json.RawMessage, path string) ([]byte, error) {
+	s, err := parseString(raw, path)
+	if err != nil {
+		return nil, newAttributeError(path, "expected base64 encoded binary, got %s", describeJSON(raw))
 	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, newAttributeError(path, "invalid base64: %v", err)
+	}
+	return b, nil
+}
 
-	if b, exists := temp["BOOL"]; exists {
-		return &types.AttributeValueMemberBOOL{Value: b.(bool)}, nil
+func parseSet(raw json.RawMessage, path string) ([]json.RawMessage, error) {
+	var elements []json.RawMessage
+	if err := json.Unmarshal(raw, &elements); err != nil || elements == nil {
+		return nil, newAttributeError(path, "expected an array, got %s", describeJSON(raw))
 	}
+	if len(elements) == 0 {
+		return nil, newAttributeError(path, "sets must not be empty")
+	}
+	return elements, nil
+}
 
-	if _, exists := temp["NULL"]; exists {
-		return &types.AttributeValueMemberNULL{Value: true}, nil
+func checkDuplicates(values []string, path string) error {
+	seen := make(map[string]int, len(values))
+	for i, value := range values {
+		if first, exists := seen[value]; exists {
+			return newAttributeError(fmt.Sprintf("%s[%d]", path, i), "duplicate set element (first seen at index %d)", first)
+		}
+		seen[value] = i
 	}
+	return nil
+}
+
+func isJSONNull(raw json.RawMessage) bool {
+	return string(bytes.TrimSpace(raw)) == "null"
+}
 
-	return nil, fmt.Errorf("unsupported attribute value type: %v", temp)
+// describeJSON renders a raw value for error messages, truncating long
+// payloads such as compressed HTML.
+func describeJSON(raw json.RawMessage) string {
+	const limit = 40
+
+	s := string(bytes.TrimSpace(raw))
+	if s == "" {
+		return "nothing"
+	}
+	if len(s) > limit {
+		return s[:limit] + "..."
+	}
+	return s
 }
@@ -1,6 +1,11 @@
 package dynamodb
 
 import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -38,6 +43,76 @@ func TestLoadData_FromString(t *testing.T) {
 	}
 }
 
+func TestLoadData_AllAttributeTypes(t *testing.T) {
+	client := NewClient()
+
+	jsonInput := `{
+		"Items": [
+			{
+				"id": {"S": "test-id"},
+				"count": {"N": "42"},
+				"blob": {"B": "aGVsbG8="},
+				"active": {"BOOL": true},
+				"badge": {"NULL": true},
+				"tags": {"SS": ["a", "b"]},
+				"sizes": {"NS": ["1", "2.5"]},
+				"blobs": {"BS": ["aGVsbG8=", "d29ybGQ="]},
+				"history": {"L": [{"S": "first"}, {"N": "2"}, {"L": [{"BOOL": false}]}]},
+				"details": {"M": {"color": {"S": "red"}, "dims": {"M": {"w": {"N": "10"}}}}}
+			}
+		]
+	}`
+
+	items, err := client.LoadDataFromReader(strings.NewReader(jsonInput))
+	if err != nil {
+		t.Fatalf("Failed to load data: %v", err)
+	}
+
+	item := items[0]
+
+	if v := item["count"].(*types.AttributeValueMemberN).Value; v != "42" {
+		t.Errorf("Expected count '42', got %q", v)
+	}
+
+	if v := item["blob"].(*types.AttributeValueMemberB).Value; string(v) != "hello" {
+		t.Errorf("Expected blob 'hello', got %q", v)
+	}
+
+	if v := item["active"].(*types.AttributeValueMemberBOOL).Value; !v {
+		t.Error("Expected active to be true")
+	}
+
+	if v := item["tags"].(*types.AttributeValueMemberSS).Value; len(v) != 2 || v[1] != "b" {
+		t.Errorf("Expected tags [a b], got %v", v)
+	}
+
+	if v := item["sizes"].(*types.AttributeValueMemberNS).Value; len(v) != 2 || v[1] != "2.5" {
+		t.Errorf("Expected sizes [1 2.5], got %v", v)
+	}
+
+	if v := item["blobs"].(*types.AttributeValueMemberBS).Value; len(v) != 2 || string(v[1]) != "world" {
+		t.Errorf("Expected blobs [hello world], got %q", v)
+	}
+
+	history := item["history"].(*types.AttributeValueMemberL).Value
+	if len(history) != 3 {
+		t.Fatalf("Expected 3 history elements, got %d", len(history))
+	}
+	nested := history[2].(*types.AttributeValueMemberL).Value
+	if nested[0].(*types.AttributeValueMemberBOOL).Value {
+		t.Error("Expected nested list element to be false")
+	}
+
+	details := item["details"].(*types.AttributeValueMemberM).Value
+	if v := details["color"].(*types.AttributeValueMemberS).Value; v != "red" {
+		t.Errorf("Expected color 'red', got %q", v)
+	}
+	dims := details["dims"].(*types.AttributeValueMemberM).Value
+	if v := dims["w"].(*types.AttributeValueMemberN).Value; v != "10" {
+		t.Errorf("Expected dims.w '10', got %q", v)
+	}
+}
+
 func TestLoadData_FromFile(t *testing.T) {
 	client := NewClient()
 
@@ -80,3 +155,107 @@ func TestUnmarshalProducts_Golden(t *testing.T) {
 		testutil.AssertGoldenMatch(t, actual, expected, "products_output.golden")
 	}
 }
+
+func TestLoadData_MalformedAttributes(t *testing.T) {
+	client := NewClient()
+
+	tests := []struct {
+		name string
+		item string
+		path string
+	}{
+		{"string holding a number", `{"rawHtml": {"S": 5}}`, "rawHtml.S"},
+		{"boolean holding a string", `{"active": {"BOOL": "true"}}`, "active.BOOL"},
+		{"number holding a number", `{"ttl": {"N": 1750481534}}`, "ttl.N"},
+		{"number that is not numeric", `{"ttl": {"N": "soon"}}`, "ttl.N"},
+		{"null that is false", `{"badge": {"NULL": false}}`, "badge.NULL"},
+		{"descriptor that is not an object", `{"name": "plain"}`, "name"},
+		{"descriptor with two types", `{"name": {"S": "a", "N": "1"}}`, "name"},
+		{"unknown type", `{"name": {"X": "a"}}`, "name.X"},
+		{"invalid binary", `{"blob": {"B": "not base64!"}}`, "blob.B"},
+		{"empty set", `{"tags": {"SS": []}}`, "tags.SS"},
+		{"duplicate set element", `{"tags": {"SS": ["a", "a"]}}`, "tags.SS[1]"},
+		{"bad list element", `{"history": {"L": [{"S": "ok"}, {"N": true}]}}`, "history.L[1].N"},
+		{"bad map member", `{"details": {"M": {"color": {"S": null}}}}`, "details.M.color.S"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonInput := `{"Items": [{"id": {"S": "ok"}}, ` + tt.item + `]}`
+
+			_, err := client.LoadDataFromReader(strings.NewReader(jsonInput))
+			if err == nil {
+				t.Fatal("Expected an error for malformed input")
+			}
+
+			var attrErr *AttributeError
+			if !errors.As(err, &attrErr) {
+				t.Fatalf("Expected *AttributeError, got %T: %v", err, err)
+			}
+
+			if attrErr.Item != 1 {
+				t.Errorf("Expected item index 1, got %d", attrErr.Item)
+			}
+
+			if attrErr.Path != tt.path {
+				t.Errorf("Expected path %q, got %q (%v)", tt.path, attrErr.Path, err)
+			}
+		})
+	}
+}
+
+func TestLoadData_ExportDataFile(t *testing.T) {
+	client := NewClient()
+
+	lines := `{"Item":{"id":{"S":"a"},"ttl":{"N":"1750481534"}}}
+{"Item":{"id":{"S":"b"},"tags":{"SS":["x"]}}}
+{"Item":{"id":{"S":"c"}}}
+`
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(lines))
+	zw.Close()
+
+	path := filepath.Join(t.TempDir(), "0123456789-abcdefgh.json.gz")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("Failed to write export file: %v", err)
+	}
+
+	items, err := client.LoadData(path)
+	if err != nil {
+		t.Fatalf("Failed to load export file: %v", err)
+	}
+
+	if len(items) != 3 {
+		t.Fatalf("Expected 3 items, got %d", len(items))
+	}
+
+	if id := items[2]["id"].(*types.AttributeValueMemberS).Value; id != "c" {
+		t.Errorf("Expected id 'c', got %q", id)
+	}
+
+	products, err := client.UnmarshalProducts(items)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal products: %v", err)
+	}
+
+	if products[0].TTL != 1750481534 {
+		t.Errorf("Expected TTL 1750481534, got %d", products[0].TTL)
+	}
+}
+
+func TestLoadData_UncompressedExportLines(t *testing.T) {
+	client := NewClient()
+
+	lines := `{"Item":{"id":{"S":"a"}}}` + "\n" + `{"Item":{"id":{"S":"b"}}}`
+
+	items, err := client.LoadDataFromReader(strings.NewReader(lines))
+	if err != nil {
+		t.Fatalf("Failed to load export lines: %v", err)
+	}
+
+	if len(items) != 2 {
+		t.Errorf("Expected 2 items, got %d", len(items))
+	}
+}
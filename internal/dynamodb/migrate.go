@@ -0,0 +1,222 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gkwa/bouncingbeaver/internal/processing"
+)
+
+const rawHTMLAttribute = "rawHtml"
+
+// MigrateOptions configures an HTMLMigrator. With DryRun set, every item is
+// re-encoded and counted but nothing is written.
+type MigrateOptions struct {
+	Table    string
+	Encoding processing.HTMLEncoding
+	DryRun   bool
+}
+
+// MigrateSummary counts what an HTMLMigrator did. Conflicts are items whose
+// rawHtml was changed by another writer after they were read; they are left
+// as that writer stored them.
+type MigrateSummary struct {
+	Migrated    int
+	Unchanged   int
+	Conflicts   int
+	Failed      int
+	BytesBefore int64
+	BytesAfter  int64
+}
+
+// HTMLMigrator rewrites the rawHtml attribute of products in place, one
+// conditional UpdateItem per item.
+type HTMLMigrator struct {
+	client  *Client
+	ctx     context.Context
+	options MigrateOptions
+	limiter *capacityLimiter
+	summary MigrateSummary
+}
+
+func (c *Client) NewHTMLMigrator(ctx context.Context, options MigrateOptions) *HTMLMigrator {
+	return &HTMLMigrator{
+		client:  c,
+		ctx:     ctx,
+		options: options,
+		limiter: c.newCapacityLimiter(0),
+	}
+}
+
+// Migrate re-encodes one item's rawHtml in the target encoding. The update
+// only succeeds while rawHtml still holds the value that was read, so a
+// scraper that rewrote the item in the meantime is never overwritten.
+// Items that cannot be migrated are counted rather than returned as an
+// error; the error is reserved for a cancelled context or a missing
+// connection.
+func (m *HTMLMigrator) Migrate(item map[string]types.AttributeValue) error {
+	id := ""
+	if s, ok := item["id"].(*types.AttributeValueMemberS); ok {
+		id = s.Value
+	}
+
+	old, ok := item[rawHTMLAttribute]
+	if !ok {
+		m.summary.Unchanged++
+		return nil
+	}
+
+	data, current, err := storedHTML(old)
+	if err != nil {
+		m.client.logger.Error("Unexpected rawHtml attribute", "id", id, "error", err)
+		m.summary.Failed++
+		return nil
+	}
+	if len(data) == 0 || current == m.options.Encoding {
+		m.summary.Unchanged++
+		return nil
+	}
+
+	value, size, err := m.reencode(data, current)
+	if err != nil {
+		m.client.logger.Error("Failed to re-encode HTML", "id", id, "from", current, "to", m.options.Encoding, "error", err)
+		m.summary.Failed++
+		return nil
+	}
+
+	if m.options.DryRun {
+		m.count(len(data), size)
+		return nil
+	}
+
+	schema, err := m.client.DescribeKeySchema(m.ctx, m.options.Table)
+	if err != nil {
+		return err
+	}
+	key, err := schema.Key(item)
+	if err != nil {
+		m.client.logger.Error("Item cannot be updated", "id", id, "error", err)
+		m.summary.Failed++
+		return nil
+	}
+
+	err = m.limiter.do(m.ctx, func() (*types.ConsumedCapacity, error) {
+		output, err := m.client.api.UpdateItem(m.ctx, &awsdynamodb.UpdateItemInput{
+			TableName:                aws.String(m.options.Table),
+			Key:                      key,
+			UpdateExpression:         aws.String("SET #html = :new"),
+			ConditionExpression:      aws.String("#html = :old"),
+			ExpressionAttributeNames: map[string]string{"#html": rawHTMLAttribute},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":old": old,
+				":new": value,
+			},
+			ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return output.ConsumedCapacity, nil
+	})
+
+	var conflict *types.ConditionalCheckFailedException
+	switch {
+	case err == nil:
+		m.count(len(data), size)
+	case errors.As(err, &conflict):
+		m.client.logger.Warn("rawHtml changed since it was read, leaving it", "id", id)
+		m.summary.Conflicts++
+	case m.ctx.Err() != nil:
+		return m.ctx.Err()
+	default:
+		m.client.logger.Error("Failed to update item", "id", id, "error", err)
+		m.summary.Failed++
+	}
+
+	return nil
+}
+
+// Summary reports what has been migrated so far.
+func (m *HTMLMigrator) Summary() MigrateSummary {
+	return m.summary
+}
+
+// reencode decodes the stored HTML and encodes it again, checking that the
+// new value decodes to the same HTML before it is written. It returns the
+// attribute to store and its size in bytes.
+func (m *HTMLMigrator) reencode(data []byte, current processing.HTMLEncoding) (types.AttributeValue, int, error) {
+	extractor := m.client.htmlExtractor
+
+	html, err := extractor.DecodeHTML(data, current)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	encoded, err := extractor.EncodeHTML(html, m.options.Encoding)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	decoded, err := extractor.DecodeHTML(encoded, m.options.Encoding)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to decode re-encoded HTML: %w", err)
+	}
+	if decoded != html {
+		return nil, 0, fmt.Errorf("re-encoded HTML does not decode to the original")
+	}
+
+	if m.options.Encoding.Binary() {
+		return &types.AttributeValueMemberB{Value: encoded}, len(encoded), nil
+	}
+	return &types.AttributeValueMemberS{Value: string(encoded)}, len(encoded), nil
+}
+
+func (m *HTMLMigrator) count(before, after int) {
+	m.summary.Migrated++
+	m.summary.BytesBefore += int64(before)
+	m.summary.BytesAfter += int64(after)
+}
+
+// VerifyHTML checks that an item's rawHtml is stored in the given encoding
+// and decodes. Items without rawHtml, or with an empty one, pass. Base64 has
+// to be the standard, padded form EncodeHTML writes, whether or not the
+// client decodes strictly.
+func (c *Client) VerifyHTML(item map[string]types.AttributeValue, encoding processing.HTMLEncoding) error {
+	value, ok := item[rawHTMLAttribute]
+	if !ok {
+		return nil
+	}
+
+	data, current, err := storedHTML(value)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	if current != encoding {
+		return fmt.Errorf("rawHtml is stored as %s, not %s", current, encoding)
+	}
+
+	if _, err := c.verifier.DecodeHTML(data, current); err != nil {
+		return fmt.Errorf("rawHtml does not decode: %w", err)
+	}
+	return nil
+}
+
+// storedHTML returns the bytes of a rawHtml attribute and the encoding they
+// were written in.
+func storedHTML(value types.AttributeValue) ([]byte, processing.HTMLEncoding, error) {
+	switch v := value.(type) {
+	case *types.AttributeValueMemberS:
+		return []byte(v.Value), processing.DetectHTMLEncoding([]byte(v.Value), false), nil
+	case *types.AttributeValueMemberB:
+		return v.Value, processing.DetectHTMLEncoding(v.Value, true), nil
+	default:
+		return nil, "", fmt.Errorf("rawHtml is a %T, expected a string or binary", value)
+	}
+}
@@ -0,0 +1,211 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gkwa/bouncingbeaver/internal/memtable"
+	"github.com/gkwa/bouncingbeaver/internal/processing"
+)
+
+// newHTMLTable returns a fake products table whose items hold rawHtml in
+// the scraper's base64-zlib format.
+func newHTMLTable(t *testing.T, count int) *memtable.DB {
+	t.Helper()
+
+	extractor := processing.NewHTMLExtractor()
+	fake := memtable.New()
+	fake.CreateTable("products", "id", "")
+
+	for i := 0; i < count; i++ {
+		encoded, err := extractor.EncodeHTML(fmt.Sprintf("<div>product %d</div>", i), processing.EncodingBase64Zlib)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		item := product(fmt.Sprint(i))
+		item["rawHtml"] = &types.AttributeValueMemberS{Value: string(encoded)}
+		if err := fake.Put("products", item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return fake
+}
+
+func migrateAll(t *testing.T, fake *memtable.DB, options MigrateOptions) MigrateSummary {
+	t.Helper()
+
+	migrator := NewClientWithAPI(fake).NewHTMLMigrator(context.Background(), options)
+	for _, item := range fake.Items("products") {
+		if err := migrator.Migrate(item); err != nil {
+			t.Fatalf("Migrate failed: %v", err)
+		}
+	}
+	return migrator.Summary()
+}
+
+func TestHTMLMigrator_Migrate(t *testing.T) {
+	fake := newHTMLTable(t, 5)
+	fake.Put("products", product("no-html"))
+
+	summary := migrateAll(t, fake, MigrateOptions{Table: "products", Encoding: processing.EncodingZstd})
+	if summary.Migrated != 5 || summary.Unchanged != 1 || summary.Failed != 0 || summary.Conflicts != 0 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+
+	client := NewClient()
+	for _, item := range fake.Items("products") {
+		if err := client.VerifyHTML(item, processing.EncodingZstd); err != nil {
+			t.Errorf("Item %v failed verification: %v", item["id"], err)
+		}
+	}
+
+	// Migrated items still unmarshal to the original HTML
+	for _, item := range fake.Items("products") {
+		if _, ok := item["rawHtml"].(*types.AttributeValueMemberB); !ok {
+			continue
+		}
+		product, err := client.UnmarshalProduct(item)
+		if err != nil {
+			t.Fatalf("UnmarshalProduct failed: %v", err)
+		}
+		if expected := "<div>product " + product.ID + "</div>"; product.RawHTMLExtracted != expected {
+			t.Errorf("Expected %s, got %s", expected, product.RawHTMLExtracted)
+		}
+	}
+
+	// A second run finds nothing left to do
+	summary = migrateAll(t, fake, MigrateOptions{Table: "products", Encoding: processing.EncodingZstd})
+	if summary.Migrated != 0 || summary.Unchanged != 6 {
+		t.Errorf("Expected every item unchanged, got %+v", summary)
+	}
+}
+
+func TestHTMLMigrator_SortKey(t *testing.T) {
+	extractor := processing.NewHTMLExtractor()
+	fake := memtable.New()
+	fake.CreateTable("products", "id", "scrapedAt")
+
+	for _, scrapedAt := range []string{"2024-01-01", "2024-01-02"} {
+		encoded, err := extractor.EncodeHTML("<div>"+scrapedAt+"</div>", processing.EncodingBase64Zlib)
+		if err != nil {
+			t.Fatal(err)
+		}
+		item := product("1")
+		item["scrapedAt"] = &types.AttributeValueMemberS{Value: scrapedAt}
+		item["rawHtml"] = &types.AttributeValueMemberS{Value: string(encoded)}
+		if err := fake.Put("products", item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	summary := migrateAll(t, fake, MigrateOptions{Table: "products", Encoding: processing.EncodingGzip})
+	if summary.Migrated != 2 || summary.Failed != 0 {
+		t.Errorf("Expected both versions migrated, got %+v", summary)
+	}
+	if calls := fake.Calls("DescribeTable"); calls != 1 {
+		t.Errorf("Expected the key schema to be described once, got %d calls", calls)
+	}
+
+	client := NewClient()
+	for _, item := range fake.Items("products") {
+		if err := client.VerifyHTML(item, processing.EncodingGzip); err != nil {
+			t.Errorf("Item %v failed verification: %v", item["scrapedAt"], err)
+		}
+	}
+}
+
+func TestHTMLMigrator_LeavesConcurrentWrites(t *testing.T) {
+	fake := newHTMLTable(t, 1)
+	stale := fake.Items("products")[0]
+
+	// The scraper rewrites the item after it has been read
+	fresh := product("0")
+	fresh["rawHtml"] = &types.AttributeValueMemberS{Value: "<div>rescraped</div>"}
+	fake.Put("products", fresh)
+
+	migrator := NewClientWithAPI(fake).NewHTMLMigrator(context.Background(), MigrateOptions{Table: "products", Encoding: processing.EncodingGzip})
+	if err := migrator.Migrate(stale); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+
+	if summary := migrator.Summary(); summary.Conflicts != 1 || summary.Migrated != 0 {
+		t.Errorf("Expected a conflict, got %+v", summary)
+	}
+	if html := fake.Items("products")[0]["rawHtml"].(*types.AttributeValueMemberS).Value; html != "<div>rescraped</div>" {
+		t.Errorf("Expected the scraper's write to survive, got %s", html)
+	}
+}
+
+func TestHTMLMigrator_DryRun(t *testing.T) {
+	fake := newHTMLTable(t, 3)
+
+	summary := migrateAll(t, fake, MigrateOptions{Table: "products", Encoding: processing.EncodingText, DryRun: true})
+	if summary.Migrated != 3 || summary.BytesAfter == 0 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+	if fake.Calls("UpdateItem") != 0 {
+		t.Errorf("Expected no writes, got %d", fake.Calls("UpdateItem"))
+	}
+}
+
+func TestHTMLMigrator_CountsFailures(t *testing.T) {
+	fake := newHTMLTable(t, 1)
+	item := product("broken")
+	item["rawHtml"] = &types.AttributeValueMemberS{Value: "bm90IHpsaWI="}
+	fake.Put("products", item)
+
+	fake.Fail = func(operation string, input interface{}) error {
+		if operation == "UpdateItem" {
+			return errors.New("access denied")
+		}
+		return nil
+	}
+
+	summary := migrateAll(t, fake, MigrateOptions{Table: "products", Encoding: processing.EncodingZlib})
+	if summary.Failed != 2 || summary.Migrated != 0 {
+		t.Errorf("Expected both items to fail, got %+v", summary)
+	}
+}
+
+func TestVerifyHTML_StrictBase64(t *testing.T) {
+	extractor := processing.NewHTMLExtractor()
+	encoded, err := extractor.EncodeHTML("<div>copied from the console</div>", processing.EncodingBase64Zlib)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Drop the padding, as some tools do when copying a value
+	unpadded := strings.TrimRight(string(encoded), "=")
+	if unpadded == string(encoded) {
+		t.Fatal("Expected the encoded HTML to be padded")
+	}
+	item := product("unpadded")
+	item["rawHtml"] = &types.AttributeValueMemberS{Value: unpadded}
+
+	client := NewClient()
+	decoded, err := client.UnmarshalProduct(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.RawHTMLExtracted != "<div>copied from the console</div>" || strings.Join(decoded.RawHTMLChain, ",") != "base64-unpadded,zlib" {
+		t.Errorf("Expected unpadded base64 to decode, got %q via %v", decoded.RawHTMLExtracted, decoded.RawHTMLChain)
+	}
+
+	if err := client.VerifyHTML(item, processing.EncodingBase64Zlib); err == nil {
+		t.Error("Expected verification to reject unpadded base64")
+	}
+
+	client.SetStrictBase64(true)
+	decoded, err = client.UnmarshalProduct(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(decoded.RawHTMLExtracted, "EXTRACTION_FAILED") {
+		t.Errorf("Expected strict decoding to fail, got %q", decoded.RawHTMLExtracted)
+	}
+}
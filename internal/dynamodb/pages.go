@@ -0,0 +1,169 @@
+package dynamodb
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// pageChain follows the LastEvaluatedKey of each Scan or Query page in the
+// order the pages were read. Every page but the last should carry a key, no
+// key should repeat, and when a page was not filtered its key should belong
+// to its last item.
+type pageChain struct {
+	pages []page
+}
+
+type page struct {
+	label            string
+	lastEvaluatedKey string
+	keyMatchesItem   bool
+}
+
+func (c *pageChain) add(source string, response DynamoDBResponse, lastItem map[string]json.RawMessage) {
+	p := page{
+		label:          fmt.Sprintf("page %d (%s)", len(c.pages)+1, source),
+		keyMatchesItem: true,
+	}
+
+	// ExecuteStatement pages are chained by NextToken instead
+	if response.NextToken != "" {
+		p.lastEvaluatedKey = "NextToken:" + response.NextToken
+	}
+
+	if response.LastEvaluatedKey != nil {
+		p.lastEvaluatedKey = canonicalJSON(response.LastEvaluatedKey)
+
+		// Filters drop items after they are evaluated, so only unfiltered
+		// pages are expected to end on the item named by the key
+		if lastItem != nil && response.Count == response.ScannedCount {
+			for name, value := range response.LastEvaluatedKey {
+				if compactJSON(lastItem[name]) != compactJSON(value) {
+					p.keyMatchesItem = false
+				}
+			}
+		}
+	}
+
+	c.pages = append(c.pages, p)
+}
+
+func (c *pageChain) warnings() []string {
+	var warnings []string
+
+	seen := make(map[string]string)
+	for i, p := range c.pages {
+		last := i == len(c.pages)-1
+
+		switch {
+		case p.lastEvaluatedKey == "" && !last:
+			warnings = append(warnings, fmt.Sprintf("%s has no LastEvaluatedKey but more pages follow; pages may be out of order or from different scans", p.label))
+		case p.lastEvaluatedKey != "" && last:
+			warnings = append(warnings, fmt.Sprintf("%s has a LastEvaluatedKey; the result is incomplete and more pages remain to be fetched", p.label))
+		}
+
+		if p.lastEvaluatedKey == "" {
+			continue
+		}
+
+		if first, exists := seen[p.lastEvaluatedKey]; exists {
+			warnings = append(warnings, fmt.Sprintf("%s repeats the LastEvaluatedKey of %s; a page may have been included twice", p.label, first))
+		}
+		seen[p.lastEvaluatedKey] = p.label
+
+		if !p.keyMatchesItem {
+			warnings = append(warnings, fmt.Sprintf("%s has a LastEvaluatedKey that does not match its last item", p.label))
+		}
+	}
+
+	return warnings
+}
+
+func canonicalJSON(value map[string]json.RawMessage) string {
+	compacted := make(map[string]json.RawMessage, len(value))
+	for key, raw := range value {
+		compacted[key] = json.RawMessage(compactJSON(raw))
+	}
+
+	// Marshal sorts map keys
+	data, err := json.Marshal(compacted)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func compactJSON(raw json.RawMessage) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
+
+// inputSource reads several inputs one after another, sharing one page
+// chain so that pages saved to separate files are checked as a sequence.
+type inputSource struct {
+	client  *Client
+	inputs  []string
+	pages   *pageChain
+	reader  io.ReadCloser
+	name    string // the input being read, for errors
+	current itemSource
+	last    itemSource
+}
+
+func (s *inputSource) next() (Record, error) {
+	for {
+		if s.current == nil {
+			if len(s.inputs) == 0 {
+				return Record{}, io.EOF
+			}
+
+			input := s.inputs[0]
+			s.inputs = s.inputs[1:]
+
+			reader, err := s.client.Open(input)
+			if err != nil {
+				return Record{}, err
+			}
+
+			name := input
+			if input == "-" {
+				name = "stdin"
+			}
+
+			s.reader = reader
+			s.name = name
+			s.current = s.client.newSource(reader, name, s.pages)
+			s.last = s.current
+		}
+
+		record, err := s.current.next()
+		if err == io.EOF {
+			s.current = nil
+			if err := s.Close(); err != nil {
+				return Record{}, err
+			}
+			continue
+		}
+
+		var attrErr *AttributeError
+		if errors.As(err, &attrErr) {
+			attrErr.Source = s.name
+		}
+		return record, err
+	}
+}
+
+func (s *inputSource) Close() error {
+	if s.reader == nil {
+		return nil
+	}
+
+	err := s.reader.Close()
+	s.reader = nil
+	return err
+}
@@ -0,0 +1,149 @@
+package dynamodb
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	scanPage1 = `{"Items": [{"id": {"S": "a"}}, {"id": {"S": "b"}}], "Count": 2, "ScannedCount": 2, "LastEvaluatedKey": {"id": {"S": "b"}}}`
+	scanPage2 = `{"Items": [{"id": {"S": "c"}}, {"id": {"S": "d"}}], "Count": 2, "ScannedCount": 2, "LastEvaluatedKey": {"id": {"S": "d"}}}`
+	scanPage3 = `{"Items": [{"id": {"S": "e"}}], "Count": 1, "ScannedCount": 1}`
+)
+
+func TestItemScanner_ConcatenatedPages(t *testing.T) {
+	client := NewClient()
+
+	input := scanPage1 + "\n" + scanPage2 + "\n" + scanPage3
+
+	scanner := client.NewItemScanner(strings.NewReader(input))
+	count := 0
+	for scanner.Scan() {
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Failed to scan pages: %v", err)
+	}
+
+	if count != 5 {
+		t.Errorf("Expected 5 items across pages, got %d", count)
+	}
+
+	if warnings := scanner.Warnings(); len(warnings) != 0 {
+		t.Errorf("Expected a complete chain, got %v", warnings)
+	}
+
+	if response := scanner.Response(); response.Count != 1 || response.LastEvaluatedKey != nil {
+		t.Errorf("Expected the last page's response, got %+v", response)
+	}
+}
+
+func TestItemScanner_IncompletePages(t *testing.T) {
+	client := NewClient()
+
+	tests := []struct {
+		name  string
+		pages []string
+		want  string
+	}{
+		{"missing last page", []string{scanPage1, scanPage2}, "page 2 (input) has a LastEvaluatedKey"},
+		{"page out of order", []string{scanPage1, scanPage3, scanPage2}, "page 2 (input) has no LastEvaluatedKey but more pages follow"},
+		{"page included twice", []string{scanPage1, scanPage1, scanPage3}, "page 2 (input) repeats the LastEvaluatedKey of page 1 (input)"},
+		{
+			"key of another item",
+			[]string{`{"Items": [{"id": {"S": "a"}}], "Count": 1, "ScannedCount": 1, "LastEvaluatedKey": {"id": {"S": "z"}}}`, scanPage3},
+			"page 1 (input) has a LastEvaluatedKey that does not match its last item",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := client.NewItemScanner(strings.NewReader(strings.Join(tt.pages, "\n")))
+			for scanner.Scan() {
+			}
+			if err := scanner.Err(); err != nil {
+				t.Fatalf("Failed to scan pages: %v", err)
+			}
+
+			warnings := strings.Join(scanner.Warnings(), "\n")
+			if !strings.Contains(warnings, tt.want) {
+				t.Errorf("Expected warning containing %q, got %q", tt.want, warnings)
+			}
+		})
+	}
+}
+
+func TestItemScanner_FilteredPageKeyIsNotChecked(t *testing.T) {
+	client := NewClient()
+
+	input := `{"Items": [{"id": {"S": "a"}}], "Count": 1, "ScannedCount": 3, "LastEvaluatedKey": {"id": {"S": "c"}}}` + scanPage3
+
+	scanner := client.NewItemScanner(strings.NewReader(input))
+	for scanner.Scan() {
+	}
+
+	if warnings := scanner.Warnings(); len(warnings) != 0 {
+		t.Errorf("Expected no warnings for a filtered page, got %v", warnings)
+	}
+}
+
+func TestNewInputScanner_PagesInSeparateFiles(t *testing.T) {
+	client := NewClient()
+
+	dir := t.TempDir()
+	var inputs []string
+	for i, page := range []string{scanPage1, scanPage2} {
+		path := filepath.Join(dir, "page"+string(rune('1'+i))+".json")
+		if err := os.WriteFile(path, []byte(page), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, path)
+	}
+
+	scanner := client.NewInputScanner(inputs...)
+	defer scanner.Close()
+
+	count := 0
+	for scanner.Scan() {
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Failed to scan files: %v", err)
+	}
+
+	if count != 4 {
+		t.Errorf("Expected 4 items, got %d", count)
+	}
+
+	warnings := scanner.Warnings()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "page2.json") {
+		t.Errorf("Expected one warning naming page2.json, got %v", warnings)
+	}
+}
+
+func TestNewInputScanner_ErrorNamesFileAndItem(t *testing.T) {
+	client := NewClient()
+
+	dir := t.TempDir()
+	var inputs []string
+	for i, page := range []string{scanPage1, `{"Items": [{"id": {"S": "c"}}, {"id": {"Q": "d"}}]}`} {
+		path := filepath.Join(dir, "p"+string(rune('1'+i))+".json")
+		if err := os.WriteFile(path, []byte(page), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, path)
+	}
+
+	scanner := client.NewInputScanner(inputs...)
+	defer scanner.Close()
+	for scanner.Scan() {
+	}
+
+	// The bad item is the second of p2.json, not the fourth read overall
+	expected := inputs[1] + `: Items[1].id.Q: unsupported attribute value type "Q"`
+	if err := scanner.Err(); err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
+}
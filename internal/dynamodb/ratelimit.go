@@ -0,0 +1,175 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gkwa/bouncingbeaver/internal/logger"
+)
+
+const (
+	throttleRetries    = 8
+	throttleBackoff    = 100 * time.Millisecond
+	throttleBackoffCap = 10 * time.Second
+)
+
+// capacityLimiter keeps the read capacity consumed by live reads under a
+// budget of max units per second, shared by every worker of a read. The cost
+// of a page is only known once DynamoDB reports it, so a page may overdraw
+// the budget; the next request then waits until it has been paid back.
+//
+// When DynamoDB reports that provisioned throughput was exceeded the allowed
+// rate is halved, and it climbs back towards max with each successful page.
+type capacityLimiter struct {
+	logger *logger.Logger
+	max    float64 // zero means unlimited
+
+	mu        sync.Mutex
+	rate      float64
+	available float64
+	last      time.Time
+	consumed  float64
+
+	now   func() time.Time
+	sleep func(context.Context, time.Duration) error
+}
+
+func (c *Client) newCapacityLimiter(maxRCU float64) *capacityLimiter {
+	return &capacityLimiter{
+		logger:    c.logger,
+		max:       maxRCU,
+		rate:      maxRCU,
+		available: maxRCU,
+		now:       time.Now,
+		sleep:     sleepContext,
+	}
+}
+
+// do calls read once the budget allows it, retrying with exponential backoff
+// while the table's provisioned throughput is exceeded. read returns the
+// capacity its request consumed.
+func (l *capacityLimiter) do(ctx context.Context, read func() (*types.ConsumedCapacity, error)) error {
+	backoff := throttleBackoff
+
+	for attempt := 1; ; attempt++ {
+		if err := l.wait(ctx); err != nil {
+			return err
+		}
+
+		consumed, err := read()
+		if err == nil {
+			l.record(consumed)
+			return nil
+		}
+
+		var exceeded *types.ProvisionedThroughputExceededException
+		if !errors.As(err, &exceeded) || attempt == throttleRetries {
+			return err
+		}
+
+		if rate := l.throttled(); rate > 0 {
+			l.logger.Warn("Provisioned throughput exceeded, backing off", "attempt", attempt, "delay", backoff, "rcu_per_second", rate)
+		} else {
+			l.logger.Warn("Provisioned throughput exceeded, backing off", "attempt", attempt, "delay", backoff)
+		}
+
+		if err := l.sleep(ctx, backoff); err != nil {
+			return err
+		}
+		backoff = min(backoff*2, throttleBackoffCap)
+	}
+}
+
+// wait blocks until the budget is no longer overdrawn.
+func (l *capacityLimiter) wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.max <= 0 {
+			l.mu.Unlock()
+			return nil
+		}
+
+		l.refill()
+		if l.available >= 0 {
+			l.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration(math.Max(-l.available/l.rate, 0.01) * float64(time.Second))
+		l.mu.Unlock()
+
+		if err := l.sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// refill credits the budget for the time since it was last refilled, up to
+// one second's worth. The caller holds mu.
+func (l *capacityLimiter) refill() {
+	now := l.now()
+	if !l.last.IsZero() {
+		l.available += now.Sub(l.last).Seconds() * l.rate
+	}
+	l.available = math.Min(l.available, l.rate)
+	l.last = now
+}
+
+func (l *capacityLimiter) record(consumed *types.ConsumedCapacity) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var units float64
+	if consumed != nil && consumed.CapacityUnits != nil {
+		units = *consumed.CapacityUnits
+	}
+
+	l.consumed += units
+	if l.max <= 0 {
+		return
+	}
+
+	l.refill()
+	l.available -= units
+	l.rate = math.Min(l.rate+l.max/10, l.max)
+}
+
+// throttled halves the allowed rate and returns the new one.
+func (l *capacityLimiter) throttled() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.max > 0 {
+		l.rate = math.Max(l.rate/2, math.Min(1, l.max))
+		l.available = math.Min(l.available, 0)
+	}
+	return l.rate
+}
+
+// consumedCapacity reports the capacity consumed so far in the shape of a
+// response's ConsumedCapacity.
+func (l *capacityLimiter) consumedCapacity(table string) *types.ConsumedCapacity {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return &types.ConsumedCapacity{
+		TableName:     aws.String(table),
+		CapacityUnits: aws.Float64(l.consumed),
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
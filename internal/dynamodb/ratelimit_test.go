@@ -0,0 +1,143 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// newTestLimiter returns a limiter on a fake clock that advances whenever
+// the limiter sleeps, along with the sleeps it made.
+func newTestLimiter(maxRCU float64) (*capacityLimiter, *[]time.Duration) {
+	limiter := NewClient().newCapacityLimiter(maxRCU)
+
+	clock := time.Unix(0, 0)
+	var sleeps []time.Duration
+
+	limiter.now = func() time.Time { return clock }
+	limiter.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		clock = clock.Add(d)
+		return nil
+	}
+
+	return limiter, &sleeps
+}
+
+func consumed(units float64) *types.ConsumedCapacity {
+	return &types.ConsumedCapacity{CapacityUnits: aws.Float64(units)}
+}
+
+func TestCapacityLimiter_WaitsForOverdraw(t *testing.T) {
+	limiter, sleeps := newTestLimiter(10)
+	ctx := context.Background()
+
+	read := func() (*types.ConsumedCapacity, error) { return consumed(25), nil }
+
+	if err := limiter.do(ctx, read); err != nil {
+		t.Fatalf("First read failed: %v", err)
+	}
+	if len(*sleeps) != 0 {
+		t.Errorf("Expected the first read to go straight through, slept %v", *sleeps)
+	}
+
+	if err := limiter.do(ctx, read); err != nil {
+		t.Fatalf("Second read failed: %v", err)
+	}
+
+	// 10 units of budget minus 25 consumed leaves 15 to pay back at 10 per second
+	if len(*sleeps) != 1 || (*sleeps)[0] != 1500*time.Millisecond {
+		t.Errorf("Expected one 1.5s wait, got %v", *sleeps)
+	}
+
+	if total := *limiter.consumedCapacity("products").CapacityUnits; total != 50 {
+		t.Errorf("Expected 50 units consumed, got %v", total)
+	}
+}
+
+func TestCapacityLimiter_Unlimited(t *testing.T) {
+	limiter, sleeps := newTestLimiter(0)
+
+	for i := 0; i < 5; i++ {
+		limiter.do(context.Background(), func() (*types.ConsumedCapacity, error) { return consumed(1000), nil })
+	}
+
+	if len(*sleeps) != 0 {
+		t.Errorf("Expected no waits without a limit, got %v", *sleeps)
+	}
+	if total := *limiter.consumedCapacity("products").CapacityUnits; total != 5000 {
+		t.Errorf("Expected 5000 units consumed, got %v", total)
+	}
+}
+
+func TestCapacityLimiter_BacksOffWhenThrottled(t *testing.T) {
+	limiter, sleeps := newTestLimiter(100)
+
+	attempts := 0
+	err := limiter.do(context.Background(), func() (*types.ConsumedCapacity, error) {
+		attempts++
+		if attempts <= 3 {
+			return nil, &types.ProvisionedThroughputExceededException{Message: aws.String("slow down")}
+		}
+		return consumed(1), nil
+	})
+	if err != nil {
+		t.Fatalf("Expected the read to succeed after backing off: %v", err)
+	}
+
+	if attempts != 4 {
+		t.Errorf("Expected 4 attempts, got %d", attempts)
+	}
+
+	var backoffs []time.Duration
+	for _, d := range *sleeps {
+		if d >= throttleBackoff {
+			backoffs = append(backoffs, d)
+		}
+	}
+	expected := []time.Duration{throttleBackoff, 2 * throttleBackoff, 4 * throttleBackoff}
+	if len(backoffs) != len(expected) || backoffs[0] != expected[0] || backoffs[2] != expected[2] {
+		t.Errorf("Expected backoffs %v, got %v", expected, backoffs)
+	}
+
+	// Halved three times from 100, then one successful page adds back 10
+	if limiter.rate != 22.5 {
+		t.Errorf("Expected rate 22.5, got %v", limiter.rate)
+	}
+}
+
+func TestCapacityLimiter_GivesUp(t *testing.T) {
+	limiter, _ := newTestLimiter(100)
+
+	attempts := 0
+	err := limiter.do(context.Background(), func() (*types.ConsumedCapacity, error) {
+		attempts++
+		return nil, &types.ProvisionedThroughputExceededException{}
+	})
+
+	var exceeded *types.ProvisionedThroughputExceededException
+	if !errors.As(err, &exceeded) {
+		t.Errorf("Expected the throttling error, got %v", err)
+	}
+	if attempts != throttleRetries {
+		t.Errorf("Expected %d attempts, got %d", throttleRetries, attempts)
+	}
+}
+
+func TestCapacityLimiter_OtherErrorsNotRetried(t *testing.T) {
+	limiter, _ := newTestLimiter(100)
+
+	attempts := 0
+	err := limiter.do(context.Background(), func() (*types.ConsumedCapacity, error) {
+		attempts++
+		return nil, &types.ResourceNotFoundException{}
+	})
+
+	if err == nil || attempts != 1 {
+		t.Errorf("Expected one failed attempt, got %d attempts and %v", attempts, err)
+	}
+}
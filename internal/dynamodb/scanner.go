@@ -0,0 +1,507 @@
+package dynamodb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ItemScanner reads items one at a time instead of loading the whole input
+// into memory. The input is either a sequence of JSON documents or the Amazon
+// Ion text written by an ION export. Each JSON document may be the output of
+// Scan, Query or ExecuteStatement (Items), GetItem or a line of a DynamoDB
+// export to S3 (Item), BatchGetItem or TransactGetItems (Responses), or a
+// batch of DynamoDB stream records (Records). It is used like bufio.Scanner:
+//
+//	scanner := client.NewItemScanner(reader)
+//	for scanner.Scan() {
+//		item := scanner.Item()
+//	}
+//	if err := scanner.Err(); err != nil {
+//		...
+//	}
+type ItemScanner struct {
+	client *Client
+	source itemSource
+	pages  *pageChain
+	filter *ItemFilter
+	done   bool
+	record Record
+	err    error
+}
+
+// Record is an item together with what the input recorded about it.
+type Record struct {
+	Item map[string]types.AttributeValue
+
+	// EventName is INSERT, MODIFY or REMOVE for items taken from a stream
+	// record, and StreamImage names the image the item came from: NewImage,
+	// OldImage or Keys.
+	EventName   string
+	StreamImage string
+
+	// Table is the table a BatchGetItem response listed the item under.
+	Table string
+}
+
+// itemSource produces records from one input format, returning io.EOF once
+// the input is exhausted.
+type itemSource interface {
+	next() (Record, error)
+}
+
+func (c *Client) NewItemScanner(reader io.Reader) *ItemScanner {
+	pages := &pageChain{}
+
+	return &ItemScanner{
+		client: c,
+		source: c.newSource(reader, "input", pages),
+		pages:  pages,
+	}
+}
+
+// NewInputScanner reads each input in turn, as opened by Open, so that Scan
+// pages saved one per file are read as a single result. Call Close if the
+// scanner is abandoned before Scan returns false.
+func (c *Client) NewInputScanner(inputs ...string) *ItemScanner {
+	pages := &pageChain{}
+
+	return &ItemScanner{
+		client: c,
+		source: &inputSource{client: c, inputs: inputs, pages: pages},
+		pages:  pages,
+	}
+}
+
+func (c *Client) newSource(reader io.Reader, name string, pages *pageChain) itemSource {
+	buffered := bufio.NewReader(reader)
+
+	if isIonText(buffered) {
+		return newIonSource(buffered)
+	}
+	return newJSONSource(c, buffered, name, pages)
+}
+
+// Scan advances to the next item. It returns false when the input is
+// exhausted or an error occurred; Err distinguishes the two.
+func (s *ItemScanner) Scan() bool {
+	if s.done || s.err != nil {
+		return false
+	}
+
+	for {
+		record, err := s.source.next()
+		if err != nil {
+			if err == io.EOF {
+				for _, warning := range s.pages.warnings() {
+					s.client.logger.Warn("Incomplete pagination", "problem", warning)
+				}
+			} else {
+				s.err = err
+			}
+			s.done = true
+			s.record = Record{}
+			return false
+		}
+
+		if s.filter != nil {
+			var ok bool
+			if record, ok = s.filter.applyRecord(record); !ok {
+				continue
+			}
+		}
+
+		s.record = record
+		return true
+	}
+}
+
+// SetFilter makes Scan skip the items filter rejects and return the rest
+// reduced to its projection. Call it before the first call to Scan.
+func (s *ItemScanner) SetFilter(filter *ItemFilter) {
+	s.filter = filter
+}
+
+// Item returns the most recent item read by Scan.
+func (s *ItemScanner) Item() map[string]types.AttributeValue {
+	return s.record.Item
+}
+
+// Record returns the most recent item read by Scan along with its metadata.
+func (s *ItemScanner) Record() Record {
+	return s.record
+}
+
+// Err returns the first error encountered while scanning.
+func (s *ItemScanner) Err() error {
+	return s.err
+}
+
+// Response returns the top-level fields of the most recent JSON document
+// other than Items, such as Count and ScannedCount. For a live read it holds
+// the totals over every page fetched so far, with ConsumedCapacity as a
+// *types.ConsumedCapacity. It is complete once Scan returns false, and empty
+// for Ion input.
+func (s *ItemScanner) Response() DynamoDBResponse {
+	source := s.source
+	if inputs, ok := source.(*inputSource); ok {
+		source = inputs.last
+	}
+	switch source := source.(type) {
+	case *jsonSource:
+		return source.response
+	case *liveSource:
+		return source.response
+	case *segmentSource:
+		return source.response
+	}
+	return DynamoDBResponse{}
+}
+
+// Warnings describes gaps found in the chain of LastEvaluatedKey values
+// across Scan or Query pages. It is complete once Scan returns false.
+func (s *ItemScanner) Warnings() []string {
+	return s.pages.warnings()
+}
+
+// Close releases the input currently being read by a scanner created with
+// NewInputScanner.
+func (s *ItemScanner) Close() error {
+	if closer, ok := s.source.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// isIonText reports whether the input starts with an Ion version marker,
+// ignoring leading whitespace. It peeks no further than it has to, so JSON
+// arriving through a pipe is not held up waiting for more input.
+func isIonText(reader *bufio.Reader) bool {
+	const marker = "$ion_1_0"
+
+	for n := 1; ; n++ {
+		peeked, err := reader.Peek(n)
+		if err != nil {
+			return false
+		}
+
+		switch peeked[n-1] {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '$':
+			peeked, err := reader.Peek(n - 1 + len(marker))
+			return err == nil && bytes.HasSuffix(peeked, []byte(marker))
+		default:
+			return false
+		}
+	}
+}
+
+// jsonSource walks DynamoDB JSON documents token by token.
+type jsonSource struct {
+	client   *Client
+	decoder  *json.Decoder
+	name     string
+	pages    *pageChain
+	started  bool
+	array    string
+	table    string
+	inTables bool
+	pending  []Record
+	element  int // index of the next element of the current array
+	document int // index of the current document
+	fields   map[string]json.RawMessage
+	sawItems bool
+	lastItem map[string]json.RawMessage
+	response DynamoDBResponse
+}
+
+func newJSONSource(client *Client, reader io.Reader, name string, pages *pageChain) *jsonSource {
+	return &jsonSource{
+		client:  client,
+		decoder: json.NewDecoder(reader),
+		name:    name,
+		pages:   pages,
+		fields:  make(map[string]json.RawMessage),
+	}
+}
+
+func (s *jsonSource) next() (Record, error) {
+	if len(s.pending) > 0 {
+		record := s.pending[0]
+		s.pending = s.pending[1:]
+		return record, nil
+	}
+
+	if !s.started {
+		if err := s.expectDelim('{'); err != nil {
+			return Record{}, err
+		}
+		s.started = true
+	}
+
+	for {
+		if s.array != "" {
+			if s.decoder.More() {
+				switch s.array {
+				case "Records":
+					if err := s.decodeStreamRecord(); err != nil {
+						return Record{}, err
+					}
+				case "Responses":
+					if err := s.decodeTransactResponse(); err != nil {
+						return Record{}, err
+					}
+				default:
+					return s.decodeItem()
+				}
+
+				// Stream records without images and transaction responses
+				// for missing items yield nothing
+				if len(s.pending) == 0 {
+					continue
+				}
+				return s.next()
+			}
+			if err := s.expectDelim(']'); err != nil {
+				return Record{}, err
+			}
+			s.array = ""
+			s.table = ""
+		}
+
+		token, err := s.decoder.Token()
+		if err != nil {
+			return Record{}, s.syntaxError(err)
+		}
+
+		if delim, ok := token.(json.Delim); ok && delim == '}' {
+			// The end of a BatchGetItem Responses object rather than of
+			// the document
+			if s.inTables {
+				s.inTables = false
+				continue
+			}
+
+			if err := s.finishResponse(); err != nil {
+				return Record{}, err
+			}
+			if !s.decoder.More() {
+				return Record{}, io.EOF
+			}
+			if err := s.expectDelim('{'); err != nil {
+				return Record{}, err
+			}
+			continue
+		}
+
+		key, ok := token.(string)
+		if !ok {
+			return Record{}, fmt.Errorf("failed to unmarshal JSON: unexpected token %v", token)
+		}
+
+		if s.inTables {
+			opened, err := s.startArray(key)
+			if err != nil {
+				return Record{}, err
+			}
+			if opened {
+				s.table = key
+				s.array = "Items"
+				s.element = 0
+			}
+			continue
+		}
+
+		switch key {
+		case "Items", "Records":
+			s.sawItems = s.sawItems || key == "Items"
+			opened, err := s.startArray(key)
+			if err != nil {
+				return Record{}, err
+			}
+			if opened {
+				s.array = key
+				s.element = 0
+			}
+			continue
+		case "Responses":
+			if err := s.startResponses(); err != nil {
+				return Record{}, err
+			}
+			continue
+		case "Item":
+			if err := s.decodeOptionalItem(); err != nil {
+				return Record{}, err
+			}
+			if len(s.pending) == 0 {
+				continue
+			}
+			return s.next()
+		}
+
+		var value json.RawMessage
+		if err := s.decoder.Decode(&value); err != nil {
+			return Record{}, s.syntaxError(err)
+		}
+		s.fields[key] = value
+	}
+}
+
+// startArray consumes the opening bracket of the array stored under key and
+// reports whether there is an array to read; a null array is treated as
+// empty.
+func (s *jsonSource) startArray(key string) (bool, error) {
+	token, err := s.decoder.Token()
+	if err != nil {
+		return false, s.syntaxError(err)
+	}
+
+	switch token {
+	case nil:
+		return false, nil
+	case json.Delim('['):
+		return true, nil
+	default:
+		return false, fmt.Errorf("failed to unmarshal JSON: expected %s to be an array, got %v", key, token)
+	}
+}
+
+// startResponses handles the two shapes Responses takes: an object mapping
+// table names to items in BatchGetItem output, and an array of {"Item": ...}
+// entries in TransactGetItems output.
+func (s *jsonSource) startResponses() error {
+	token, err := s.decoder.Token()
+	if err != nil {
+		return s.syntaxError(err)
+	}
+
+	switch token {
+	case nil:
+		return nil
+	case json.Delim('{'):
+		s.inTables = true
+		return nil
+	case json.Delim('['):
+		s.array = "Responses"
+		s.element = 0
+		return nil
+	default:
+		return fmt.Errorf("failed to unmarshal JSON: expected Responses to be an object or array, got %v", token)
+	}
+}
+
+func (s *jsonSource) decodeItem() (Record, error) {
+	var raw map[string]json.RawMessage
+	if err := s.decoder.Decode(&raw); err != nil {
+		return Record{}, s.syntaxError(err)
+	}
+
+	array := ""
+	if s.table != "" {
+		array = "Responses." + s.table
+	}
+	item, err := s.client.parseItem(raw, "")
+	if err != nil {
+		return Record{}, locateAttributeError(err, array, s.element)
+	}
+	s.element++
+	s.lastItem = raw
+
+	return Record{Item: item, Table: s.table}, nil
+}
+
+// decodeOptionalItem queues the item under an Item key unless it is null.
+func (s *jsonSource) decodeOptionalItem() error {
+	var raw map[string]json.RawMessage
+	if err := s.decoder.Decode(&raw); err != nil {
+		return s.syntaxError(err)
+	}
+	if raw == nil {
+		return nil
+	}
+
+	item, err := s.client.parseItem(raw, "")
+	if err != nil {
+		return locateAttributeError(err, "", s.document)
+	}
+
+	s.pending = append(s.pending, Record{Item: item})
+	return nil
+}
+
+func (s *jsonSource) decodeTransactResponse() error {
+	var response struct {
+		Item map[string]json.RawMessage `json:"Item"`
+	}
+	if err := s.decoder.Decode(&response); err != nil {
+		return s.syntaxError(err)
+	}
+
+	index := s.element
+	s.element++
+
+	// Items that do not exist come back as empty entries
+	if response.Item == nil {
+		return nil
+	}
+
+	item, err := s.client.parseItem(response.Item, "")
+	if err != nil {
+		return locateAttributeError(err, "Responses", index)
+	}
+
+	s.pending = append(s.pending, Record{Item: item})
+	return nil
+}
+
+func (s *jsonSource) finishResponse() error {
+	data, err := json.Marshal(s.fields)
+	if err != nil {
+		return err
+	}
+
+	s.response = DynamoDBResponse{}
+	if err := json.Unmarshal(data, &s.response); err != nil {
+		return fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+
+	if s.sawItems || s.response.LastEvaluatedKey != nil || s.response.NextToken != "" {
+		s.pages.add(s.name, s.response, s.lastItem)
+	}
+
+	for table, keys := range s.response.UnprocessedKeys {
+		if len(keys.Keys) > 0 {
+			s.client.logger.Warn("BatchGetItem left keys unprocessed; output is incomplete", "table", table, "keys", len(keys.Keys))
+		}
+	}
+
+	s.fields = make(map[string]json.RawMessage)
+	s.lastItem = nil
+	s.sawItems = false
+	s.document++
+
+	return nil
+}
+
+func (s *jsonSource) expectDelim(want json.Delim) error {
+	token, err := s.decoder.Token()
+	if err != nil {
+		return s.syntaxError(err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != want {
+		return fmt.Errorf("failed to unmarshal JSON: expected %v, got %v", want, token)
+	}
+	return nil
+}
+
+func (s *jsonSource) syntaxError(err error) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("failed to unmarshal JSON: %w", err)
+}
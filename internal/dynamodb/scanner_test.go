@@ -0,0 +1,203 @@
+package dynamodb
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestItemScanner_YieldsItemsBeforeInputEnds(t *testing.T) {
+	client := NewClient()
+
+	pr, pw := io.Pipe()
+	defer pr.Close()
+
+	go func() {
+		pw.Write([]byte(`{"Items": [{"id": {"S": "first"}},`))
+	}()
+
+	scanner := client.NewItemScanner(pr)
+	if !scanner.Scan() {
+		t.Fatalf("Expected first item before the input was complete: %v", scanner.Err())
+	}
+
+	if id := scanner.Item()["id"].(*types.AttributeValueMemberS).Value; id != "first" {
+		t.Errorf("Expected id 'first', got %q", id)
+	}
+
+	go func() {
+		pw.Write([]byte(` {"id": {"S": "second"}}], "Count": 2, "ScannedCount": 5}`))
+		pw.Close()
+	}()
+
+	if !scanner.Scan() {
+		t.Fatalf("Expected second item: %v", scanner.Err())
+	}
+
+	if scanner.Scan() {
+		t.Fatal("Expected end of input")
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	response := scanner.Response()
+	if response.Count != 2 || response.ScannedCount != 5 {
+		t.Errorf("Expected Count 2 and ScannedCount 5, got %d and %d", response.Count, response.ScannedCount)
+	}
+}
+
+func TestItemScanner_ItemsAfterOtherFields(t *testing.T) {
+	client := NewClient()
+
+	input := `{"Count": 1, "ConsumedCapacity": {"CapacityUnits": 0.5}, "Items": [{"id": {"S": "a"}}]}`
+
+	items, err := client.LoadDataFromReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Failed to load data: %v", err)
+	}
+
+	if len(items) != 1 {
+		t.Errorf("Expected 1 item, got %d", len(items))
+	}
+}
+
+func TestItemScanner_ErrorIndexCountsStreamedItems(t *testing.T) {
+	client := NewClient()
+
+	input := `{"Items": [{"id": {"S": "a"}}, {"id": {"S": "b"}}, {"id": {"S": 3}}]}`
+
+	scanner := client.NewItemScanner(strings.NewReader(input))
+	count := 0
+	for scanner.Scan() {
+		count++
+	}
+
+	if count != 2 {
+		t.Errorf("Expected 2 items before the error, got %d", count)
+	}
+
+	var attrErr *AttributeError
+	if !errors.As(scanner.Err(), &attrErr) {
+		t.Fatalf("Expected *AttributeError, got %v", scanner.Err())
+	}
+
+	if attrErr.Error() != `Items[2].id.S: expected a string, got 3` {
+		t.Errorf("Unexpected error message: %v", attrErr)
+	}
+}
+
+func TestItemScanner_TruncatedInput(t *testing.T) {
+	client := NewClient()
+
+	_, err := client.LoadDataFromReader(strings.NewReader(`{"Items": [{"id": {"S": "a"}}`))
+	if err == nil {
+		t.Fatal("Expected an error for truncated input")
+	}
+}
+
+func TestItemScanner_ResponseShapes(t *testing.T) {
+	client := NewClient()
+
+	tests := []struct {
+		name   string
+		input  string
+		ids    []string
+		tables []string
+	}{
+		{
+			"get-item",
+			`{"Item": {"id": {"S": "a"}}}`,
+			[]string{"a"},
+			[]string{""},
+		},
+		{
+			"get-item for a missing item",
+			`{}`,
+			nil,
+			nil,
+		},
+		{
+			"batch-get-item",
+			`{"Responses": {"products": [{"id": {"S": "a"}}, {"id": {"S": "b"}}], "archive": [{"id": {"S": "c"}}], "empty": null}, "UnprocessedKeys": {}}`,
+			[]string{"a", "b", "c"},
+			[]string{"products", "products", "archive"},
+		},
+		{
+			"transact-get-items",
+			`{"Responses": [{"Item": {"id": {"S": "a"}}}, {}, {"Item": {"id": {"S": "c"}}}]}`,
+			[]string{"a", "c"},
+			[]string{"", ""},
+		},
+		{
+			"execute-statement",
+			`{"Items": [{"id": {"S": "a"}}], "NextToken": "t1"}` + "\n" + `{"Items": [{"id": {"S": "b"}}]}`,
+			[]string{"a", "b"},
+			[]string{"", ""},
+		},
+		{
+			"scan with null items",
+			`{"Items": null, "Count": 0}`,
+			nil,
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := client.NewItemScanner(strings.NewReader(tt.input))
+
+			var ids, tables []string
+			for scanner.Scan() {
+				record := scanner.Record()
+				ids = append(ids, record.Item["id"].(*types.AttributeValueMemberS).Value)
+				tables = append(tables, record.Table)
+			}
+			if err := scanner.Err(); err != nil {
+				t.Fatalf("Failed to scan: %v", err)
+			}
+
+			if strings.Join(ids, ",") != strings.Join(tt.ids, ",") {
+				t.Errorf("Expected ids %v, got %v", tt.ids, ids)
+			}
+			if strings.Join(tables, ",") != strings.Join(tt.tables, ",") {
+				t.Errorf("Expected tables %v, got %v", tt.tables, tables)
+			}
+			if warnings := scanner.Warnings(); len(warnings) != 0 {
+				t.Errorf("Expected no pagination warnings, got %v", warnings)
+			}
+		})
+	}
+}
+
+func TestUnmarshalRecord_SourceTable(t *testing.T) {
+	client := NewClient()
+
+	scanner := client.NewItemScanner(strings.NewReader(`{"Responses": {"products": [{"id": {"S": "a"}}]}}`))
+	if !scanner.Scan() {
+		t.Fatalf("Expected an item: %v", scanner.Err())
+	}
+
+	product, err := client.UnmarshalRecord(scanner.Record())
+	if err != nil {
+		t.Fatalf("Failed to unmarshal record: %v", err)
+	}
+
+	if product.SourceTable != "products" {
+		t.Errorf("Expected source table 'products', got %q", product.SourceTable)
+	}
+}
+
+func TestItemScanner_ErrorIndexRestartsEachPage(t *testing.T) {
+	client := NewClient()
+
+	input := scanPage1 + "\n" + `{"Items": [{"id": {"S": 3}}]}`
+
+	_, err := client.LoadDataFromReader(strings.NewReader(input))
+	if err == nil || err.Error() != `Items[0].id.S: expected a string, got 3` {
+		t.Errorf("Expected the first item of the second page, got %v", err)
+	}
+}
@@ -0,0 +1,290 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// segmentBuffer is how many pages each segment may fetch ahead of the output.
+// Segments are printed in order, so later segments wait once they are this
+// far ahead.
+const segmentBuffer = 8
+
+// SegmentOptions configures a parallel Scan. With CheckpointFile set, the
+// position of every segment is saved as output is written; Resume continues
+// from that file instead of starting over.
+type SegmentOptions struct {
+	TotalSegments  int
+	CheckpointFile string
+	Resume         bool
+}
+
+// ScanCheckpoint is the file written during a segmented Scan. A segment's
+// LastEvaluatedKey is that of the last page whose items have all been
+// written, in DynamoDB JSON.
+type ScanCheckpoint struct {
+	Table         string              `json:"table"`
+	Index         string              `json:"index,omitempty"`
+	Filter        string              `json:"filter,omitempty"`
+	TotalSegments int                 `json:"totalSegments"`
+	Segments      []SegmentCheckpoint `json:"segments"`
+}
+
+// SegmentCheckpoint records how far one segment has been written.
+type SegmentCheckpoint struct {
+	Segment          int                        `json:"segment"`
+	LastEvaluatedKey map[string]json.RawMessage `json:"lastEvaluatedKey,omitempty"`
+	Done             bool                       `json:"done"`
+}
+
+// ScanSegments reads a table with TotalSegments parallel Scan workers. Items
+// are returned segment by segment, so the output order does not depend on
+// which worker finishes first.
+func (c *Client) ScanSegments(ctx context.Context, options QueryOptions, segments SegmentOptions) (*ItemScanner, error) {
+	checkpoint, err := c.loadScanCheckpoint(options, segments)
+	if err != nil {
+		return nil, err
+	}
+
+	limiter := c.newCapacityLimiter(options.MaxRCU)
+	if c.api == nil {
+		return c.newLiveScanner(ctx, options.Table, limiter, nil), nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	source := &segmentSource{
+		client:     c,
+		table:      options.Table,
+		limiter:    limiter,
+		cancel:     cancel,
+		checkpoint: checkpoint,
+		path:       segments.CheckpointFile,
+		pages:      make([]chan segmentPage, checkpoint.TotalSegments),
+	}
+
+	if err := source.save(); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	for i := range source.pages {
+		source.pages[i] = make(chan segmentPage, segmentBuffer)
+
+		state := checkpoint.Segments[i]
+		if state.Done {
+			close(source.pages[i])
+			continue
+		}
+
+		var startKey map[string]types.AttributeValue
+		if state.LastEvaluatedKey != nil {
+			startKey, err = c.parseItem(state.LastEvaluatedKey, "")
+			if err != nil {
+				cancel()
+				return nil, fmt.Errorf("invalid checkpoint key for segment %d: %w", i, err)
+			}
+		}
+
+		go c.scanSegment(ctx, limiter, options, i, checkpoint.TotalSegments, startKey, source.pages[i])
+	}
+
+	c.logger.Debug("Started segmented scan", "table", options.Table, "segments", checkpoint.TotalSegments, "resume", segments.Resume)
+
+	return &ItemScanner{
+		client: c,
+		source: source,
+		pages:  &pageChain{},
+	}, nil
+}
+
+func (c *Client) loadScanCheckpoint(options QueryOptions, segments SegmentOptions) (*ScanCheckpoint, error) {
+	if !segments.Resume {
+		total := segments.TotalSegments
+		if total < 1 {
+			total = 1
+		}
+
+		checkpoint := &ScanCheckpoint{
+			Table:         options.Table,
+			Index:         options.Index,
+			Filter:        options.Filter,
+			TotalSegments: total,
+			Segments:      make([]SegmentCheckpoint, total),
+		}
+		for i := range checkpoint.Segments {
+			checkpoint.Segments[i].Segment = i
+		}
+		return checkpoint, nil
+	}
+
+	if segments.CheckpointFile == "" {
+		return nil, fmt.Errorf("resuming a scan requires a checkpoint file")
+	}
+
+	data, err := os.ReadFile(segments.CheckpointFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var checkpoint ScanCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %w", segments.CheckpointFile, err)
+	}
+
+	switch {
+	case checkpoint.Table != options.Table || checkpoint.Index != options.Index || checkpoint.Filter != options.Filter:
+		return nil, fmt.Errorf("checkpoint %s was written for a different table, index or filter", segments.CheckpointFile)
+	case segments.TotalSegments > 0 && segments.TotalSegments != checkpoint.TotalSegments:
+		return nil, fmt.Errorf("checkpoint %s was written for %d segments, not %d", segments.CheckpointFile, checkpoint.TotalSegments, segments.TotalSegments)
+	case checkpoint.TotalSegments < 1 || len(checkpoint.Segments) != checkpoint.TotalSegments:
+		return nil, fmt.Errorf("checkpoint %s lists %d segments for a total of %d", segments.CheckpointFile, len(checkpoint.Segments), checkpoint.TotalSegments)
+	}
+
+	return &checkpoint, nil
+}
+
+// scanSegment fetches every page of one segment and hands them to the
+// output in order. The channel is closed after the last page or an error.
+func (c *Client) scanSegment(ctx context.Context, limiter *capacityLimiter, options QueryOptions, segment, total int, startKey map[string]types.AttributeValue, pages chan<- segmentPage) {
+	defer close(pages)
+
+	for {
+		input := options.scanInput(startKey)
+		input.Segment = aws.Int32(int32(segment))
+		input.TotalSegments = aws.Int32(int32(total))
+
+		var page segmentPage
+		page.livePage, page.err = c.scanPage(ctx, limiter, input)
+		if page.err != nil {
+			page.err = fmt.Errorf("segment %d: %w", segment, page.err)
+		}
+
+		select {
+		case pages <- page:
+		case <-ctx.Done():
+			return
+		}
+
+		if page.err != nil || page.lastEvaluatedKey == nil {
+			return
+		}
+		startKey = page.lastEvaluatedKey
+	}
+}
+
+type segmentPage struct {
+	livePage
+	err error
+}
+
+// segmentSource hands out the items of each segment in turn, updating the
+// checkpoint once every item of a page has been handed out.
+type segmentSource struct {
+	client     *Client
+	table      string
+	limiter    *capacityLimiter
+	cancel     context.CancelFunc
+	checkpoint *ScanCheckpoint
+	path       string
+	pages      []chan segmentPage
+	current    int
+	items      []map[string]types.AttributeValue
+	pageKey    map[string]types.AttributeValue
+	inPage     bool
+	response   DynamoDBResponse
+}
+
+func (s *segmentSource) next() (Record, error) {
+	for len(s.items) == 0 {
+		if s.inPage {
+			// Everything up to this page's key has been handed out
+			s.inPage = false
+			if err := s.advance(); err != nil {
+				return Record{}, err
+			}
+		}
+
+		if s.current >= len(s.pages) {
+			return Record{}, io.EOF
+		}
+
+		page, ok := <-s.pages[s.current]
+		if !ok {
+			s.current++
+			continue
+		}
+		if page.err != nil {
+			return Record{}, page.err
+		}
+
+		s.items = page.items
+		s.pageKey = page.lastEvaluatedKey
+		s.inPage = true
+		s.response.Count += page.count
+		s.response.ScannedCount += page.scannedCount
+		s.response.ConsumedCapacity = s.limiter.consumedCapacity(s.table)
+	}
+
+	item := s.items[0]
+	s.items = s.items[1:]
+
+	return Record{Item: item}, nil
+}
+
+func (s *segmentSource) advance() error {
+	key, err := encodeItem(s.pageKey)
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint key: %w", err)
+	}
+
+	state := &s.checkpoint.Segments[s.current]
+	state.LastEvaluatedKey = key
+	state.Done = key == nil
+
+	return s.save()
+}
+
+// save writes the checkpoint through a temporary file so an interrupted
+// write never leaves a truncated checkpoint behind.
+func (s *segmentSource) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.checkpoint, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	_, err = temp.Write(append(data, '\n'))
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temp.Name(), s.path)
+	}
+	if err != nil {
+		os.Remove(temp.Name())
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// Close stops the segment workers.
+func (s *segmentSource) Close() error {
+	s.cancel()
+	return nil
+}
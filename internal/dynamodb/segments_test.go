@@ -0,0 +1,205 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gkwa/bouncingbeaver/internal/memtable"
+)
+
+// newProductTable returns a fake holding count products keyed on id.
+func newProductTable(t *testing.T, count int) *memtable.DB {
+	t.Helper()
+
+	fake := memtable.New()
+	fake.CreateTable("products", "id", "")
+	for i := 0; i < count; i++ {
+		item := map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: fmt.Sprintf("product-%02d", i)}}
+		if err := fake.Put("products", item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return fake
+}
+
+// segmentIDs returns the ids in each segment in the order the fake scans
+// them.
+func segmentIDs(t *testing.T, fake *memtable.DB, total int) [][]string {
+	t.Helper()
+
+	segments := make([][]string, total)
+	for segment := range segments {
+		output, err := fake.Scan(context.Background(), &awsdynamodb.ScanInput{
+			TableName:     aws.String("products"),
+			Segment:       aws.Int32(int32(segment)),
+			TotalSegments: aws.Int32(int32(total)),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range output.Items {
+			segments[segment] = append(segments[segment], item["id"].(*types.AttributeValueMemberS).Value)
+		}
+	}
+	return segments
+}
+
+func scanIDs(t *testing.T, scanner *ItemScanner) ([]string, error) {
+	t.Helper()
+
+	var ids []string
+	for scanner.Scan() {
+		ids = append(ids, scanner.Item()["id"].(*types.AttributeValueMemberS).Value)
+	}
+	scanner.Close()
+	return ids, scanner.Err()
+}
+
+func TestScanSegments_MergesBySegment(t *testing.T) {
+	fake := newProductTable(t, 30)
+	client := NewClientWithAPI(fake)
+
+	checkpointFile := filepath.Join(t.TempDir(), "checkpoint.json")
+	scanner, err := client.ScanSegments(context.Background(), QueryOptions{Table: "products", PageSize: 2}, SegmentOptions{TotalSegments: 3, CheckpointFile: checkpointFile})
+	if err != nil {
+		t.Fatalf("ScanSegments failed: %v", err)
+	}
+
+	ids, err := scanIDs(t, scanner)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	var expected []string
+	for _, segment := range segmentIDs(t, fake, 3) {
+		expected = append(expected, segment...)
+	}
+	if strings.Join(ids, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected items in segment order\n%v\ngot\n%v", expected, ids)
+	}
+
+	checkpoint := readCheckpoint(t, checkpointFile)
+	for _, segment := range checkpoint.Segments {
+		if !segment.Done || segment.LastEvaluatedKey != nil {
+			t.Errorf("Expected segment %d to be done, got %+v", segment.Segment, segment)
+		}
+	}
+}
+
+func TestScanSegments_Resume(t *testing.T) {
+	fake := newProductTable(t, 30)
+	segments := segmentIDs(t, fake, 3)
+
+	// Fail segment 1 when it asks for its second page
+	var mu sync.Mutex
+	failing := true
+	fake.Fail = func(operation string, input interface{}) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		scan := input.(*awsdynamodb.ScanInput)
+		if failing && aws.ToInt32(scan.Segment) == 1 && scan.ExclusiveStartKey != nil {
+			return errors.New("injected failure")
+		}
+		return nil
+	}
+
+	client := NewClientWithAPI(fake)
+	options := QueryOptions{Table: "products", PageSize: 2}
+	checkpointFile := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	scanner, err := client.ScanSegments(context.Background(), options, SegmentOptions{TotalSegments: 3, CheckpointFile: checkpointFile})
+	if err != nil {
+		t.Fatalf("ScanSegments failed: %v", err)
+	}
+
+	first, err := scanIDs(t, scanner)
+	if err == nil || !strings.Contains(err.Error(), "segment 1") {
+		t.Fatalf("Expected segment 1 to fail, got %v", err)
+	}
+	expectedFirst := append(append([]string{}, segments[0]...), segments[1][:2]...)
+	if strings.Join(first, " ") != strings.Join(expectedFirst, " ") {
+		t.Errorf("Expected %v before the failure, got %v", expectedFirst, first)
+	}
+
+	checkpoint := readCheckpoint(t, checkpointFile)
+	if !checkpoint.Segments[0].Done || checkpoint.Segments[1].Done || checkpoint.Segments[2].Done {
+		t.Errorf("Unexpected checkpoint after failure: %+v", checkpoint.Segments)
+	}
+	var key map[string]string
+	json.Unmarshal(checkpoint.Segments[1].LastEvaluatedKey["id"], &key)
+	if key["S"] != segments[1][1] {
+		t.Errorf("Expected segment 1 to stop after %s, got %v", segments[1][1], key)
+	}
+
+	mu.Lock()
+	failing = false
+	mu.Unlock()
+
+	scanner, err = client.ScanSegments(context.Background(), options, SegmentOptions{CheckpointFile: checkpointFile, Resume: true})
+	if err != nil {
+		t.Fatalf("ScanSegments resume failed: %v", err)
+	}
+
+	second, err := scanIDs(t, scanner)
+	if err != nil {
+		t.Fatalf("Resumed scan failed: %v", err)
+	}
+	expectedSecond := append(append([]string{}, segments[1][2:]...), segments[2]...)
+	if strings.Join(second, " ") != strings.Join(expectedSecond, " ") {
+		t.Errorf("Expected %v after resuming, got %v", expectedSecond, second)
+	}
+}
+
+func TestScanSegments_ResumeMismatch(t *testing.T) {
+	client := NewClient()
+	checkpointFile := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	data, _ := json.Marshal(ScanCheckpoint{Table: "products", TotalSegments: 2, Segments: make([]SegmentCheckpoint, 2)})
+	if err := os.WriteFile(checkpointFile, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		options  QueryOptions
+		segments SegmentOptions
+	}{
+		{"different table", QueryOptions{Table: "orders"}, SegmentOptions{CheckpointFile: checkpointFile, Resume: true}},
+		{"different segment count", QueryOptions{Table: "products"}, SegmentOptions{TotalSegments: 4, CheckpointFile: checkpointFile, Resume: true}},
+		{"no checkpoint file", QueryOptions{Table: "products"}, SegmentOptions{Resume: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := client.ScanSegments(context.Background(), tt.options, tt.segments); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func readCheckpoint(t *testing.T, path string) ScanCheckpoint {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read checkpoint: %v", err)
+	}
+
+	var checkpoint ScanCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		t.Fatalf("Failed to parse checkpoint: %v", err)
+	}
+	return checkpoint
+}
@@ -0,0 +1,346 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	serverTargetPrefix = "DynamoDB_20120810."
+	serverErrorPrefix  = "com.amazonaws.dynamodb.v20120810#"
+	serverContentType  = "application/x-amz-json-1.0"
+)
+
+// ServerBackend is what a Server answers requests from. The in-memory table
+// in internal/memtable satisfies it, as does the SDK client.
+type ServerBackend interface {
+	Scan(ctx context.Context, input *awsdynamodb.ScanInput, optFns ...func(*awsdynamodb.Options)) (*awsdynamodb.ScanOutput, error)
+	Query(ctx context.Context, input *awsdynamodb.QueryInput, optFns ...func(*awsdynamodb.Options)) (*awsdynamodb.QueryOutput, error)
+	GetItem(ctx context.Context, input *awsdynamodb.GetItemInput, optFns ...func(*awsdynamodb.Options)) (*awsdynamodb.GetItemOutput, error)
+	DescribeTable(ctx context.Context, input *awsdynamodb.DescribeTableInput, optFns ...func(*awsdynamodb.Options)) (*awsdynamodb.DescribeTableOutput, error)
+}
+
+var _ ServerBackend = (*awsdynamodb.Client)(nil)
+
+// Server speaks the DynamoDB JSON-over-HTTP protocol for Scan, Query,
+// GetItem and DescribeTable, so the aws CLI and SDKs can read from a
+// backend by pointing their endpoint at it. Requests are not
+// authenticated; any credentials are accepted.
+type Server struct {
+	client  *Client
+	backend ServerBackend
+}
+
+func (c *Client) NewServer(backend ServerBackend) *Server {
+	return &Server{client: c, backend: backend}
+}
+
+// serverRequest holds the request fields of every supported operation.
+// Attribute values stay as DynamoDB JSON until the operation is known.
+type serverRequest struct {
+	TableName                 *string
+	IndexName                 *string
+	Key                       map[string]json.RawMessage
+	ExclusiveStartKey         map[string]json.RawMessage
+	KeyConditionExpression    *string
+	FilterExpression          *string
+	ProjectionExpression      *string
+	ExpressionAttributeNames  map[string]string
+	ExpressionAttributeValues map[string]json.RawMessage
+	Limit                     *int32
+	Segment                   *int32
+	TotalSegments             *int32
+	ConsistentRead            *bool
+	ScanIndexForward          *bool
+	Select                    types.Select
+	ReturnConsumedCapacity    types.ReturnConsumedCapacity
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.writeError(w, http.StatusMethodNotAllowed, "UnknownOperationException", "only POST requests are supported")
+		return
+	}
+
+	operation, ok := strings.CutPrefix(r.Header.Get("X-Amz-Target"), serverTargetPrefix)
+	if !ok {
+		s.writeError(w, http.StatusBadRequest, "UnknownOperationException", "missing or unknown X-Amz-Target header")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "SerializationException", "failed to read request body")
+		return
+	}
+
+	var request serverRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		s.writeError(w, http.StatusBadRequest, "SerializationException", err.Error())
+		return
+	}
+
+	s.client.logger.Debug("Serving request", "operation", operation, "table", aws.ToString(request.TableName))
+
+	var response map[string]interface{}
+	switch operation {
+	case "Scan":
+		response, err = s.scan(r.Context(), request)
+	case "Query":
+		response, err = s.query(r.Context(), request)
+	case "GetItem":
+		response, err = s.getItem(r.Context(), request)
+	case "DescribeTable":
+		response, err = s.describeTable(r.Context(), request)
+	default:
+		s.writeError(w, http.StatusBadRequest, "UnknownOperationException", fmt.Sprintf("operation %s is not supported", operation))
+		return
+	}
+
+	if err != nil {
+		s.writeBackendError(w, operation, err)
+		return
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, "InternalServerError", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", serverContentType)
+	w.Write(data)
+}
+
+func (s *Server) scan(ctx context.Context, request serverRequest) (map[string]interface{}, error) {
+	startKey, values, err := s.parseRequestItems(request)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := s.backend.Scan(ctx, &awsdynamodb.ScanInput{
+		TableName:                 request.TableName,
+		IndexName:                 request.IndexName,
+		ExclusiveStartKey:         startKey,
+		FilterExpression:          request.FilterExpression,
+		ProjectionExpression:      request.ProjectionExpression,
+		ExpressionAttributeNames:  request.ExpressionAttributeNames,
+		ExpressionAttributeValues: values,
+		Limit:                     request.Limit,
+		Segment:                   request.Segment,
+		TotalSegments:             request.TotalSegments,
+		ConsistentRead:            request.ConsistentRead,
+		Select:                    request.Select,
+		ReturnConsumedCapacity:    request.ReturnConsumedCapacity,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.pageResponse(request, output.Items, output.Count, output.ScannedCount, output.LastEvaluatedKey, output.ConsumedCapacity)
+}
+
+func (s *Server) query(ctx context.Context, request serverRequest) (map[string]interface{}, error) {
+	startKey, values, err := s.parseRequestItems(request)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := s.backend.Query(ctx, &awsdynamodb.QueryInput{
+		TableName:                 request.TableName,
+		IndexName:                 request.IndexName,
+		ExclusiveStartKey:         startKey,
+		KeyConditionExpression:    request.KeyConditionExpression,
+		FilterExpression:          request.FilterExpression,
+		ProjectionExpression:      request.ProjectionExpression,
+		ExpressionAttributeNames:  request.ExpressionAttributeNames,
+		ExpressionAttributeValues: values,
+		Limit:                     request.Limit,
+		ConsistentRead:            request.ConsistentRead,
+		ScanIndexForward:          request.ScanIndexForward,
+		Select:                    request.Select,
+		ReturnConsumedCapacity:    request.ReturnConsumedCapacity,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.pageResponse(request, output.Items, output.Count, output.ScannedCount, output.LastEvaluatedKey, output.ConsumedCapacity)
+}
+
+func (s *Server) getItem(ctx context.Context, request serverRequest) (map[string]interface{}, error) {
+	key, err := s.parseRequestItem(request.Key, "Key.")
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := s.backend.GetItem(ctx, &awsdynamodb.GetItemInput{
+		TableName:                request.TableName,
+		Key:                      key,
+		ProjectionExpression:     request.ProjectionExpression,
+		ExpressionAttributeNames: request.ExpressionAttributeNames,
+		ConsistentRead:           request.ConsistentRead,
+		ReturnConsumedCapacity:   request.ReturnConsumedCapacity,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := map[string]interface{}{}
+	if output.Item != nil {
+		item, err := encodeItem(output.Item)
+		if err != nil {
+			return nil, err
+		}
+		response["Item"] = item
+	}
+	if output.ConsumedCapacity != nil {
+		response["ConsumedCapacity"] = encodeConsumedCapacity(output.ConsumedCapacity)
+	}
+
+	return response, nil
+}
+
+func (s *Server) describeTable(ctx context.Context, request serverRequest) (map[string]interface{}, error) {
+	output, err := s.backend.DescribeTable(ctx, &awsdynamodb.DescribeTableInput{TableName: request.TableName})
+	if err != nil {
+		return nil, err
+	}
+
+	table := output.Table
+
+	keySchema := make([]map[string]string, len(table.KeySchema))
+	for i, element := range table.KeySchema {
+		keySchema[i] = map[string]string{"AttributeName": aws.ToString(element.AttributeName), "KeyType": string(element.KeyType)}
+	}
+
+	definitions := make([]map[string]string, len(table.AttributeDefinitions))
+	for i, definition := range table.AttributeDefinitions {
+		definitions[i] = map[string]string{"AttributeName": aws.ToString(definition.AttributeName), "AttributeType": string(definition.AttributeType)}
+	}
+
+	description := map[string]interface{}{
+		"TableName":            aws.ToString(table.TableName),
+		"TableArn":             aws.ToString(table.TableArn),
+		"TableStatus":          string(table.TableStatus),
+		"KeySchema":            keySchema,
+		"AttributeDefinitions": definitions,
+		"ItemCount":            aws.ToInt64(table.ItemCount),
+		"TableSizeBytes":       aws.ToInt64(table.TableSizeBytes),
+	}
+	// Timestamps are epoch seconds in the JSON protocol
+	if table.CreationDateTime != nil {
+		description["CreationDateTime"] = table.CreationDateTime.Unix()
+	}
+	if table.BillingModeSummary != nil {
+		description["BillingModeSummary"] = map[string]string{"BillingMode": string(table.BillingModeSummary.BillingMode)}
+	}
+
+	return map[string]interface{}{"Table": description}, nil
+}
+
+// parseRequestItems decodes the ExclusiveStartKey and expression values of
+// a Scan or Query.
+func (s *Server) parseRequestItems(request serverRequest) (map[string]types.AttributeValue, map[string]types.AttributeValue, error) {
+	startKey, err := s.parseRequestItem(request.ExclusiveStartKey, "ExclusiveStartKey.")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values, err := s.parseRequestItem(request.ExpressionAttributeValues, "ExpressionAttributeValues.")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return startKey, values, nil
+}
+
+func (s *Server) parseRequestItem(item map[string]json.RawMessage, prefix string) (map[string]types.AttributeValue, error) {
+	if item == nil {
+		return nil, nil
+	}
+
+	parsed, err := s.client.parseItem(item, prefix)
+	if err != nil {
+		var attributeErr *AttributeError
+		if errors.As(err, &attributeErr) {
+			return nil, &smithy.GenericAPIError{Code: "ValidationException", Message: attributeErr.Path + ": " + attributeErr.Problem, Fault: smithy.FaultClient}
+		}
+		return nil, &smithy.GenericAPIError{Code: "ValidationException", Message: err.Error(), Fault: smithy.FaultClient}
+	}
+	return parsed, nil
+}
+
+func (s *Server) pageResponse(request serverRequest, items []map[string]types.AttributeValue, count, scannedCount int32, lastKey map[string]types.AttributeValue, consumed *types.ConsumedCapacity) (map[string]interface{}, error) {
+	response := map[string]interface{}{
+		"Count":        count,
+		"ScannedCount": scannedCount,
+	}
+
+	if request.Select != types.SelectCount {
+		encoded := make([]map[string]json.RawMessage, len(items))
+		for i, item := range items {
+			var err error
+			if encoded[i], err = encodeItem(item); err != nil {
+				return nil, err
+			}
+		}
+		response["Items"] = encoded
+	}
+
+	if lastKey != nil {
+		key, err := encodeItem(lastKey)
+		if err != nil {
+			return nil, err
+		}
+		response["LastEvaluatedKey"] = key
+	}
+	if consumed != nil {
+		response["ConsumedCapacity"] = encodeConsumedCapacity(consumed)
+	}
+
+	return response, nil
+}
+
+func encodeConsumedCapacity(consumed *types.ConsumedCapacity) map[string]interface{} {
+	return map[string]interface{}{
+		"TableName":     aws.ToString(consumed.TableName),
+		"CapacityUnits": aws.ToFloat64(consumed.CapacityUnits),
+	}
+}
+
+// writeBackendError reports an error from the backend the way DynamoDB
+// does: client errors with status 400 and their exception name, anything
+// else as a 500.
+func (s *Server) writeBackendError(w http.ResponseWriter, operation string, err error) {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorFault() != smithy.FaultServer {
+		s.client.logger.Debug("Request rejected", "operation", operation, "code", apiErr.ErrorCode(), "message", apiErr.ErrorMessage())
+		s.writeError(w, http.StatusBadRequest, apiErr.ErrorCode(), apiErr.ErrorMessage())
+		return
+	}
+
+	s.client.logger.Error("Request failed", "operation", operation, "error", err)
+	s.writeError(w, http.StatusInternalServerError, "InternalServerError", err.Error())
+}
+
+func (s *Server) writeError(w http.ResponseWriter, status int, code, message string) {
+	data, _ := json.Marshal(map[string]string{
+		"__type":  serverErrorPrefix + code,
+		"message": message,
+	})
+
+	w.Header().Set("Content-Type", serverContentType)
+	w.WriteHeader(status)
+	w.Write(data)
+}
@@ -0,0 +1,150 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gkwa/bouncingbeaver/internal/memtable"
+)
+
+// newServedClient serves fake over HTTP and returns an SDK client pointed at
+// it, so the requests and responses go through the real wire format.
+func newServedClient(t *testing.T, fake *memtable.DB) (*Client, *awsdynamodb.Client) {
+	t.Helper()
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_CONFIG_FILE", "/dev/null")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/dev/null")
+
+	server := httptest.NewServer(NewClient().NewServer(fake))
+	t.Cleanup(server.Close)
+
+	client := NewClient()
+	if err := client.Connect(context.Background(), LiveOptions{EndpointURL: server.URL}); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	return client, client.api.(*awsdynamodb.Client)
+}
+
+func TestServer_ScanAndQuery(t *testing.T) {
+	fake := memtable.New()
+	fake.CreateTable("orders", "customer", "placed")
+	for _, customer := range []string{"alice", "bob"} {
+		for day := 1; day <= 5; day++ {
+			fake.Put("orders", map[string]types.AttributeValue{
+				"customer": &types.AttributeValueMemberS{Value: customer},
+				"placed":   &types.AttributeValueMemberN{Value: fmt.Sprint(day)},
+				"receipt":  &types.AttributeValueMemberB{Value: []byte{0, 1, byte(day)}},
+				"tags":     &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+			})
+		}
+	}
+
+	client, _ := newServedClient(t, fake)
+	ctx := context.Background()
+
+	scanner := client.ScanTable(ctx, QueryOptions{Table: "orders", PageSize: 3})
+	var scanned []string
+	for scanner.Scan() {
+		item := scanner.Item()
+		scanned = append(scanned, item["customer"].(*types.AttributeValueMemberS).Value+item["placed"].(*types.AttributeValueMemberN).Value)
+		if receipt := item["receipt"].(*types.AttributeValueMemberB).Value; len(receipt) != 3 {
+			t.Errorf("Expected binary attributes to survive, got %v", receipt)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if len(scanned) != 10 || scanner.Response().ScannedCount != 10 {
+		t.Errorf("Expected 10 items over several pages, got %v", scanned)
+	}
+
+	values, err := client.ParseExpressionValues(`{":c": {"S": "bob"}, ":p": {"N": "3"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanner = client.QueryTable(ctx, QueryOptions{
+		Table:                     "orders",
+		KeyCondition:              "customer = :c AND placed >= :p",
+		ExpressionAttributeValues: values,
+	})
+	count := 0
+	for scanner.Scan() {
+		count++
+	}
+	if err := scanner.Err(); err != nil || count != 3 {
+		t.Errorf("Expected 3 items from the query, got %d, %v", count, err)
+	}
+}
+
+func TestServer_GetItemAndDescribeTable(t *testing.T) {
+	fake := newProductTable(t, 3)
+	_, sdk := newServedClient(t, fake)
+	ctx := context.Background()
+
+	output, err := sdk.GetItem(ctx, &awsdynamodb.GetItemInput{
+		TableName: aws.String("products"),
+		Key:       map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "product-01"}},
+	})
+	if err != nil {
+		t.Fatalf("GetItem failed: %v", err)
+	}
+	if output.Item == nil {
+		t.Error("Expected the item")
+	}
+
+	output, err = sdk.GetItem(ctx, &awsdynamodb.GetItemInput{
+		TableName: aws.String("products"),
+		Key:       map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "missing"}},
+	})
+	if err != nil || output.Item != nil {
+		t.Errorf("Expected no item, got %v, %v", output.Item, err)
+	}
+
+	described, err := sdk.DescribeTable(ctx, &awsdynamodb.DescribeTableInput{TableName: aws.String("products")})
+	if err != nil {
+		t.Fatalf("DescribeTable failed: %v", err)
+	}
+	table := described.Table
+	if aws.ToInt64(table.ItemCount) != 3 || table.TableStatus != types.TableStatusActive || table.CreationDateTime == nil {
+		t.Errorf("Unexpected description: %+v", table)
+	}
+	if len(table.KeySchema) != 1 || aws.ToString(table.KeySchema[0].AttributeName) != "id" || table.KeySchema[0].KeyType != types.KeyTypeHash {
+		t.Errorf("Unexpected key schema: %+v", table.KeySchema)
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	fake := newProductTable(t, 1)
+	_, sdk := newServedClient(t, fake)
+
+	_, err := sdk.Scan(context.Background(), &awsdynamodb.ScanInput{TableName: aws.String("missing")})
+	var notFound *types.ResourceNotFoundException
+	if !errors.As(err, &notFound) {
+		t.Errorf("Expected ResourceNotFoundException, got %v", err)
+	}
+
+	server := httptest.NewServer(NewClient().NewServer(fake))
+	defer server.Close()
+
+	request, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"TableName": "products"}`))
+	request.Header.Set("X-Amz-Target", "DynamoDB_20120810.PutItem")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected unsupported operations to be rejected, got status %d", response.StatusCode)
+	}
+}
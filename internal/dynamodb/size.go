@@ -0,0 +1,114 @@
+package dynamodb
+
+import (
+	"math"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gkwa/bouncingbeaver/internal/itemsize"
+)
+
+// MaxItemSize is the largest item DynamoDB accepts, attribute names
+// included.
+const MaxItemSize = 400 * 1024
+
+const (
+	readUnitBytes  = 4096
+	writeUnitBytes = 1024
+)
+
+// ItemSize is an item's size as DynamoDB counts it. Attributes holds the
+// size of each top-level attribute, its name included.
+type ItemSize struct {
+	ID         string
+	Bytes      int
+	Attributes map[string]int
+}
+
+// MeasureItem sizes an item and each of its attributes by DynamoDB's rules,
+// as internal/itemsize counts them.
+func MeasureItem(item map[string]types.AttributeValue) ItemSize {
+	size := ItemSize{Attributes: make(map[string]int, len(item))}
+	if s, ok := item["id"].(*types.AttributeValueMemberS); ok {
+		size.ID = s.Value
+	}
+
+	for name, value := range item {
+		bytes := len(name) + itemsize.Value(value)
+		size.Attributes[name] = bytes
+		size.Bytes += bytes
+	}
+
+	return size
+}
+
+// Largest returns the attribute that takes up most of the item, and its
+// size.
+func (s ItemSize) Largest() (string, int) {
+	largest, bytes := "", -1
+	for name, size := range s.Attributes {
+		if size > bytes || size == bytes && name < largest {
+			largest, bytes = name, size
+		}
+	}
+	return largest, max(bytes, 0)
+}
+
+// ReadUnits is the capacity a GetItem of the item consumes: one unit per
+// 4 KB for a strongly consistent read, half that for an eventually
+// consistent one.
+func (s ItemSize) ReadUnits(consistent bool) float64 {
+	units := math.Max(math.Ceil(float64(s.Bytes)/readUnitBytes), 1)
+	if !consistent {
+		units /= 2
+	}
+	return units
+}
+
+// WriteUnits is the capacity a PutItem of the item consumes: one unit per
+// 1 KB.
+func (s ItemSize) WriteUnits() float64 {
+	return math.Max(math.Ceil(float64(s.Bytes)/writeUnitBytes), 1)
+}
+
+// SizeReport summarises the sizes of a set of items and keeps those at or
+// above Threshold bytes.
+type SizeReport struct {
+	Threshold int
+	Items     int
+	Bytes     int64
+	OverLimit int
+	Largest   ItemSize
+
+	near []ItemSize
+}
+
+func NewSizeReport(threshold int) *SizeReport {
+	return &SizeReport{Threshold: threshold}
+}
+
+// Add measures one item.
+func (r *SizeReport) Add(item map[string]types.AttributeValue) {
+	size := MeasureItem(item)
+
+	r.Items++
+	r.Bytes += int64(size.Bytes)
+	if size.Bytes > MaxItemSize {
+		r.OverLimit++
+	}
+	if r.Items == 1 || size.Bytes > r.Largest.Bytes {
+		r.Largest = size
+	}
+	if size.Bytes >= r.Threshold {
+		r.near = append(r.near, size)
+	}
+}
+
+// Near returns the items at or above the threshold, largest first.
+func (r *SizeReport) Near() []ItemSize {
+	near := append([]ItemSize(nil), r.near...)
+	sort.SliceStable(near, func(i, j int) bool {
+		return near[i].Bytes > near[j].Bytes
+	})
+	return near
+}
@@ -0,0 +1,50 @@
+package dynamodb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestMeasureItem(t *testing.T) {
+	item := map[string]types.AttributeValue{
+		"id":      &types.AttributeValueMemberS{Value: "p1"},
+		"ttl":     &types.AttributeValueMemberN{Value: "1750000000"},
+		"rawHtml": &types.AttributeValueMemberS{Value: strings.Repeat("x", 5000)},
+	}
+
+	size := MeasureItem(item)
+	if size.ID != "p1" || size.Bytes != 4+(3+3)+(7+5000) {
+		t.Errorf("Unexpected size: %+v", size)
+	}
+
+	if name, bytes := size.Largest(); name != "rawHtml" || bytes != 5007 {
+		t.Errorf("Expected rawHtml to dominate, got %s with %d bytes", name, bytes)
+	}
+
+	if size.ReadUnits(true) != 2 || size.ReadUnits(false) != 1 || size.WriteUnits() != 5 {
+		t.Errorf("Unexpected capacity: %g RCU, %g eventually consistent RCU, %g WCU",
+			size.ReadUnits(true), size.ReadUnits(false), size.WriteUnits())
+	}
+}
+
+func TestSizeReport(t *testing.T) {
+	report := NewSizeReport(300 * 1024)
+
+	for _, length := range []int{100, 350 * 1024, 410 * 1024, 320 * 1024} {
+		report.Add(map[string]types.AttributeValue{
+			"id":      &types.AttributeValueMemberS{Value: "p"},
+			"rawHtml": &types.AttributeValueMemberS{Value: strings.Repeat("x", length)},
+		})
+	}
+
+	if report.Items != 4 || report.OverLimit != 1 || report.Largest.Bytes != 410*1024+10 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	near := report.Near()
+	if len(near) != 3 || near[0].Bytes < near[1].Bytes || near[1].Bytes < near[2].Bytes {
+		t.Errorf("Expected 3 items largest first, got %+v", near)
+	}
+}
@@ -0,0 +1,75 @@
+package dynamodb
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	streamstypes "github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+)
+
+// streamRecord is one element of the Records array in GetRecords output or
+// in the event a Lambda function receives from a DynamoDB stream. Both use
+// the same field names.
+type streamRecord struct {
+	EventID   string `json:"eventID"`
+	EventName string `json:"eventName"`
+	DynamoDB  struct {
+		Keys           map[string]json.RawMessage `json:"Keys"`
+		NewImage       map[string]json.RawMessage `json:"NewImage"`
+		OldImage       map[string]json.RawMessage `json:"OldImage"`
+		SequenceNumber string                     `json:"SequenceNumber"`
+		StreamViewType string                     `json:"StreamViewType"`
+	} `json:"dynamodb"`
+}
+
+type streamImage struct {
+	name  string
+	image map[string]json.RawMessage
+}
+
+// decodeStreamRecord queues one Record per image in the next stream record:
+// NewImage and OldImage when the stream carries them, or Keys for streams
+// with the KEYS_ONLY view type. A malformed image is reported at the
+// record's index in Records, which differs from the number of items read
+// when records carry two images or none.
+func (s *jsonSource) decodeStreamRecord() error {
+	index := s.element
+	s.element++
+
+	var record streamRecord
+	if err := s.decoder.Decode(&record); err != nil {
+		return s.syntaxError(err)
+	}
+
+	if !slices.Contains(streamstypes.OperationType("").Values(), streamstypes.OperationType(record.EventName)) {
+		return fmt.Errorf("stream record %s: unsupported event name %q", record.EventID, record.EventName)
+	}
+
+	images := []streamImage{
+		{"NewImage", record.DynamoDB.NewImage},
+		{"OldImage", record.DynamoDB.OldImage},
+	}
+	if record.DynamoDB.NewImage == nil && record.DynamoDB.OldImage == nil {
+		images = []streamImage{{"Keys", record.DynamoDB.Keys}}
+	}
+
+	for _, image := range images {
+		if image.image == nil {
+			continue
+		}
+
+		item, err := s.client.parseItem(image.image, "dynamodb."+image.name+".")
+		if err != nil {
+			return locateAttributeError(err, "Records", index)
+		}
+
+		s.pending = append(s.pending, Record{
+			Item:        item,
+			EventName:   record.EventName,
+			StreamImage: image.name,
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package dynamodb
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const lambdaStreamEvent = `{
+	"Records": [
+		{
+			"eventID": "1",
+			"eventName": "INSERT",
+			"eventSource": "aws:dynamodb",
+			"dynamodb": {
+				"Keys": {"id": {"S": "p-1"}},
+				"NewImage": {"id": {"S": "p-1"}, "name": {"S": "Ice"}},
+				"SequenceNumber": "111",
+				"StreamViewType": "NEW_AND_OLD_IMAGES"
+			}
+		},
+		{
+			"eventID": "2",
+			"eventName": "MODIFY",
+			"dynamodb": {
+				"Keys": {"id": {"S": "p-1"}},
+				"NewImage": {"id": {"S": "p-1"}, "name": {"S": "Ice 7 lb"}},
+				"OldImage": {"id": {"S": "p-1"}, "name": {"S": "Ice"}},
+				"SequenceNumber": "222"
+			}
+		},
+		{
+			"eventID": "3",
+			"eventName": "REMOVE",
+			"dynamodb": {
+				"Keys": {"id": {"S": "p-1"}},
+				"OldImage": {"id": {"S": "p-1"}, "name": {"S": "Ice 7 lb"}},
+				"SequenceNumber": "333"
+			}
+		}
+	]
+}`
+
+func TestItemScanner_StreamRecords(t *testing.T) {
+	client := NewClient()
+
+	scanner := client.NewItemScanner(strings.NewReader(lambdaStreamEvent))
+
+	var got []string
+	for scanner.Scan() {
+		product, err := client.UnmarshalRecord(scanner.Record())
+		if err != nil {
+			t.Fatalf("Failed to unmarshal record: %v", err)
+		}
+		got = append(got, product.EventName+"/"+product.StreamImage+"/"+product.Name)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Failed to scan stream records: %v", err)
+	}
+
+	want := []string{
+		"INSERT/NewImage/Ice",
+		"MODIFY/NewImage/Ice 7 lb",
+		"MODIFY/OldImage/Ice",
+		"REMOVE/OldImage/Ice 7 lb",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestItemScanner_StreamRecordsKeysOnly(t *testing.T) {
+	client := NewClient()
+
+	input := `{"Records": [{"eventID": "1", "eventName": "REMOVE", "dynamodb": {"Keys": {"id": {"S": "p-9"}}, "StreamViewType": "KEYS_ONLY"}}], "NextShardIterator": "abc"}`
+
+	scanner := client.NewItemScanner(strings.NewReader(input))
+	if !scanner.Scan() {
+		t.Fatalf("Expected a record: %v", scanner.Err())
+	}
+
+	record := scanner.Record()
+	if record.StreamImage != "Keys" {
+		t.Errorf("Expected Keys image, got %q", record.StreamImage)
+	}
+	if id := record.Item["id"].(*types.AttributeValueMemberS).Value; id != "p-9" {
+		t.Errorf("Expected id 'p-9', got %q", id)
+	}
+}
+
+func TestItemScanner_StreamRecordErrors(t *testing.T) {
+	client := NewClient()
+
+	input := `{"Records": [{"eventID": "1", "eventName": "INSERT", "dynamodb": {"NewImage": {"id": {"S": 1}}}}]}`
+
+	_, err := client.LoadDataFromReader(strings.NewReader(input))
+
+	var attrErr *AttributeError
+	if !errors.As(err, &attrErr) {
+		t.Fatalf("Expected *AttributeError, got %v", err)
+	}
+	if attrErr.Path != "dynamodb.NewImage.id.S" {
+		t.Errorf("Unexpected path %q", attrErr.Path)
+	}
+
+	// The first record yields two items, but the error is in the second record
+	input = `{"Records": [
+		{"eventID": "1", "eventName": "MODIFY", "dynamodb": {"NewImage": {"id": {"S": "a"}}, "OldImage": {"id": {"S": "a"}}}},
+		{"eventID": "2", "eventName": "INSERT", "dynamodb": {"NewImage": {"id": {"S": 3}}}}
+	]}`
+	_, err = client.LoadDataFromReader(strings.NewReader(input))
+	if !errors.As(err, &attrErr) {
+		t.Fatalf("Expected *AttributeError, got %v", err)
+	}
+	if attrErr.Error() != `Records[1].dynamodb.NewImage.id.S: expected a string, got 3` {
+		t.Errorf("Unexpected error message: %v", attrErr)
+	}
+
+	input = `{"Records": [{"eventID": "1", "eventName": "UPSERT", "dynamodb": {"NewImage": {"id": {"S": "a"}}}}]}`
+	if _, err := client.LoadDataFromReader(strings.NewReader(input)); err == nil {
+		t.Error("Expected an error for an unknown event name")
+	}
+}
@@ -0,0 +1,311 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const ttlAttribute = "ttl"
+
+// TTLPeriod is the width of a TTLReport bucket.
+type TTLPeriod string
+
+const (
+	TTLPeriodDay   TTLPeriod = "day"
+	TTLPeriodWeek  TTLPeriod = "week"
+	TTLPeriodMonth TTLPeriod = "month"
+)
+
+// ParseTTLPeriod checks that name is day, week or month.
+func ParseTTLPeriod(name string) (TTLPeriod, error) {
+	switch period := TTLPeriod(name); period {
+	case TTLPeriodDay, TTLPeriodWeek, TTLPeriodMonth:
+		return period, nil
+	default:
+		return "", fmt.Errorf("unknown period %q, expected day, week or month", name)
+	}
+}
+
+// ParseTTLDuration parses a duration as time.ParseDuration does, adding d
+// for days and w for weeks, so that 30d or 2w1d are accepted. Durations
+// that are not positive are rejected, since TTLs are only pushed forward.
+func ParseTTLDuration(text string) (time.Duration, error) {
+	var total time.Duration
+	rest := text
+
+	for _, unit := range []struct {
+		suffix string
+		length time.Duration
+	}{{"w", 7 * 24 * time.Hour}, {"d", 24 * time.Hour}} {
+		number, after, found := strings.Cut(rest, unit.suffix)
+		if !found {
+			continue
+		}
+		count, err := strconv.Atoi(number)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", text)
+		}
+		total += time.Duration(count) * unit.length
+		rest = after
+	}
+
+	if rest != "" {
+		duration, err := time.ParseDuration(rest)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", text)
+		}
+		total += duration
+	}
+
+	if text == "" || total == 0 {
+		return 0, fmt.Errorf("invalid duration %q", text)
+	}
+	if total < 0 {
+		return 0, fmt.Errorf("duration %q is negative, expected a positive duration", text)
+	}
+	return total, nil
+}
+
+// TTLBucket counts the items expiring in the period starting at Start.
+type TTLBucket struct {
+	Start time.Time
+	Count int
+}
+
+// TTLReport builds a histogram of expiry dates. Expired counts the items
+// whose TTL has passed but which are still in the table; DynamoDB deletes
+// expired items in the background, typically within a few days.
+type TTLReport struct {
+	Period  TTLPeriod
+	Now     time.Time
+	Total   int
+	NoTTL   int
+	Expired int
+
+	counts map[time.Time]int
+}
+
+func NewTTLReport(period TTLPeriod, now time.Time) *TTLReport {
+	return &TTLReport{
+		Period: period,
+		Now:    now,
+		counts: make(map[time.Time]int),
+	}
+}
+
+// Add counts one item.
+func (r *TTLReport) Add(item map[string]types.AttributeValue) {
+	r.Total++
+
+	ttl, ok := itemTTL(item)
+	if !ok {
+		r.NoTTL++
+		return
+	}
+
+	expiry := time.Unix(ttl, 0).UTC()
+	if !expiry.After(r.Now) {
+		r.Expired++
+	}
+	r.counts[r.bucket(expiry)]++
+}
+
+// Buckets returns the counted periods in date order.
+func (r *TTLReport) Buckets() []TTLBucket {
+	buckets := make([]TTLBucket, 0, len(r.counts))
+	for start, count := range r.counts {
+		buckets = append(buckets, TTLBucket{Start: start, Count: count})
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Start.Before(buckets[j].Start)
+	})
+	return buckets
+}
+
+// bucket returns the start of the period containing t. Weeks start on
+// Monday.
+func (r *TTLReport) bucket(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch r.Period {
+	case TTLPeriodWeek:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case TTLPeriodMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// TTLOptions configures a TTLWriter. By is how far Extend pushes each TTL
+// forward. With DryRun set, items are counted but nothing is written.
+type TTLOptions struct {
+	Table  string
+	By     time.Duration
+	DryRun bool
+}
+
+// TTLSummary counts what a TTLWriter did. Skipped items had no TTL or, for
+// Purge, had not expired; Conflicts had their TTL changed by another writer
+// after they were read and were left alone.
+type TTLSummary struct {
+	Updated   int
+	Skipped   int
+	Conflicts int
+	Failed    int
+}
+
+// TTLWriter extends or purges items one conditional request at a time. Each
+// request only succeeds while the item's ttl still holds the value that was
+// read.
+type TTLWriter struct {
+	client  *Client
+	ctx     context.Context
+	options TTLOptions
+	limiter *capacityLimiter
+	summary TTLSummary
+
+	now func() time.Time
+}
+
+func (c *Client) NewTTLWriter(ctx context.Context, options TTLOptions) *TTLWriter {
+	return &TTLWriter{
+		client:  c,
+		ctx:     ctx,
+		options: options,
+		limiter: c.newCapacityLimiter(0),
+		now:     time.Now,
+	}
+}
+
+// Extend moves an item's TTL forward by options.By. Items without a TTL are
+// skipped. A failed write is logged and counted in the summary, so an error
+// is only returned when the context is cancelled or the table cannot be
+// described.
+func (w *TTLWriter) Extend(item map[string]types.AttributeValue) error {
+	ttl, ok := itemTTL(item)
+	if !ok {
+		w.summary.Skipped++
+		return nil
+	}
+
+	extended := ttl + int64(w.options.By/time.Second)
+
+	return w.write(item, func(key map[string]types.AttributeValue) (*types.ConsumedCapacity, error) {
+		output, err := w.client.api.UpdateItem(w.ctx, &awsdynamodb.UpdateItemInput{
+			TableName:                aws.String(w.options.Table),
+			Key:                      key,
+			UpdateExpression:         aws.String("SET #ttl = :new"),
+			ConditionExpression:      aws.String("#ttl = :old"),
+			ExpressionAttributeNames: map[string]string{"#ttl": ttlAttribute},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":old": item[ttlAttribute],
+				":new": &types.AttributeValueMemberN{Value: strconv.FormatInt(extended, 10)},
+			},
+			ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return output.ConsumedCapacity, nil
+	})
+}
+
+// Purge deletes an item whose TTL has passed. Items without a TTL, or whose
+// TTL is still in the future, are skipped.
+func (w *TTLWriter) Purge(item map[string]types.AttributeValue) error {
+	ttl, ok := itemTTL(item)
+	if !ok || ttl > w.now().Unix() {
+		w.summary.Skipped++
+		return nil
+	}
+
+	return w.write(item, func(key map[string]types.AttributeValue) (*types.ConsumedCapacity, error) {
+		output, err := w.client.api.DeleteItem(w.ctx, &awsdynamodb.DeleteItemInput{
+			TableName:                 aws.String(w.options.Table),
+			Key:                       key,
+			ConditionExpression:       aws.String("#ttl = :old"),
+			ExpressionAttributeNames:  map[string]string{"#ttl": ttlAttribute},
+			ExpressionAttributeValues: map[string]types.AttributeValue{":old": item[ttlAttribute]},
+			ReturnConsumedCapacity:    types.ReturnConsumedCapacityTotal,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return output.ConsumedCapacity, nil
+	})
+}
+
+// Summary reports what has been written so far.
+func (w *TTLWriter) Summary() TTLSummary {
+	return w.summary
+}
+
+// write sends request for item's key, as described by the table's key
+// schema.
+func (w *TTLWriter) write(item map[string]types.AttributeValue, request func(key map[string]types.AttributeValue) (*types.ConsumedCapacity, error)) error {
+	id := ""
+	if s, ok := item["id"].(*types.AttributeValueMemberS); ok {
+		id = s.Value
+	}
+
+	if w.options.DryRun {
+		w.summary.Updated++
+		return nil
+	}
+
+	schema, err := w.client.DescribeKeySchema(w.ctx, w.options.Table)
+	if err != nil {
+		return err
+	}
+	key, err := schema.Key(item)
+	if err != nil {
+		w.client.logger.Error("Item cannot be updated", "id", id, "error", err)
+		w.summary.Failed++
+		return nil
+	}
+
+	err = w.limiter.do(w.ctx, func() (*types.ConsumedCapacity, error) {
+		return request(key)
+	})
+
+	var conflict *types.ConditionalCheckFailedException
+	switch {
+	case err == nil:
+		w.summary.Updated++
+	case errors.As(err, &conflict):
+		w.client.logger.Warn("ttl changed since it was read, leaving it", "id", id)
+		w.summary.Conflicts++
+	case w.ctx.Err() != nil:
+		return w.ctx.Err()
+	default:
+		w.client.logger.Error("Failed to write item", "id", id, "error", err)
+		w.summary.Failed++
+	}
+
+	return nil
+}
+
+// itemTTL returns an item's ttl as Unix seconds. Like DynamoDB, it ignores
+// a ttl that is missing, not a number, or zero.
+func itemTTL(item map[string]types.AttributeValue) (int64, bool) {
+	n, ok := item[ttlAttribute].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0, false
+	}
+
+	ttl, err := strconv.ParseFloat(n.Value, 64)
+	if err != nil || ttl <= 0 {
+		return 0, false
+	}
+	return int64(ttl), true
+}
@@ -0,0 +1,196 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gkwa/bouncingbeaver/internal/memtable"
+)
+
+var ttlNow = time.Date(2025, 6, 18, 12, 0, 0, 0, time.UTC) // a Wednesday
+
+func expiring(id string, at time.Time) map[string]types.AttributeValue {
+	item := product(id)
+	item["ttl"] = &types.AttributeValueMemberN{Value: fmt.Sprint(at.Unix())}
+	return item
+}
+
+func TestParseTTLDuration(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected time.Duration
+	}{
+		{"30d", 30 * 24 * time.Hour},
+		{"2w1d", 15 * 24 * time.Hour},
+		{"1d12h", 36 * time.Hour},
+		{"90m", 90 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTTLDuration(tt.text)
+		if err != nil || got != tt.expected {
+			t.Errorf("ParseTTLDuration(%q) = %v, %v; expected %v", tt.text, got, err, tt.expected)
+		}
+	}
+
+	for _, text := range []string{"", "0d", "d", "1x", "1h2d", "-7d", "-1h"} {
+		if _, err := ParseTTLDuration(text); err == nil {
+			t.Errorf("Expected %q to be rejected", text)
+		}
+	}
+}
+
+func TestTTLReport(t *testing.T) {
+	report := NewTTLReport(TTLPeriodWeek, ttlNow)
+
+	report.Add(expiring("expired", ttlNow.Add(-time.Hour)))
+	report.Add(expiring("monday", time.Date(2025, 6, 16, 0, 0, 0, 0, time.UTC)))
+	report.Add(expiring("sunday", time.Date(2025, 6, 22, 23, 0, 0, 0, time.UTC)))
+	report.Add(expiring("next-week", time.Date(2025, 6, 23, 0, 0, 0, 0, time.UTC)))
+	report.Add(product("no-ttl"))
+
+	if report.Total != 5 || report.NoTTL != 1 || report.Expired != 2 {
+		t.Errorf("Unexpected totals: %+v", report)
+	}
+
+	got := fmt.Sprint(report.Buckets())
+	expected := fmt.Sprint([]TTLBucket{
+		{Start: time.Date(2025, 6, 16, 0, 0, 0, 0, time.UTC), Count: 3},
+		{Start: time.Date(2025, 6, 23, 0, 0, 0, 0, time.UTC), Count: 1},
+	})
+	if got != expected {
+		t.Errorf("Expected buckets %s, got %s", expected, got)
+	}
+}
+
+func newTTLWriter(fake *memtable.DB, options TTLOptions) *TTLWriter {
+	writer := NewClientWithAPI(fake).NewTTLWriter(context.Background(), options)
+	writer.now = func() time.Time { return ttlNow }
+	return writer
+}
+
+func TestTTLWriter_Extend(t *testing.T) {
+	fake := memtable.New()
+	fake.CreateTable("products", "id", "")
+	fake.Put("products", expiring("a", ttlNow))
+	fake.Put("products", product("no-ttl"))
+
+	writer := newTTLWriter(fake, TTLOptions{Table: "products", By: 30 * 24 * time.Hour})
+	for _, item := range fake.Items("products") {
+		if err := writer.Extend(item); err != nil {
+			t.Fatalf("Extend failed: %v", err)
+		}
+	}
+
+	if summary := writer.Summary(); summary.Updated != 1 || summary.Skipped != 1 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+
+	expected := fmt.Sprint(ttlNow.AddDate(0, 0, 30).Unix())
+	for _, item := range fake.Items("products") {
+		if ttl, ok := item["ttl"].(*types.AttributeValueMemberN); ok && ttl.Value != expected {
+			t.Errorf("Expected ttl %s, got %s", expected, ttl.Value)
+		}
+	}
+
+	// Extending from a stale read leaves the newer TTL alone
+	stale := expiring("a", ttlNow)
+	if err := writer.Extend(stale); err != nil {
+		t.Fatalf("Extend failed: %v", err)
+	}
+	if writer.Summary().Conflicts != 1 {
+		t.Errorf("Expected a conflict, got %+v", writer.Summary())
+	}
+}
+
+func TestTTLWriter_ExtendWhere(t *testing.T) {
+	fake := memtable.New()
+	fake.CreateTable("products", "id", "")
+	fake.Put("products", expiring("a", ttlNow))
+	fake.Put("products", expiring("b", ttlNow))
+
+	client := NewClientWithAPI(fake)
+	writer := newTTLWriter(fake, TTLOptions{Table: "products", By: 24 * time.Hour})
+
+	// --where is sent as the Scan's FilterExpression
+	scanner := client.ScanTable(context.Background(), QueryOptions{
+		Table:                     "products",
+		Filter:                    "id = :id",
+		ExpressionAttributeValues: map[string]types.AttributeValue{":id": &types.AttributeValueMemberS{Value: "b"}},
+	})
+	defer scanner.Close()
+	for scanner.Scan() {
+		if err := writer.Extend(scanner.Record().Item); err != nil {
+			t.Fatalf("Extend failed: %v", err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	if summary := writer.Summary(); summary.Updated != 1 || summary.Skipped != 0 {
+		t.Errorf("Expected only the matching item updated, got %+v", summary)
+	}
+
+	expected := map[string]int64{"a": ttlNow.Unix(), "b": ttlNow.AddDate(0, 0, 1).Unix()}
+	for _, item := range fake.Items("products") {
+		id := item["id"].(*types.AttributeValueMemberS).Value
+		if ttl, _ := itemTTL(item); ttl != expected[id] {
+			t.Errorf("Expected %s to have ttl %d, got %d", id, expected[id], ttl)
+		}
+	}
+}
+
+func TestTTLWriter_Purge(t *testing.T) {
+	fake := memtable.New()
+	fake.CreateTable("products", "id", "")
+	fake.Put("products", expiring("expired", ttlNow.Add(-time.Hour)))
+	fake.Put("products", expiring("extended", ttlNow.Add(time.Hour)))
+	fake.Put("products", expiring("current", ttlNow.Add(time.Hour)))
+	fake.Put("products", product("no-ttl"))
+
+	writer := newTTLWriter(fake, TTLOptions{Table: "products"})
+
+	// "extended" was expired when it was read but has since been extended
+	items := append(fake.Items("products"), expiring("extended", ttlNow.Add(-time.Hour)))
+	for _, item := range items {
+		if err := writer.Purge(item); err != nil {
+			t.Fatalf("Purge failed: %v", err)
+		}
+	}
+
+	if summary := writer.Summary(); summary.Updated != 1 || summary.Conflicts != 1 || summary.Skipped != 3 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+	if len(fake.Items("products")) != 3 {
+		t.Errorf("Expected only the expired item deleted, %d items left", len(fake.Items("products")))
+	}
+}
+
+func TestTTLWriter_SortKey(t *testing.T) {
+	fake := memtable.New()
+	fake.CreateTable("products", "id", "scrapedAt")
+	for i, at := range []time.Time{ttlNow.Add(-time.Hour), ttlNow.Add(time.Hour)} {
+		item := expiring("a", at)
+		item["scrapedAt"] = &types.AttributeValueMemberS{Value: fmt.Sprint(i)}
+		fake.Put("products", item)
+	}
+
+	writer := newTTLWriter(fake, TTLOptions{Table: "products"})
+	for _, item := range fake.Items("products") {
+		if err := writer.Purge(item); err != nil {
+			t.Fatalf("Purge failed: %v", err)
+		}
+	}
+
+	if summary := writer.Summary(); summary.Updated != 1 || summary.Skipped != 1 || summary.Failed != 0 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+	items := fake.Items("products")
+	if len(items) != 1 || items[0]["scrapedAt"].(*types.AttributeValueMemberS).Value != "1" {
+		t.Errorf("Expected only the expired version deleted, got %v", items)
+	}
+}
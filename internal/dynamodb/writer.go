@@ -0,0 +1,205 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gkwa/bouncingbeaver/internal/models"
+)
+
+const (
+	batchWriteSize = 25
+	batchRetries   = 8
+)
+
+// WriteOptions configures a BatchWriter. With DryRun set, batches are built
+// and counted but never sent.
+type WriteOptions struct {
+	Table  string
+	DryRun bool
+}
+
+// WriteSummary counts what a BatchWriter did. Failed items were rejected by
+// DynamoDB or still unprocessed after every retry.
+type WriteSummary struct {
+	Written int
+	Failed  int
+	Batches int
+	Retries int
+}
+
+// BatchWriter puts items into a table 25 at a time with BatchWriteItem,
+// retrying UnprocessedItems with exponential backoff.
+type BatchWriter struct {
+	client  *Client
+	ctx     context.Context
+	options WriteOptions
+	pending []types.WriteRequest
+	keys    map[string]bool
+	summary WriteSummary
+
+	sleep func(context.Context, time.Duration) error
+}
+
+func (c *Client) NewBatchWriter(ctx context.Context, options WriteOptions) *BatchWriter {
+	return &BatchWriter{
+		client:  c,
+		ctx:     ctx,
+		options: options,
+		keys:    make(map[string]bool),
+		sleep:   sleepContext,
+	}
+}
+
+// Put queues an item, sending the batch once it is full. BatchWriteItem
+// rejects a batch that names the same key twice, so an item whose key is
+// already queued sends the batch first. Keys follow the table's key schema;
+// a dry run without a connection cannot describe the table and so never
+// splits a batch.
+func (w *BatchWriter) Put(item map[string]types.AttributeValue) error {
+	key, err := w.itemKey(item)
+	if err != nil {
+		return err
+	}
+	if key != "" && w.keys[key] {
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	w.pending = append(w.pending, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}})
+	if key != "" {
+		w.keys[key] = true
+	}
+
+	if len(w.pending) == batchWriteSize {
+		return w.Flush()
+	}
+	return nil
+}
+
+// Flush sends any queued items. Items that cannot be written are counted as
+// failed rather than returned as an error; the error is reserved for a
+// cancelled context or a missing connection.
+func (w *BatchWriter) Flush() error {
+	if len(w.pending) == 0 {
+		return nil
+	}
+
+	batch := w.pending
+	w.pending = nil
+	w.keys = make(map[string]bool)
+	w.summary.Batches++
+
+	if w.options.DryRun {
+		w.client.logger.Info("Dry run: would write batch", "table", w.options.Table, "items", len(batch))
+		w.summary.Written += len(batch)
+		return nil
+	}
+
+	if w.client.api == nil {
+		return errNotConnected
+	}
+
+	backoff := throttleBackoff
+	for attempt := 1; ; attempt++ {
+		output, err := w.client.api.BatchWriteItem(w.ctx, &awsdynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]types.WriteRequest{w.options.Table: batch},
+		})
+
+		var unprocessed []types.WriteRequest
+		var exceeded *types.ProvisionedThroughputExceededException
+		switch {
+		case err == nil:
+			unprocessed = output.UnprocessedItems[w.options.Table]
+			w.summary.Written += len(batch) - len(unprocessed)
+		case errors.As(err, &exceeded):
+			unprocessed = batch
+		case w.ctx.Err() != nil:
+			return w.ctx.Err()
+		default:
+			w.client.logger.Error("Batch write failed", "table", w.options.Table, "items", len(batch), "error", err)
+			w.summary.Failed += len(batch)
+			return nil
+		}
+
+		if len(unprocessed) == 0 {
+			return nil
+		}
+		if attempt == batchRetries {
+			w.client.logger.Error("Items still unprocessed after retries", "table", w.options.Table, "items", len(unprocessed), "attempts", attempt)
+			w.summary.Failed += len(unprocessed)
+			return nil
+		}
+
+		w.client.logger.Debug("Retrying unprocessed items", "items", len(unprocessed), "attempt", attempt, "delay", backoff)
+		w.summary.Retries++
+		if err := w.sleep(w.ctx, backoff); err != nil {
+			return err
+		}
+		backoff = min(backoff*2, throttleBackoffCap)
+		batch = unprocessed
+	}
+}
+
+// itemKey returns item's key as a string, or "" when it cannot be known:
+// without a connection, or when the item lacks a key attribute, which
+// DynamoDB reports when the batch is sent.
+func (w *BatchWriter) itemKey(item map[string]types.AttributeValue) (string, error) {
+	if w.client.api == nil {
+		return "", nil
+	}
+
+	schema, err := w.client.DescribeKeySchema(w.ctx, w.options.Table)
+	if err != nil {
+		return "", err
+	}
+	key, err := schema.Key(item)
+	if err != nil {
+		return "", nil
+	}
+	return keyString(key), nil
+}
+
+// Summary reports what has been written so far.
+func (w *BatchWriter) Summary() WriteSummary {
+	return w.summary
+}
+
+// RemarshalItem converts an item to a models.Product and back, so that only
+// the attributes the model knows are written and each has the type its
+// dynamodbav tag gives it. Attributes the item did not have are left out
+// rather than written as zero values; a zero ttl would expire the item.
+// rawHtml keeps the value it was read with, so binary HTML written by
+// migrate-html is not turned back into a string.
+func (c *Client) RemarshalItem(item map[string]types.AttributeValue) (map[string]types.AttributeValue, error) {
+	var product models.Product
+	if err := attributevalue.UnmarshalMap(normalizeRawHTML(item), &product); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal item: %w", err)
+	}
+
+	remarshaled, err := attributevalue.MarshalMap(product)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal product %s: %w", product.ID, err)
+	}
+
+	for name := range remarshaled {
+		if _, ok := item[name]; !ok {
+			delete(remarshaled, name)
+		}
+	}
+	if rawHTML, ok := item[rawHTMLAttribute]; ok {
+		remarshaled[rawHTMLAttribute] = rawHTML
+	}
+
+	if len(remarshaled) == 0 {
+		return nil, fmt.Errorf("item has none of the product attributes")
+	}
+
+	return remarshaled, nil
+}
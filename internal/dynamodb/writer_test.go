@@ -0,0 +1,229 @@
+package dynamodb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gkwa/bouncingbeaver/internal/memtable"
+)
+
+// newTestWriter returns a writer to a fake products table that records its
+// backoff delays instead of sleeping.
+func newTestWriter(fake *memtable.DB, options WriteOptions) (*BatchWriter, *[]time.Duration) {
+	writer := NewClientWithAPI(fake).NewBatchWriter(context.Background(), options)
+
+	var sleeps []time.Duration
+	writer.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return nil
+	}
+
+	return writer, &sleeps
+}
+
+func product(id string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"id":   &types.AttributeValueMemberS{Value: id},
+		"name": &types.AttributeValueMemberS{Value: "Product " + id},
+	}
+}
+
+func TestBatchWriter_RetriesUnprocessedItems(t *testing.T) {
+	fake := memtable.New()
+	fake.CreateTable("products", "id", "")
+	fake.BatchWriteLimit = 10
+
+	writer, sleeps := newTestWriter(fake, WriteOptions{Table: "products"})
+	for i := 0; i < 60; i++ {
+		if err := writer.Put(product(fmt.Sprint(i))); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	summary := writer.Summary()
+	if summary.Written != 60 || summary.Failed != 0 || summary.Batches != 3 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+	if len(fake.Items("products")) != 60 {
+		t.Errorf("Expected 60 items in the table, got %d", len(fake.Items("products")))
+	}
+
+	// Two full batches need two retries each, the last batch of 10 none
+	if summary.Retries != 4 || len(*sleeps) != 4 {
+		t.Errorf("Expected 4 retries, got %d (%v)", summary.Retries, *sleeps)
+	}
+	if (*sleeps)[0] != throttleBackoff || (*sleeps)[1] != 2*throttleBackoff {
+		t.Errorf("Expected exponential backoff, got %v", *sleeps)
+	}
+}
+
+func TestBatchWriter_GivesUpOnUnprocessedItems(t *testing.T) {
+	fake := memtable.New()
+	fake.CreateTable("products", "id", "")
+	fake.Fail = func(operation string, input interface{}) error {
+		if operation != "BatchWriteItem" {
+			return nil
+		}
+		return &types.ProvisionedThroughputExceededException{}
+	}
+
+	writer, _ := newTestWriter(fake, WriteOptions{Table: "products"})
+	writer.Put(product("a"))
+	writer.Put(product("b"))
+	if err := writer.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	summary := writer.Summary()
+	if summary.Failed != 2 || summary.Written != 0 || fake.Calls("BatchWriteItem") != batchRetries {
+		t.Errorf("Expected 2 failed items after %d attempts, got %+v and %d calls", batchRetries, summary, fake.Calls("BatchWriteItem"))
+	}
+}
+
+func TestBatchWriter_CountsRejectedBatch(t *testing.T) {
+	fake := memtable.New()
+	fake.CreateTable("products", "id", "")
+	fake.Fail = func(operation string, input interface{}) error {
+		if operation != "BatchWriteItem" {
+			return nil
+		}
+		return errors.New("ValidationException: item too large")
+	}
+
+	writer, _ := newTestWriter(fake, WriteOptions{Table: "products"})
+	writer.Put(product("a"))
+	writer.Flush()
+
+	if summary := writer.Summary(); summary.Failed != 1 || summary.Retries != 0 {
+		t.Errorf("Expected one failed item without retries, got %+v", summary)
+	}
+}
+
+func TestBatchWriter_SplitsDuplicateIDs(t *testing.T) {
+	fake := memtable.New()
+	fake.CreateTable("products", "id", "")
+
+	writer, _ := newTestWriter(fake, WriteOptions{Table: "products"})
+	first := product("a")
+	second := product("a")
+	second["name"] = &types.AttributeValueMemberS{Value: "Renamed"}
+
+	writer.Put(first)
+	writer.Put(product("b"))
+	writer.Put(second)
+	writer.Flush()
+
+	if summary := writer.Summary(); summary.Batches != 2 || summary.Written != 3 {
+		t.Errorf("Expected the repeated id to start a new batch, got %+v", summary)
+	}
+
+	items := fake.Items("products")
+	if name := items[0]["name"].(*types.AttributeValueMemberS).Value; name != "Renamed" {
+		t.Errorf("Expected the later write to win, got %s", name)
+	}
+}
+
+func TestBatchWriter_SortKey(t *testing.T) {
+	fake := memtable.New()
+	fake.CreateTable("products", "id", "scrapedAt")
+
+	writer, _ := newTestWriter(fake, WriteOptions{Table: "products"})
+	for _, scrapedAt := range []string{"1", "2", "1"} {
+		item := product("a")
+		item["scrapedAt"] = &types.AttributeValueMemberS{Value: scrapedAt}
+		writer.Put(item)
+	}
+	writer.Flush()
+
+	// Only the repeated id and sort key starts a new batch
+	if summary := writer.Summary(); summary.Batches != 2 || summary.Written != 3 || summary.Failed != 0 {
+		t.Errorf("Expected one batch per repeated key, got %+v", summary)
+	}
+	if len(fake.Items("products")) != 2 {
+		t.Errorf("Expected two items, got %d", len(fake.Items("products")))
+	}
+}
+
+func TestBatchWriter_DryRun(t *testing.T) {
+	writer := NewClient().NewBatchWriter(context.Background(), WriteOptions{Table: "products", DryRun: true})
+	for i := 0; i < 30; i++ {
+		writer.Put(product(fmt.Sprint(i)))
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+
+	if summary := writer.Summary(); summary.Written != 30 || summary.Batches != 2 {
+		t.Errorf("Unexpected dry run summary: %+v", summary)
+	}
+}
+
+func TestRemarshalItem(t *testing.T) {
+	client := NewClient()
+
+	item := map[string]types.AttributeValue{
+		"id":      &types.AttributeValueMemberS{Value: "a"},
+		"rawHtml": &types.AttributeValueMemberS{Value: "eJw="},
+		"ttl":     &types.AttributeValueMemberN{Value: "1750000000"},
+		"unknown": &types.AttributeValueMemberS{Value: "dropped"},
+	}
+
+	remarshaled, err := client.RemarshalItem(item)
+	if err != nil {
+		t.Fatalf("RemarshalItem failed: %v", err)
+	}
+
+	if len(remarshaled) != 3 {
+		t.Errorf("Expected id, rawHtml and ttl only, got %v", remarshaled)
+	}
+	if _, ok := remarshaled["RawHTMLExtracted"]; ok {
+		t.Error("Expected RawHTMLExtracted not to be written")
+	}
+	if ttl, ok := remarshaled["ttl"].(*types.AttributeValueMemberN); !ok || ttl.Value != "1750000000" {
+		t.Errorf("Expected ttl to stay a number, got %#v", remarshaled["ttl"])
+	}
+
+	if _, err := client.RemarshalItem(map[string]types.AttributeValue{"ttl": &types.AttributeValueMemberS{Value: "soon"}}); err == nil {
+		t.Error("Expected an error for a ttl that is not a number")
+	}
+}
+
+func TestBatchWriter_KeepsBinaryHTML(t *testing.T) {
+	fake := memtable.New()
+	fake.CreateTable("products", "id", "")
+
+	compressed := []byte{0x28, 0xb5, 0x2f, 0xfd, 0x01}
+	item := product("zstd")
+	item["rawHtml"] = &types.AttributeValueMemberB{Value: compressed}
+
+	client := NewClientWithAPI(fake)
+	remarshaled, err := client.RemarshalItem(item)
+	if err != nil {
+		t.Fatalf("RemarshalItem failed: %v", err)
+	}
+
+	writer, _ := newTestWriter(fake, WriteOptions{Table: "products"})
+	if err := writer.Put(remarshaled); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	items := fake.Items("products")
+	if len(items) != 1 {
+		t.Fatalf("Expected 1 item, got %d", len(items))
+	}
+	written, ok := items[0]["rawHtml"].(*types.AttributeValueMemberB)
+	if !ok || !bytes.Equal(written.Value, compressed) {
+		t.Errorf("Expected rawHtml to be written as the original binary, got %#v", items[0]["rawHtml"])
+	}
+}
@@ -0,0 +1,409 @@
+package expression
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// maxInOperands is the most values DynamoDB accepts on the right of IN.
+const maxInOperands = 100
+
+// Condition is a parsed condition or filter expression.
+type Condition struct {
+	expression string
+	root       node
+}
+
+// ParseCondition parses a condition or filter expression, replacing #name and
+// :value placeholders from names and values. Placeholders that are used but
+// not defined are reported as errors.
+func ParseCondition(expression string, names map[string]string, values map[string]types.AttributeValue) (*Condition, error) {
+	if strings.TrimSpace(expression) == "" {
+		return nil, &Error{expression, 0, "expression is empty"}
+	}
+
+	p, err := newParser(expression, names, values)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.errorAt(t, fmt.Sprintf("unexpected %s", t))
+	}
+
+	return &Condition{expression: expression, root: root}, nil
+}
+
+// Matches reports whether item satisfies the condition.
+func (c *Condition) Matches(item map[string]types.AttributeValue) bool {
+	return c.root.eval(item)
+}
+
+func (c *Condition) String() string {
+	return c.expression
+}
+
+// KeyTerm is one term of a key condition: Operator is a comparison other
+// than <>, BETWEEN or begins_with, applied to a top-level Attribute.
+type KeyTerm struct {
+	Attribute string
+	Operator  string
+}
+
+// KeyTerms lists the terms of a condition used as a KeyConditionExpression,
+// which may only join comparisons, BETWEEN and begins_with of a top-level
+// attribute with values by AND. Other conditions are reported as errors;
+// checking which attributes the terms name is left to the caller, which
+// knows the key schema.
+func (c *Condition) KeyTerms() ([]KeyTerm, error) {
+	var terms []KeyTerm
+	var collect func(n node) error
+	collect = func(n node) error {
+		var term KeyTerm
+		var path Path
+		var values []operand
+		switch n := n.(type) {
+		case andNode:
+			if err := collect(n.left); err != nil {
+				return err
+			}
+			return collect(n.right)
+		case comparisonNode:
+			left, ok := n.left.(pathOperand)
+			switch {
+			case n.op == "<>":
+				return &Error{c.expression, 0, "<> is not supported in a key condition"}
+			case !ok:
+				return &Error{c.expression, 0, "a comparison in a key condition must test an attribute"}
+			}
+			term.Operator, path, values = n.op, left.path, []operand{n.right}
+		case betweenNode:
+			value, ok := n.value.(pathOperand)
+			if !ok {
+				return &Error{c.expression, 0, "BETWEEN in a key condition must test an attribute"}
+			}
+			term.Operator, path, values = "BETWEEN", value.path, []operand{n.lower, n.upper}
+		case beginsWithNode:
+			term.Operator, path, values = "begins_with", n.path, []operand{n.prefix}
+		default:
+			return &Error{c.expression, 0, "a key condition may only join comparisons, BETWEEN and begins_with with AND"}
+		}
+
+		if len(path) != 1 || path[0].IsIndex {
+			return &Error{c.expression, 0, fmt.Sprintf("key condition on %s must name a top-level attribute", path)}
+		}
+		for _, value := range values {
+			if _, ok := value.(valueOperand); !ok {
+				return &Error{c.expression, 0, fmt.Sprintf("key condition on %s must compare with a value", path)}
+			}
+		}
+		term.Attribute = path[0].Name
+		terms = append(terms, term)
+		return nil
+	}
+
+	if err := collect(c.root); err != nil {
+		return nil, err
+	}
+	return terms, nil
+}
+
+type parser struct {
+	expression string
+	tokens     []token
+	pos        int
+	names      map[string]string
+	values     map[string]types.AttributeValue
+
+	// partiql switches to PartiQL's syntax: literals and ? parameters
+	// instead of placeholders, and case-insensitive function names.
+	partiql    bool
+	parameters []types.AttributeValue
+	used       int
+}
+
+func newParser(expression string, names map[string]string, values map[string]types.AttributeValue) (*parser, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+	return &parser{expression: expression, tokens: tokens, names: names, values: values}, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) peekAt(offset int) token {
+	if p.pos+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+offset]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// punct reports whether the next token is the given punctuation.
+func (p *parser) punct(text string) bool {
+	t := p.peek()
+	return t.kind == tokenPunct && t.text == text
+}
+
+// isFunction reports whether t calls the named function.
+func (p *parser) isFunction(t token, name string) bool {
+	if t.kind != tokenIdentifier || p.peekAt(1).text != "(" {
+		return false
+	}
+	if p.partiql {
+		return strings.EqualFold(t.text, name)
+	}
+	return t.text == name
+}
+
+func (p *parser) expect(punct string) error {
+	t := p.next()
+	if t.kind != tokenPunct || t.text != punct {
+		return p.errorAt(t, fmt.Sprintf("expected %q, found %s", punct, t))
+	}
+	return nil
+}
+
+func (p *parser) errorAt(t token, message string) error {
+	return &Error{p.expression, t.offset, message}
+}
+
+// keyword reports whether the next token is the given keyword, which like
+// DynamoDB's keywords is matched case-insensitively.
+func (p *parser) keyword(word string) bool {
+	t := p.peek()
+	return t.kind == tokenIdentifier && strings.EqualFold(t.text, word)
+}
+
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		p.next()
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (node, error) {
+	left, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("AND") {
+		p.next()
+		right, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) not() (node, error) {
+	if p.keyword("NOT") {
+		p.next()
+		operand, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.peek()
+
+	if p.punct("(") {
+		p.next()
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	}
+
+	if t.kind == tokenIdentifier && p.peekAt(1).text == "(" && !p.isFunction(t, "size") {
+		return p.function()
+	}
+
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.peek().kind == tokenComparator:
+		op := p.next().text
+		if op == "!=" {
+			op = "<>"
+		}
+		right, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		return comparisonNode{op, left, right}, nil
+	case p.keyword("BETWEEN"):
+		p.next()
+		lower, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		if !p.keyword("AND") {
+			return nil, p.errorAt(p.peek(), fmt.Sprintf("expected AND in BETWEEN, found %s", p.peek()))
+		}
+		p.next()
+		upper, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		return betweenNode{left, lower, upper}, nil
+	case p.keyword("IN"):
+		in := p.next()
+		closing := ")"
+		if p.partiql && p.punct("[") {
+			closing = "]"
+			p.next()
+		} else if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		var candidates []operand
+		for {
+			candidate, err := p.operand()
+			if err != nil {
+				return nil, err
+			}
+			candidates = append(candidates, candidate)
+			if p.peek().text != "," {
+				break
+			}
+			p.next()
+		}
+		if err := p.expect(closing); err != nil {
+			return nil, err
+		}
+		if len(candidates) > maxInOperands {
+			return nil, p.errorAt(in, fmt.Sprintf("IN accepts at most %d operands, got %d", maxInOperands, len(candidates)))
+		}
+		return inNode{left, candidates}, nil
+	case p.partiql && p.keyword("IS"):
+		return p.is(left)
+	default:
+		return nil, p.errorAt(p.peek(), fmt.Sprintf("expected a comparison, BETWEEN or IN, found %s", p.peek()))
+	}
+}
+
+func (p *parser) function() (node, error) {
+	name := p.next()
+	p.next() // (
+
+	path, err := p.path()
+	if err != nil {
+		return nil, err
+	}
+
+	function := name.text
+	if p.partiql {
+		function = strings.ToLower(function)
+	}
+
+	var result node
+	switch function {
+	case "attribute_exists":
+		result = existsNode{path, true}
+	case "attribute_not_exists":
+		result = existsNode{path, false}
+	case "attribute_type", "begins_with", "contains":
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		argument, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		switch function {
+		case "attribute_type":
+			value, ok := argument.(valueOperand)
+			s, isString := value.value.(*types.AttributeValueMemberS)
+			if !ok || !isString || !validType(s.Value) {
+				return nil, p.errorAt(name, "attribute_type expects a value naming one of S, SS, N, NS, B, BS, BOOL, NULL, L or M")
+			}
+			result = typeNode{path, s.Value}
+		case "begins_with":
+			result = beginsWithNode{path, argument}
+		default:
+			result = containsNode{path, argument}
+		}
+	default:
+		return nil, p.errorAt(name, fmt.Sprintf("unknown function %s", name.text))
+	}
+
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (p *parser) operand() (operand, error) {
+	t := p.peek()
+
+	switch {
+	case p.partiql:
+		if literal, ok, err := p.literal(); ok || err != nil {
+			return literal, err
+		}
+	case t.kind == tokenValue:
+		p.next()
+		value, ok := p.values[t.text]
+		if !ok {
+			return nil, p.errorAt(t, fmt.Sprintf("expression attribute value %s is not defined", t.text))
+		}
+		return valueOperand{value}, nil
+	}
+
+	if p.isFunction(t, "size") {
+		p.next()
+		p.next()
+		path, err := p.path()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return sizeOperand{path}, nil
+	}
+
+	path, err := p.path()
+	if err != nil {
+		return nil, err
+	}
+	return pathOperand{path}, nil
+}
@@ -0,0 +1,183 @@
+package expression
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func testItem() map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"id":      &types.AttributeValueMemberS{Value: "product-042"},
+		"price":   &types.AttributeValueMemberN{Value: "19.90"},
+		"ttl":     &types.AttributeValueMemberN{Value: "1750000000"},
+		"status":  &types.AttributeValueMemberS{Value: "active"},
+		"rawHtml": &types.AttributeValueMemberB{Value: []byte{0x1f, 0x8b, 8, 0}},
+		"tags":    &types.AttributeValueMemberSS{Value: []string{"sale", "new"}},
+		"sizes":   &types.AttributeValueMemberNS{Value: []string{"8", "10"}},
+		"deleted": &types.AttributeValueMemberBOOL{Value: false},
+		"info": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"brand": &types.AttributeValueMemberS{Value: "Acme"},
+			"ratings": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+				&types.AttributeValueMemberN{Value: "4"},
+				&types.AttributeValueMemberN{Value: "5"},
+			}},
+		}},
+	}
+}
+
+func TestCondition_Matches(t *testing.T) {
+	names := map[string]string{"#s": "status", "#ttl": "ttl", "#r": "ratings"}
+	values := map[string]types.AttributeValue{
+		":active": &types.AttributeValueMemberS{Value: "active"},
+		":p":      &types.AttributeValueMemberS{Value: "product-"},
+		":low":    &types.AttributeValueMemberN{Value: "10"},
+		":high":   &types.AttributeValueMemberN{Value: "2e1"},
+		":price":  &types.AttributeValueMemberN{Value: "19.9"},
+		":sale":   &types.AttributeValueMemberS{Value: "sale"},
+		":ten":    &types.AttributeValueMemberN{Value: "10"},
+		":two":    &types.AttributeValueMemberN{Value: "2"},
+		":five":   &types.AttributeValueMemberN{Value: "5"},
+		":tags":   &types.AttributeValueMemberSS{Value: []string{"new", "sale"}},
+		":gzip":   &types.AttributeValueMemberB{Value: []byte{0x1f, 0x8b}},
+		":B":      &types.AttributeValueMemberS{Value: "B"},
+		":SS":     &types.AttributeValueMemberS{Value: "SS"},
+		":acme":   &types.AttributeValueMemberS{Value: "Acme"},
+		":now":    &types.AttributeValueMemberN{Value: "1760000000"},
+	}
+
+	tests := []struct {
+		expression string
+		expected   bool
+	}{
+		{"#s = :active", true},
+		{"#s <> :active", false},
+		{"price = :price", true},
+		{"price BETWEEN :low AND :high", true},
+		{"price > :high", false},
+		{"#ttl < :now AND begins_with(id, :p)", true},
+		{"begins_with(rawHtml, :gzip)", true},
+		{"begins_with(id, :sale)", false},
+		{"contains(tags, :sale)", true},
+		{"contains(sizes, :ten)", true},
+		{"contains(info.#r, :five)", true},
+		{"contains(id, :sale)", false},
+		{"tags = :tags", true},
+		{"size(tags) = :two AND size(info.#r) = :two", true},
+		{"size(id) > :ten", true},
+		{"attribute_type(rawHtml, :B)", true},
+		{"attribute_type(tags, :B)", false},
+		{"attribute_type(tags, :SS)", true},
+		{"attribute_exists(info.brand) AND attribute_not_exists(info.color)", true},
+		{"attribute_exists(info.#r[1]) AND attribute_not_exists(info.#r[2])", true},
+		{"info.#r[1] = :five", true},
+		{"info.brand IN (:sale, :acme)", true},
+		{"#s IN (:sale, :p)", false},
+		{"missing = :active", false},
+		{"missing <> :active", true},
+		{"missing < :active", false},
+		{"id < :ten", false},
+		{"NOT #s = :active OR deleted = :active", false},
+		{"not (#s = :sale) and (price < :low or price > :ten)", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			condition, err := ParseCondition(tt.expression, names, values)
+			if err != nil {
+				t.Fatalf("ParseCondition failed: %v", err)
+			}
+			if got := condition.Matches(testItem()); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseCondition_Errors(t *testing.T) {
+	values := map[string]types.AttributeValue{
+		":v": &types.AttributeValueMemberS{Value: "x"},
+		":n": &types.AttributeValueMemberN{Value: "1"},
+	}
+
+	tests := []string{
+		"",
+		"a =",
+		"a = :v AND",
+		"#missing = :v",
+		"a = :missing",
+		"(a = :v",
+		"a :v",
+		"a BETWEEN :v :v",
+		"a IN ()",
+		"attribute_type(a, :n)",
+		"unknown_function(a)",
+		"a = :v extra",
+		"a[x] = :v",
+		"a = $",
+	}
+
+	for _, expression := range tests {
+		t.Run(expression, func(t *testing.T) {
+			_, err := ParseCondition(expression, nil, values)
+			var expressionErr *Error
+			if !errors.As(err, &expressionErr) {
+				t.Errorf("Expected an expression error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseCondition_InLimit(t *testing.T) {
+	values := map[string]types.AttributeValue{":v": &types.AttributeValueMemberS{Value: "x"}}
+
+	expression := "a IN (:v"
+	for i := 1; i < maxInOperands; i++ {
+		expression += ", :v"
+	}
+	if _, err := ParseCondition(expression+")", nil, values); err != nil {
+		t.Errorf("Expected %d operands to be accepted, got %v", maxInOperands, err)
+	}
+	if _, err := ParseCondition(expression+", :v)", nil, values); err == nil {
+		t.Errorf("Expected more than %d operands to be rejected", maxInOperands)
+	}
+}
+
+func TestCondition_KeyTerms(t *testing.T) {
+	values := map[string]types.AttributeValue{":v": &types.AttributeValueMemberS{Value: "x"}}
+	names := map[string]string{"#pk": "pk"}
+
+	condition, err := ParseCondition("#pk = :v AND (sk BETWEEN :v AND :v)", names, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	terms, err := condition.KeyTerms()
+	if err != nil {
+		t.Fatalf("KeyTerms failed: %v", err)
+	}
+	if got := fmt.Sprint(terms); got != "[{pk =} {sk BETWEEN}]" {
+		t.Errorf("Unexpected terms %s", got)
+	}
+
+	for _, expression := range []string{
+		"pk = :v OR sk = :v",
+		"NOT pk = :v",
+		"pk <> :v",
+		"pk = sk",
+		":v = pk",
+		"info.id = :v",
+		"attribute_exists(pk)",
+		"pk IN (:v)",
+		"size(pk) = :v",
+	} {
+		condition, err := ParseCondition(expression, nil, values)
+		if err != nil {
+			t.Fatalf("ParseCondition(%q) failed: %v", expression, err)
+		}
+		if _, err := condition.KeyTerms(); err == nil {
+			t.Errorf("Expected %q to be rejected as a key condition", expression)
+		}
+	}
+}
@@ -0,0 +1,417 @@
+package expression
+
+import (
+	"bytes"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type node interface {
+	eval(item map[string]types.AttributeValue) bool
+}
+
+// operand produces the value one side of a comparison refers to, and false
+// when the item has no such value.
+type operand interface {
+	resolve(item map[string]types.AttributeValue) (types.AttributeValue, bool)
+}
+
+type valueOperand struct{ value types.AttributeValue }
+
+func (o valueOperand) resolve(map[string]types.AttributeValue) (types.AttributeValue, bool) {
+	return o.value, true
+}
+
+type pathOperand struct{ path Path }
+
+func (o pathOperand) resolve(item map[string]types.AttributeValue) (types.AttributeValue, bool) {
+	return o.path.Resolve(item)
+}
+
+type sizeOperand struct{ path Path }
+
+func (o sizeOperand) resolve(item map[string]types.AttributeValue) (types.AttributeValue, bool) {
+	value, ok := o.path.Resolve(item)
+	if !ok {
+		return nil, false
+	}
+
+	var size int
+	switch v := value.(type) {
+	case *types.AttributeValueMemberS:
+		size = utf8.RuneCountInString(v.Value)
+	case *types.AttributeValueMemberB:
+		size = len(v.Value)
+	case *types.AttributeValueMemberSS:
+		size = len(v.Value)
+	case *types.AttributeValueMemberNS:
+		size = len(v.Value)
+	case *types.AttributeValueMemberBS:
+		size = len(v.Value)
+	case *types.AttributeValueMemberL:
+		size = len(v.Value)
+	case *types.AttributeValueMemberM:
+		size = len(v.Value)
+	default:
+		return nil, false
+	}
+
+	return &types.AttributeValueMemberN{Value: strconv.Itoa(size)}, true
+}
+
+type orNode struct{ left, right node }
+
+func (n orNode) eval(item map[string]types.AttributeValue) bool {
+	return n.left.eval(item) || n.right.eval(item)
+}
+
+type andNode struct{ left, right node }
+
+func (n andNode) eval(item map[string]types.AttributeValue) bool {
+	return n.left.eval(item) && n.right.eval(item)
+}
+
+type notNode struct{ operand node }
+
+func (n notNode) eval(item map[string]types.AttributeValue) bool {
+	return !n.operand.eval(item)
+}
+
+type comparisonNode struct {
+	op          string
+	left, right operand
+}
+
+// eval follows DynamoDB in treating a comparison with a missing operand as
+// false, except for <> which is then true.
+func (n comparisonNode) eval(item map[string]types.AttributeValue) bool {
+	left, leftOK := n.left.resolve(item)
+	right, rightOK := n.right.resolve(item)
+	if !leftOK || !rightOK {
+		return n.op == "<>"
+	}
+
+	switch n.op {
+	case "=":
+		return Equal(left, right)
+	case "<>":
+		return !Equal(left, right)
+	}
+
+	c, ok := Compare(left, right)
+	if !ok {
+		return false
+	}
+	switch n.op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+type betweenNode struct{ value, lower, upper operand }
+
+func (n betweenNode) eval(item map[string]types.AttributeValue) bool {
+	value, ok := n.value.resolve(item)
+	if !ok {
+		return false
+	}
+	lower, ok := n.lower.resolve(item)
+	if !ok {
+		return false
+	}
+	upper, ok := n.upper.resolve(item)
+	if !ok {
+		return false
+	}
+
+	low, ok := Compare(value, lower)
+	if !ok || low < 0 {
+		return false
+	}
+	high, ok := Compare(value, upper)
+	return ok && high <= 0
+}
+
+type inNode struct {
+	value      operand
+	candidates []operand
+}
+
+func (n inNode) eval(item map[string]types.AttributeValue) bool {
+	value, ok := n.value.resolve(item)
+	if !ok {
+		return false
+	}
+	for _, candidate := range n.candidates {
+		if c, ok := candidate.resolve(item); ok && Equal(value, c) {
+			return true
+		}
+	}
+	return false
+}
+
+type existsNode struct {
+	path   Path
+	exists bool
+}
+
+func (n existsNode) eval(item map[string]types.AttributeValue) bool {
+	_, ok := n.path.Resolve(item)
+	return ok == n.exists
+}
+
+type typeNode struct {
+	path     Path
+	typeName string
+}
+
+func (n typeNode) eval(item map[string]types.AttributeValue) bool {
+	value, ok := n.path.Resolve(item)
+	return ok && TypeName(value) == n.typeName
+}
+
+type beginsWithNode struct {
+	path   Path
+	prefix operand
+}
+
+func (n beginsWithNode) eval(item map[string]types.AttributeValue) bool {
+	value, ok := n.path.Resolve(item)
+	if !ok {
+		return false
+	}
+	prefix, ok := n.prefix.resolve(item)
+	if !ok {
+		return false
+	}
+
+	switch v := value.(type) {
+	case *types.AttributeValueMemberS:
+		p, ok := prefix.(*types.AttributeValueMemberS)
+		return ok && strings.HasPrefix(v.Value, p.Value)
+	case *types.AttributeValueMemberB:
+		p, ok := prefix.(*types.AttributeValueMemberB)
+		return ok && bytes.HasPrefix(v.Value, p.Value)
+	}
+	return false
+}
+
+type containsNode struct {
+	path    Path
+	operand operand
+}
+
+func (n containsNode) eval(item map[string]types.AttributeValue) bool {
+	value, ok := n.path.Resolve(item)
+	if !ok {
+		return false
+	}
+	operand, ok := n.operand.resolve(item)
+	if !ok {
+		return false
+	}
+
+	switch v := value.(type) {
+	case *types.AttributeValueMemberS:
+		s, ok := operand.(*types.AttributeValueMemberS)
+		return ok && strings.Contains(v.Value, s.Value)
+	case *types.AttributeValueMemberB:
+		b, ok := operand.(*types.AttributeValueMemberB)
+		return ok && bytes.Contains(v.Value, b.Value)
+	case *types.AttributeValueMemberSS:
+		for _, element := range v.Value {
+			if Equal(&types.AttributeValueMemberS{Value: element}, operand) {
+				return true
+			}
+		}
+	case *types.AttributeValueMemberNS:
+		for _, element := range v.Value {
+			if Equal(&types.AttributeValueMemberN{Value: element}, operand) {
+				return true
+			}
+		}
+	case *types.AttributeValueMemberBS:
+		for _, element := range v.Value {
+			if Equal(&types.AttributeValueMemberB{Value: element}, operand) {
+				return true
+			}
+		}
+	case *types.AttributeValueMemberL:
+		for _, element := range v.Value {
+			if Equal(element, operand) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TypeName returns the DynamoDB type descriptor of value, such as "S" or
+// "NS".
+func TypeName(value types.AttributeValue) string {
+	switch value.(type) {
+	case *types.AttributeValueMemberS:
+		return "S"
+	case *types.AttributeValueMemberN:
+		return "N"
+	case *types.AttributeValueMemberB:
+		return "B"
+	case *types.AttributeValueMemberSS:
+		return "SS"
+	case *types.AttributeValueMemberNS:
+		return "NS"
+	case *types.AttributeValueMemberBS:
+		return "BS"
+	case *types.AttributeValueMemberBOOL:
+		return "BOOL"
+	case *types.AttributeValueMemberNULL:
+		return "NULL"
+	case *types.AttributeValueMemberL:
+		return "L"
+	case *types.AttributeValueMemberM:
+		return "M"
+	}
+	return ""
+}
+
+func validType(name string) bool {
+	switch name {
+	case "S", "SS", "N", "NS", "B", "BS", "BOOL", "NULL", "L", "M":
+		return true
+	}
+	return false
+}
+
+// Compare orders two scalars of the same type: numbers numerically, strings
+// and binaries byte by byte. Other combinations cannot be ordered.
+func Compare(a, b types.AttributeValue) (int, bool) {
+	switch x := a.(type) {
+	case *types.AttributeValueMemberN:
+		y, ok := b.(*types.AttributeValueMemberN)
+		if !ok {
+			return 0, false
+		}
+		return compareNumbers(x.Value, y.Value)
+	case *types.AttributeValueMemberS:
+		y, ok := b.(*types.AttributeValueMemberS)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(x.Value, y.Value), true
+	case *types.AttributeValueMemberB:
+		y, ok := b.(*types.AttributeValueMemberB)
+		if !ok {
+			return 0, false
+		}
+		return bytes.Compare(x.Value, y.Value), true
+	}
+	return 0, false
+}
+
+func compareNumbers(a, b string) (int, bool) {
+	x, ok := new(big.Rat).SetString(a)
+	if !ok {
+		return 0, false
+	}
+	y, ok := new(big.Rat).SetString(b)
+	if !ok {
+		return 0, false
+	}
+	return x.Cmp(y), true
+}
+
+// Equal reports whether two attribute values are equal the way DynamoDB
+// compares them: numbers by value, sets regardless of order, and lists and
+// maps element by element.
+func Equal(a, b types.AttributeValue) bool {
+	switch x := a.(type) {
+	case *types.AttributeValueMemberS:
+		y, ok := b.(*types.AttributeValueMemberS)
+		return ok && x.Value == y.Value
+	case *types.AttributeValueMemberN:
+		y, ok := b.(*types.AttributeValueMemberN)
+		if !ok {
+			return false
+		}
+		c, ok := compareNumbers(x.Value, y.Value)
+		return ok && c == 0
+	case *types.AttributeValueMemberB:
+		y, ok := b.(*types.AttributeValueMemberB)
+		return ok && bytes.Equal(x.Value, y.Value)
+	case *types.AttributeValueMemberBOOL:
+		y, ok := b.(*types.AttributeValueMemberBOOL)
+		return ok && x.Value == y.Value
+	case *types.AttributeValueMemberNULL:
+		_, ok := b.(*types.AttributeValueMemberNULL)
+		return ok
+	case *types.AttributeValueMemberSS:
+		y, ok := b.(*types.AttributeValueMemberSS)
+		return ok && sameElements(len(x.Value), len(y.Value), func(i, j int) bool { return x.Value[i] == y.Value[j] })
+	case *types.AttributeValueMemberNS:
+		y, ok := b.(*types.AttributeValueMemberNS)
+		return ok && sameElements(len(x.Value), len(y.Value), func(i, j int) bool {
+			c, ok := compareNumbers(x.Value[i], y.Value[j])
+			return ok && c == 0
+		})
+	case *types.AttributeValueMemberBS:
+		y, ok := b.(*types.AttributeValueMemberBS)
+		return ok && sameElements(len(x.Value), len(y.Value), func(i, j int) bool { return bytes.Equal(x.Value[i], y.Value[j]) })
+	case *types.AttributeValueMemberL:
+		y, ok := b.(*types.AttributeValueMemberL)
+		if !ok || len(x.Value) != len(y.Value) {
+			return false
+		}
+		for i := range x.Value {
+			if !Equal(x.Value[i], y.Value[i]) {
+				return false
+			}
+		}
+		return true
+	case *types.AttributeValueMemberM:
+		y, ok := b.(*types.AttributeValueMemberM)
+		if !ok || len(x.Value) != len(y.Value) {
+			return false
+		}
+		for key, value := range x.Value {
+			other, ok := y.Value[key]
+			if !ok || !Equal(value, other) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// sameElements reports whether two sets of sizes n and m hold the same
+// elements, given an equality test between their elements.
+func sameElements(n, m int, equal func(i, j int) bool) bool {
+	if n != m {
+		return false
+	}
+	matched := make([]bool, m)
+	for i := 0; i < n; i++ {
+		found := false
+		for j := 0; j < m; j++ {
+			if !matched[j] && equal(i, j) {
+				matched[j] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
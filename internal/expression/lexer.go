@@ -0,0 +1,165 @@
+// Package expression parses and evaluates DynamoDB condition, filter and
+// projection expressions against items held in memory, so that the
+// expression strings sent to DynamoDB can be applied to local data.
+package expression
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF        tokenKind = iota
+	tokenIdentifier           // name, keyword or function
+	tokenName                 // #placeholder
+	tokenValue                // :placeholder
+	tokenNumber               // list index, or a number in PartiQL
+	tokenString               // 'string' in PartiQL
+	tokenQuoted               // "identifier" in PartiQL
+	tokenComparator           // = <> != < <= > >=
+	tokenPunct                // ( ) [ ] . , * ? - ;
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	offset int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of expression"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// Error reports a problem with an expression and where in it the problem was
+// found.
+type Error struct {
+	Expression string
+	Offset     int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("invalid expression %q at offset %d: %s", e.Expression, e.Offset, e.Message)
+}
+
+func tokenize(expression string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(expression); {
+		c := expression[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '#' || c == ':':
+			j := i + 1
+			for j < len(expression) && isWordChar(expression[j]) {
+				j++
+			}
+			if j == i+1 {
+				return nil, &Error{expression, i, fmt.Sprintf("%q must be followed by a placeholder name", c)}
+			}
+			kind := tokenName
+			if c == ':' {
+				kind = tokenValue
+			}
+			tokens = append(tokens, token{kind, expression[i:j], i})
+			i = j
+		case isLetter(c) || c == '_':
+			j := i + 1
+			for j < len(expression) && isWordChar(expression[j]) {
+				j++
+			}
+			tokens = append(tokens, token{tokenIdentifier, expression[i:j], i})
+			i = j
+		case c >= '0' && c <= '9':
+			j := scanNumber(expression, i)
+			tokens = append(tokens, token{tokenNumber, expression[i:j], i})
+			i = j
+		case c == '\'' || c == '"':
+			text, j, ok := scanQuoted(expression, i)
+			if !ok {
+				return nil, &Error{expression, i, "unterminated quoted text"}
+			}
+			kind := tokenString
+			if c == '"' {
+				kind = tokenQuoted
+			}
+			tokens = append(tokens, token{kind, text, i})
+			i = j
+		case c == '<' || c == '>' || c == '=' || c == '!' && i+1 < len(expression) && expression[i+1] == '=':
+			j := i + 1
+			if j < len(expression) && (expression[j] == '=' || c == '<' && expression[j] == '>') {
+				j++
+			}
+			tokens = append(tokens, token{tokenComparator, expression[i:j], i})
+			i = j
+		case strings.IndexByte("()[].,*?-;", c) >= 0:
+			tokens = append(tokens, token{tokenPunct, string(c), i})
+			i++
+		default:
+			return nil, &Error{expression, i, fmt.Sprintf("unexpected character %q", c)}
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, offset: len(expression)}), nil
+}
+
+// scanNumber returns the end of the number starting at start: digits with an
+// optional fraction and exponent.
+func scanNumber(expression string, start int) int {
+	digits := func(i int) int {
+		for i < len(expression) && expression[i] >= '0' && expression[i] <= '9' {
+			i++
+		}
+		return i
+	}
+
+	i := digits(start)
+	if i+1 < len(expression) && expression[i] == '.' && expression[i+1] >= '0' && expression[i+1] <= '9' {
+		i = digits(i + 1)
+	}
+	if i < len(expression) && (expression[i] == 'e' || expression[i] == 'E') {
+		j := i + 1
+		if j < len(expression) && (expression[j] == '+' || expression[j] == '-') {
+			j++
+		}
+		if end := digits(j); end > j {
+			i = end
+		}
+	}
+	return i
+}
+
+// scanQuoted reads text enclosed in the quote character at start, in which a
+// doubled quote stands for one, and returns the text and where it ends.
+func scanQuoted(expression string, start int) (string, int, bool) {
+	quote := expression[start]
+
+	var b strings.Builder
+	for i := start + 1; i < len(expression); i++ {
+		if expression[i] != quote {
+			b.WriteByte(expression[i])
+			continue
+		}
+		if i+1 < len(expression) && expression[i+1] == quote {
+			b.WriteByte(quote)
+			i++
+			continue
+		}
+		return b.String(), i + 1, true
+	}
+	return "", 0, false
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isWordChar(c byte) bool {
+	return isLetter(c) || c >= '0' && c <= '9' || c == '_'
+}
@@ -0,0 +1,180 @@
+package expression
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Select is a parsed PartiQL SELECT statement of the form DynamoDB's
+// ExecuteStatement accepts:
+//
+//	SELECT * | path, ... FROM table[."index"] [WHERE condition]
+type Select struct {
+	Table string
+	Index string
+
+	// Where is nil when the statement has no WHERE clause, and Projection
+	// is nil for SELECT *.
+	Where      *Condition
+	Projection *Projection
+}
+
+// ParseSelect parses a PartiQL SELECT statement. Values are written inline
+// as 'strings', numbers, TRUE, FALSE and NULL, or as ? markers taken in
+// order from parameters. Names may be "quoted", which is needed for names
+// that are PartiQL keywords or hold characters such as '-'.
+func ParseSelect(statement string, parameters []types.AttributeValue) (*Select, error) {
+	tokens, err := tokenize(statement)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{expression: statement, tokens: tokens, partiql: true, parameters: parameters}
+
+	if !p.keyword("SELECT") {
+		return nil, p.errorAt(p.peek(), fmt.Sprintf("expected SELECT, found %s", p.peek()))
+	}
+	p.next()
+
+	var result Select
+	if p.punct("*") {
+		p.next()
+	} else {
+		projection, err := p.projection()
+		if err != nil {
+			return nil, err
+		}
+		result.Projection = projection
+	}
+
+	if !p.keyword("FROM") {
+		return nil, p.errorAt(p.peek(), fmt.Sprintf("expected FROM, found %s", p.peek()))
+	}
+	p.next()
+
+	if result.Table, err = p.name("table"); err != nil {
+		return nil, err
+	}
+	if p.punct(".") {
+		p.next()
+		if result.Index, err = p.name("index"); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.keyword("WHERE") {
+		p.next()
+		start := p.peek().offset
+		root, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		where := strings.TrimSpace(statement[start:p.peek().offset])
+		result.Where = &Condition{expression: where, root: root}
+	}
+
+	if p.punct(";") {
+		p.next()
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.errorAt(t, fmt.Sprintf("unexpected %s", t))
+	}
+	if p.used != len(parameters) {
+		return nil, &Error{statement, len(statement), fmt.Sprintf("statement uses %d parameters but %d were given", p.used, len(parameters))}
+	}
+
+	return &result, nil
+}
+
+// name reads a table or index name, which may be quoted.
+func (p *parser) name(what string) (string, error) {
+	t := p.next()
+	if t.kind != tokenIdentifier && t.kind != tokenQuoted {
+		return "", p.errorAt(t, fmt.Sprintf("expected a %s name, found %s", what, t))
+	}
+	return t.text, nil
+}
+
+// literal reads a PartiQL value or ? parameter, reporting false when the
+// next token is neither.
+func (p *parser) literal() (operand, bool, error) {
+	t := p.peek()
+
+	switch {
+	case t.kind == tokenString:
+		p.next()
+		return valueOperand{&types.AttributeValueMemberS{Value: t.text}}, true, nil
+	case t.kind == tokenNumber, p.punct("-") && p.peekAt(1).kind == tokenNumber:
+		text := p.next().text
+		if text == "-" {
+			text += p.next().text
+		}
+		if _, ok := new(big.Rat).SetString(text); !ok {
+			return nil, false, p.errorAt(t, fmt.Sprintf("invalid number %s", text))
+		}
+		return valueOperand{&types.AttributeValueMemberN{Value: text}}, true, nil
+	case p.punct("?"):
+		p.next()
+		if p.used >= len(p.parameters) {
+			return nil, false, p.errorAt(t, fmt.Sprintf("statement uses more than the %d parameters given", len(p.parameters)))
+		}
+		value := p.parameters[p.used]
+		p.used++
+		return valueOperand{value}, true, nil
+	case p.keyword("TRUE"), p.keyword("FALSE"):
+		p.next()
+		return valueOperand{&types.AttributeValueMemberBOOL{Value: strings.EqualFold(t.text, "TRUE")}}, true, nil
+	case p.keyword("NULL"):
+		p.next()
+		return valueOperand{&types.AttributeValueMemberNULL{Value: true}}, true, nil
+	}
+
+	return nil, false, nil
+}
+
+// is reads the rest of IS [NOT] MISSING or IS [NOT] NULL.
+func (p *parser) is(left operand) (node, error) {
+	p.next()
+
+	negate := p.keyword("NOT")
+	if negate {
+		p.next()
+	}
+
+	var result node
+	switch {
+	case p.keyword("MISSING"):
+		result = missingNode{left}
+	case p.keyword("NULL"):
+		result = nullNode{left}
+	default:
+		return nil, p.errorAt(p.peek(), fmt.Sprintf("expected MISSING or NULL after IS, found %s", p.peek()))
+	}
+	p.next()
+
+	if negate {
+		result = notNode{result}
+	}
+	return result, nil
+}
+
+type missingNode struct{ operand operand }
+
+func (n missingNode) eval(item map[string]types.AttributeValue) bool {
+	_, ok := n.operand.resolve(item)
+	return !ok
+}
+
+// nullNode follows PartiQL in treating a missing value as null too.
+type nullNode struct{ operand operand }
+
+func (n nullNode) eval(item map[string]types.AttributeValue) bool {
+	value, ok := n.operand.resolve(item)
+	if !ok {
+		return true
+	}
+	_, isNull := value.(*types.AttributeValueMemberNULL)
+	return isNull
+}
@@ -0,0 +1,103 @@
+package expression
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestParseSelect_Where(t *testing.T) {
+	parameters := []types.AttributeValue{
+		&types.AttributeValueMemberS{Value: "active"},
+		&types.AttributeValueMemberN{Value: "20"},
+	}
+
+	tests := []struct {
+		statement  string
+		parameters []types.AttributeValue
+		expected   bool
+	}{
+		{`SELECT * FROM products WHERE status = 'active'`, nil, true},
+		{`select * from "products" where "status" != 'active'`, nil, false},
+		{`SELECT * FROM products WHERE price > 10 AND price <= 19.9`, nil, true},
+		{`SELECT * FROM products WHERE price BETWEEN -5 AND 2e1`, nil, true},
+		{`SELECT * FROM products WHERE info.brand IN ['Acme', 'Other']`, nil, true},
+		{`SELECT * FROM products WHERE info.brand IN ('Other')`, nil, false},
+		{`SELECT * FROM products WHERE info.ratings[1] = 5`, nil, true},
+		{`SELECT * FROM products WHERE BEGINS_WITH(id, 'product-') AND Contains(tags, 'sale')`, nil, true},
+		{`SELECT * FROM products WHERE size(tags) = 2 AND attribute_type(rawHtml, 'B')`, nil, true},
+		{`SELECT * FROM products WHERE deleted = FALSE`, nil, true},
+		{`SELECT * FROM products WHERE color IS MISSING AND id IS NOT MISSING`, nil, true},
+		{`SELECT * FROM products WHERE color IS NULL AND NOT (status IS NULL)`, nil, true},
+		{`SELECT * FROM products WHERE status = ? AND price < ?;`, parameters, true},
+		{`SELECT * FROM products WHERE "note" = 'it''s'`, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.statement, func(t *testing.T) {
+			statement, err := ParseSelect(tt.statement, tt.parameters)
+			if err != nil {
+				t.Fatalf("ParseSelect failed: %v", err)
+			}
+			if statement.Table != "products" || statement.Projection != nil {
+				t.Errorf("Unexpected statement: %+v", statement)
+			}
+			if got := statement.Where.Matches(testItem()); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseSelect_Projection(t *testing.T) {
+	statement, err := ParseSelect(`SELECT id, "status", info.ratings[0] FROM "product-table"."by-status"`, nil)
+	if err != nil {
+		t.Fatalf("ParseSelect failed: %v", err)
+	}
+
+	if statement.Table != "product-table" || statement.Index != "by-status" || statement.Where != nil {
+		t.Errorf("Unexpected statement: %+v", statement)
+	}
+	if statement.Projection.String() != `id, "status", info.ratings[0]` {
+		t.Errorf("Unexpected projection: %s", statement.Projection)
+	}
+
+	result := statement.Projection.Apply(testItem())
+	if len(result) != 3 {
+		t.Errorf("Expected 3 attributes, got %v", result)
+	}
+	ratings := result["info"].(*types.AttributeValueMemberM).Value["ratings"].(*types.AttributeValueMemberL).Value
+	if len(ratings) != 1 || ratings[0].(*types.AttributeValueMemberN).Value != "4" {
+		t.Errorf("Expected the first rating, got %v", ratings)
+	}
+}
+
+func TestParseSelect_Errors(t *testing.T) {
+	tests := []string{
+		"",
+		"SELECT FROM products",
+		"SELECT * products",
+		"SELECT * FROM",
+		"SELECT * FROM products WHERE",
+		"SELECT * FROM products WHERE a = :v",
+		"SELECT * FROM products WHERE a = #n",
+		"SELECT * FROM products WHERE a = ?",
+		"SELECT * FROM products WHERE a IS 'x'",
+		"SELECT * FROM products WHERE a = 'unterminated",
+		"SELECT a, a.b FROM products",
+		"SELECT * FROM products ORDER BY a",
+		"UPDATE products SET a = 1",
+	}
+
+	for _, statement := range tests {
+		t.Run(statement, func(t *testing.T) {
+			if _, err := ParseSelect(statement, nil); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+
+	if _, err := ParseSelect("SELECT * FROM products", []types.AttributeValue{&types.AttributeValueMemberS{Value: "x"}}); err == nil {
+		t.Error("Expected unused parameters to be rejected")
+	}
+}
@@ -0,0 +1,111 @@
+package expression
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// PathElement is one step of a document path: a map key, or a list index
+// when IsIndex is set.
+type PathElement struct {
+	Name    string
+	Index   int
+	IsIndex bool
+}
+
+// Path is a document path such as info.ratings[0].score, with placeholders
+// already replaced by the names they stand for.
+type Path []PathElement
+
+func (p Path) String() string {
+	var b strings.Builder
+	for i, element := range p {
+		switch {
+		case element.IsIndex:
+			fmt.Fprintf(&b, "[%d]", element.Index)
+		case i > 0:
+			b.WriteString("." + element.Name)
+		default:
+			b.WriteString(element.Name)
+		}
+	}
+	return b.String()
+}
+
+// Resolve returns the value at the path, and false when the item has no
+// value there.
+func (p Path) Resolve(item map[string]types.AttributeValue) (types.AttributeValue, bool) {
+	if len(p) == 0 || p[0].IsIndex {
+		return nil, false
+	}
+
+	value, ok := item[p[0].Name]
+	if !ok {
+		return nil, false
+	}
+
+	for _, element := range p[1:] {
+		switch v := value.(type) {
+		case *types.AttributeValueMemberM:
+			if element.IsIndex {
+				return nil, false
+			}
+			if value, ok = v.Value[element.Name]; !ok {
+				return nil, false
+			}
+		case *types.AttributeValueMemberL:
+			if !element.IsIndex || element.Index >= len(v.Value) {
+				return nil, false
+			}
+			value = v.Value[element.Index]
+		default:
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+// path reads a document path: names or #placeholders separated by dots,
+// each optionally followed by [index]. In PartiQL names may instead be
+// "quoted", and there are no placeholders.
+func (p *parser) path() (Path, error) {
+	var path Path
+
+	for {
+		t := p.next()
+		switch {
+		case t.kind == tokenIdentifier, t.kind == tokenQuoted && p.partiql:
+			path = append(path, PathElement{Name: t.text})
+		case t.kind == tokenName && !p.partiql:
+			name, ok := p.names[t.text]
+			if !ok {
+				return nil, p.errorAt(t, fmt.Sprintf("expression attribute name %s is not defined", t.text))
+			}
+			path = append(path, PathElement{Name: name})
+		default:
+			return nil, p.errorAt(t, fmt.Sprintf("expected an attribute name, found %s", t))
+		}
+
+		for p.punct("[") {
+			p.next()
+			index := p.next()
+			n, err := strconv.Atoi(index.text)
+			if index.kind != tokenNumber || err != nil {
+				return nil, p.errorAt(index, fmt.Sprintf("expected a list index, found %s", index))
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			path = append(path, PathElement{Index: n, IsIndex: true})
+		}
+
+		if !p.punct(".") {
+			return path, nil
+		}
+		p.next()
+	}
+}
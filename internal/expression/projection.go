@@ -0,0 +1,183 @@
+package expression
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Projection is a parsed projection expression: the attributes, or parts of
+// them, to keep from each item.
+type Projection struct {
+	expression string
+	paths      []Path
+	mask       *mask
+}
+
+// mask records which parts of a value a projection keeps. A mask with all set
+// keeps the whole value; otherwise it keeps only the listed map keys or list
+// indexes.
+type mask struct {
+	all     bool
+	fields  map[string]*mask
+	indexes map[int]*mask
+}
+
+// ParseProjection parses a comma-separated list of document paths, replacing
+// #name placeholders from names. Paths that overlap, such as a and a.b, are
+// rejected as DynamoDB rejects them.
+func ParseProjection(expression string, names map[string]string) (*Projection, error) {
+	if strings.TrimSpace(expression) == "" {
+		return nil, &Error{expression, 0, "expression is empty"}
+	}
+
+	p, err := newParser(expression, names, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	projection, err := p.projection()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.errorAt(t, fmt.Sprintf("expected \",\" between paths, found %s", t))
+	}
+
+	return projection, nil
+}
+
+// projection reads a comma-separated list of paths.
+func (p *parser) projection() (*Projection, error) {
+	start := p.peek().offset
+	projection := &Projection{mask: &mask{}}
+
+	for {
+		t := p.peek()
+		path, err := p.path()
+		if err != nil {
+			return nil, err
+		}
+		if !projection.mask.add(path) {
+			return nil, p.errorAt(t, fmt.Sprintf("path %s overlaps another path in the projection", path))
+		}
+		projection.paths = append(projection.paths, path)
+
+		if !p.punct(",") {
+			break
+		}
+		p.next()
+	}
+
+	projection.expression = strings.TrimSpace(p.expression[start:p.peek().offset])
+	return projection, nil
+}
+
+// Paths returns the document paths the projection keeps, in the order they
+// were given.
+func (p *Projection) Paths() []Path {
+	return p.paths
+}
+
+// Apply returns a copy of item holding only the projected attributes. List
+// elements kept from the middle of a list are packed together in index
+// order, as DynamoDB returns them.
+func (p *Projection) Apply(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	result := make(map[string]types.AttributeValue)
+	for name, m := range p.mask.fields {
+		value, ok := item[name]
+		if !ok {
+			continue
+		}
+		if projected, ok := m.apply(value); ok {
+			result[name] = projected
+		}
+	}
+	return result
+}
+
+func (p *Projection) String() string {
+	return p.expression
+}
+
+// add marks path as kept and reports false when it overlaps a path already
+// added.
+func (m *mask) add(path Path) bool {
+	node := m
+	for _, element := range path {
+		if node.all {
+			return false
+		}
+		if element.IsIndex {
+			if node.indexes == nil {
+				node.indexes = make(map[int]*mask)
+			}
+			if node.indexes[element.Index] == nil {
+				node.indexes[element.Index] = &mask{}
+			}
+			node = node.indexes[element.Index]
+		} else {
+			if node.fields == nil {
+				node.fields = make(map[string]*mask)
+			}
+			if node.fields[element.Name] == nil {
+				node.fields[element.Name] = &mask{}
+			}
+			node = node.fields[element.Name]
+		}
+	}
+
+	if node.all || len(node.fields) > 0 || len(node.indexes) > 0 {
+		return false
+	}
+	node.all = true
+	return true
+}
+
+func (m *mask) apply(value types.AttributeValue) (types.AttributeValue, bool) {
+	if m.all {
+		return value, true
+	}
+
+	switch v := value.(type) {
+	case *types.AttributeValueMemberM:
+		projected := make(map[string]types.AttributeValue)
+		for name, child := range m.fields {
+			element, ok := v.Value[name]
+			if !ok {
+				continue
+			}
+			if kept, ok := child.apply(element); ok {
+				projected[name] = kept
+			}
+		}
+		if len(projected) == 0 {
+			return nil, false
+		}
+		return &types.AttributeValueMemberM{Value: projected}, true
+	case *types.AttributeValueMemberL:
+		indexes := make([]int, 0, len(m.indexes))
+		for index := range m.indexes {
+			indexes = append(indexes, index)
+		}
+		sort.Ints(indexes)
+
+		var projected []types.AttributeValue
+		for _, index := range indexes {
+			if index >= len(v.Value) {
+				continue
+			}
+			if kept, ok := m.indexes[index].apply(v.Value[index]); ok {
+				projected = append(projected, kept)
+			}
+		}
+		if len(projected) == 0 {
+			return nil, false
+		}
+		return &types.AttributeValueMemberL{Value: projected}, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,62 @@
+package expression
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestProjection_Apply(t *testing.T) {
+	projection, err := ParseProjection("id, #s, info.#r[1], info.#r[0], info.color, missing", map[string]string{
+		"#s": "status",
+		"#r": "ratings",
+	})
+	if err != nil {
+		t.Fatalf("ParseProjection failed: %v", err)
+	}
+
+	result := projection.Apply(testItem())
+	expected := map[string]types.AttributeValue{
+		"id":     &types.AttributeValueMemberS{Value: "product-042"},
+		"status": &types.AttributeValueMemberS{Value: "active"},
+		"info": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"ratings": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+				&types.AttributeValueMemberN{Value: "4"},
+				&types.AttributeValueMemberN{Value: "5"},
+			}},
+		}},
+	}
+	if !Equal(&types.AttributeValueMemberM{Value: result}, &types.AttributeValueMemberM{Value: expected}) {
+		t.Errorf("Unexpected projection: %v", result)
+	}
+
+	if paths := projection.Paths(); len(paths) != 6 || paths[2].String() != "info.ratings[1]" {
+		t.Errorf("Unexpected paths: %v", paths)
+	}
+}
+
+func TestProjection_CompactsLists(t *testing.T) {
+	projection, err := ParseProjection("info.ratings[1], info.ratings[7], info.brand.first", nil)
+	if err != nil {
+		t.Fatalf("ParseProjection failed: %v", err)
+	}
+
+	result := projection.Apply(testItem())
+	ratings := result["info"].(*types.AttributeValueMemberM).Value["ratings"].(*types.AttributeValueMemberL).Value
+	if len(ratings) != 1 || ratings[0].(*types.AttributeValueMemberN).Value != "5" {
+		t.Errorf("Expected only the second rating, got %v", ratings)
+	}
+	if _, ok := result["info"].(*types.AttributeValueMemberM).Value["brand"]; ok {
+		t.Error("Expected a path into a string to project nothing")
+	}
+}
+
+func TestParseProjection_Errors(t *testing.T) {
+	for _, expression := range []string{"", "a,", "a b", "a, a", "a, a.b", "a[0].b, a[0]", "#undefined", ":v"} {
+		t.Run(expression, func(t *testing.T) {
+			if _, err := ParseProjection(expression, nil); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+// Package itemsize measures items the way DynamoDB does when it enforces
+// the 400 KB item limit and charges capacity, so the size report and the
+// in-memory table agree on what an item costs.
+package itemsize
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Item returns the size of an item: each attribute's name and value.
+func Item(item map[string]types.AttributeValue) int {
+	size := 0
+	for name, value := range item {
+		size += len(name) + Value(value)
+	}
+	return size
+}
+
+// Value returns the size of a value without its name: strings by their
+// UTF-8 length, binaries by their length, numbers by their significant
+// digits, and lists and maps with a few bytes of overhead.
+func Value(value types.AttributeValue) int {
+	switch v := value.(type) {
+	case *types.AttributeValueMemberS:
+		return len(v.Value)
+	case *types.AttributeValueMemberN:
+		return numberSize(v.Value)
+	case *types.AttributeValueMemberB:
+		return len(v.Value)
+	case *types.AttributeValueMemberBOOL, *types.AttributeValueMemberNULL:
+		return 1
+	case *types.AttributeValueMemberSS:
+		size := 0
+		for _, s := range v.Value {
+			size += len(s)
+		}
+		return size
+	case *types.AttributeValueMemberNS:
+		size := 0
+		for _, n := range v.Value {
+			size += numberSize(n)
+		}
+		return size
+	case *types.AttributeValueMemberBS:
+		size := 0
+		for _, b := range v.Value {
+			size += len(b)
+		}
+		return size
+	case *types.AttributeValueMemberL:
+		// 3 bytes for the list and 1 for each element
+		size := 3
+		for _, element := range v.Value {
+			size += 1 + Value(element)
+		}
+		return size
+	case *types.AttributeValueMemberM:
+		// 3 bytes for the map and 1 for each entry, whose name counts too
+		size := 3
+		for name, element := range v.Value {
+			size += 1 + len(name) + Value(element)
+		}
+		return size
+	}
+	return 0
+}
+
+// numberSize counts one byte per two significant digits, plus one byte,
+// plus one more for a negative number. Leading and trailing zeros are not
+// significant, and the exponent of a number like 1.5E3 is not stored.
+func numberSize(n string) int {
+	n = strings.TrimSpace(n)
+	negative := strings.HasPrefix(n, "-")
+
+	mantissa := strings.TrimLeft(n, "+-")
+	if i := strings.IndexAny(mantissa, "eE"); i >= 0 {
+		mantissa = mantissa[:i]
+	}
+	digits := strings.Trim(strings.Replace(mantissa, ".", "", 1), "0")
+
+	significant := max(len(digits), 1)
+	size := (significant+1)/2 + 1
+	if negative {
+		size++
+	}
+	return size
+}
@@ -0,0 +1,51 @@
+package itemsize
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    types.AttributeValue
+		expected int
+	}{
+		{"string", &types.AttributeValueMemberS{Value: "héllo"}, 6},
+		{"number", &types.AttributeValueMemberN{Value: "123"}, 3},
+		{"trailing zeros", &types.AttributeValueMemberN{Value: "1000"}, 2},
+		{"negative decimal", &types.AttributeValueMemberN{Value: "-001.50"}, 3},
+		{"exponent", &types.AttributeValueMemberN{Value: "1.5E3"}, 2},
+		{"zero", &types.AttributeValueMemberN{Value: "0"}, 2},
+		{"binary", &types.AttributeValueMemberB{Value: []byte{1, 2, 3, 4}}, 4},
+		{"bool", &types.AttributeValueMemberBOOL{Value: true}, 1},
+		{"null", &types.AttributeValueMemberNULL{Value: true}, 1},
+		{"string set", &types.AttributeValueMemberSS{Value: []string{"ab", "cde"}}, 5},
+		{"list", &types.AttributeValueMemberL{Value: []types.AttributeValue{
+			&types.AttributeValueMemberS{Value: "a"},
+			&types.AttributeValueMemberN{Value: "1"},
+		}}, 8},
+		{"map", &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"key": &types.AttributeValueMemberS{Value: "v"},
+		}}, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Value(tt.value); got != tt.expected {
+				t.Errorf("Expected %d bytes, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestItem(t *testing.T) {
+	item := map[string]types.AttributeValue{
+		"id":  &types.AttributeValueMemberS{Value: "p1"},
+		"ttl": &types.AttributeValueMemberN{Value: "1750000000"},
+	}
+	if got := Item(item); got != (2+2)+(3+3) {
+		t.Errorf("Expected 10 bytes, got %d", got)
+	}
+}
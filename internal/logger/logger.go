@@ -19,6 +19,10 @@ func (l *Logger) Error(msg string, args ...interface{}) {
 	l.log(0, "ERROR", msg, args...)
 }
 
+func (l *Logger) Warn(msg string, args ...interface{}) {
+	l.log(0, "WARN", msg, args...)
+}
+
 func (l *Logger) Info(msg string, args ...interface{}) {
 	if l.level >= 1 {
 		l.log(1, "INFO", msg, args...)
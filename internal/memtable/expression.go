@@ -0,0 +1,358 @@
+package memtable
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gkwa/bouncingbeaver/internal/expression"
+)
+
+// Condition and key condition expressions are evaluated with
+// internal/expression. Update expressions are parsed here, as far as
+// the live commands use them: SET and REMOVE clauses on top-level
+// attributes.
+
+type token struct {
+	kind string // "name", "value", "op" or "punct"
+	text string
+}
+
+func tokenize(expression string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(expression); {
+		c := rune(expression[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == ':' || c == '#' || c == '_' || unicode.IsLetter(c):
+			j := i + 1
+			for j < len(expression) && (isNameChar(rune(expression[j]))) {
+				j++
+			}
+			kind := "name"
+			if c == ':' {
+				kind = "value"
+			}
+			tokens = append(tokens, token{kind: kind, text: expression[i:j]})
+			i = j
+		case strings.ContainsRune("<>=", c):
+			j := i + 1
+			if j < len(expression) && strings.ContainsRune("<>=", rune(expression[j])) {
+				j++
+			}
+			op := expression[i:j]
+			switch op {
+			case "=", "<", "<=", ">", ">=", "<>":
+			default:
+				return nil, validationError(fmt.Sprintf("Invalid expression: unexpected operator %s", op))
+			}
+			tokens = append(tokens, token{kind: "op", text: op})
+			i = j
+		case strings.ContainsRune("(),+-", c):
+			tokens = append(tokens, token{kind: "punct", text: string(c)})
+			i++
+		default:
+			return nil, validationError(fmt.Sprintf("Invalid expression: unexpected character %q", c))
+		}
+	}
+
+	return tokens, nil
+}
+
+func isNameChar(c rune) bool {
+	return c == '_' || c == '.' || c == '[' || c == ']' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
+
+// parser walks the tokens of one expression, resolving placeholders.
+type parser struct {
+	tokens []token
+	pos    int
+	names  map[string]string
+	values map[string]types.AttributeValue
+}
+
+func newParser(expression string, names map[string]string, values map[string]types.AttributeValue) (*parser, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, validationError("Invalid expression: the expression is empty")
+	}
+	return &parser{tokens: tokens, names: names, values: values}, nil
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() token {
+	if p.done() {
+		return token{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *parser) keyword(word string) bool {
+	if t := p.peek(); t.kind == "name" && strings.EqualFold(t.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if t := p.next(); t.text != text {
+		return validationError(fmt.Sprintf("Invalid expression: expected %s, found %q", text, t.text))
+	}
+	return nil
+}
+
+// path reads an attribute name, substituting #placeholders.
+func (p *parser) path() (string, error) {
+	t := p.next()
+	if t.kind != "name" {
+		return "", validationError(fmt.Sprintf("Invalid expression: expected an attribute name, found %q", t.text))
+	}
+
+	name := t.text
+	if strings.HasPrefix(name, "#") {
+		resolved, ok := p.names[name]
+		if !ok {
+			return "", validationError(fmt.Sprintf("Invalid expression: An expression attribute name used in the document path is not defined; attribute name: %s", name))
+		}
+		return resolved, nil
+	}
+
+	if strings.ContainsAny(name, ".[") {
+		return "", validationError(fmt.Sprintf("Invalid UpdateExpression: nested attribute path %s is not supported", name))
+	}
+	return name, nil
+}
+
+// value reads a :placeholder.
+func (p *parser) value() (types.AttributeValue, error) {
+	t := p.next()
+	if t.kind != "value" {
+		return nil, validationError(fmt.Sprintf("Invalid expression: expected an expression attribute value, found %q", t.text))
+	}
+
+	value, ok := p.values[t.text]
+	if !ok {
+		return nil, validationError(fmt.Sprintf("Invalid expression: An expression attribute value used in expression is not defined; attribute value: %s", t.text))
+	}
+	return value, nil
+}
+
+// keyCondition parses a Query's KeyConditionExpression and checks that it
+// is an equality on the partition key, optionally joined with one condition
+// on the sort key.
+func (t *table) keyCondition(keyExpression string, names map[string]string, values map[string]types.AttributeValue) (*expression.Condition, error) {
+	condition, err := expression.ParseCondition(keyExpression, names, values)
+	if err != nil {
+		return nil, validationError(err.Error())
+	}
+	terms, err := condition.KeyTerms()
+	if err != nil {
+		return nil, validationError(err.Error())
+	}
+
+	partition, sort := 0, 0
+	for _, term := range terms {
+		switch {
+		case term.Attribute == t.partitionKey && term.Operator == "=":
+			partition++
+		case term.Attribute == t.sortKey && t.sortKey != "":
+			sort++
+		default:
+			return nil, validationError(fmt.Sprintf("Query key condition not supported: %s %s", term.Attribute, term.Operator))
+		}
+	}
+
+	if partition != 1 || sort > 1 {
+		return nil, validationError("Query condition missed key schema element: " + t.partitionKey)
+	}
+	return condition, nil
+}
+
+// updateAction is one SET or REMOVE of a top-level attribute.
+type updateAction struct {
+	name  string
+	value func(item map[string]types.AttributeValue) (types.AttributeValue, error) // nil for REMOVE
+}
+
+func (a updateAction) apply(item map[string]types.AttributeValue) error {
+	if a.value == nil {
+		delete(item, a.name)
+		return nil
+	}
+
+	value, err := a.value(item)
+	if err != nil {
+		return err
+	}
+	item[a.name] = value
+	return nil
+}
+
+func parseUpdate(expression string, names map[string]string, values map[string]types.AttributeValue) ([]updateAction, error) {
+	p, err := newParser(expression, names, values)
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []updateAction
+	for !p.done() {
+		switch {
+		case p.keyword("SET"):
+			for {
+				name, err := p.path()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expect("="); err != nil {
+					return nil, err
+				}
+				value, err := p.setValue()
+				if err != nil {
+					return nil, err
+				}
+				actions = append(actions, updateAction{name: name, value: value})
+
+				if p.peek().text != "," {
+					break
+				}
+				p.next()
+			}
+		case p.keyword("REMOVE"):
+			for {
+				name, err := p.path()
+				if err != nil {
+					return nil, err
+				}
+				actions = append(actions, updateAction{name: name})
+
+				if p.peek().text != "," {
+					break
+				}
+				p.next()
+			}
+		default:
+			return nil, validationError(fmt.Sprintf("Invalid UpdateExpression: only SET and REMOVE are supported, found %q", p.peek().text))
+		}
+	}
+
+	return actions, nil
+}
+
+type operand func(item map[string]types.AttributeValue) (types.AttributeValue, error)
+
+// setValue reads the right-hand side of a SET action: an operand, or two
+// operands added or subtracted.
+func (p *parser) setValue() (operand, error) {
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+
+	sign := p.peek().text
+	if sign != "+" && sign != "-" {
+		return left, nil
+	}
+	p.next()
+
+	right, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(item map[string]types.AttributeValue) (types.AttributeValue, error) {
+		a, err := left(item)
+		if err != nil {
+			return nil, err
+		}
+		b, err := right(item)
+		if err != nil {
+			return nil, err
+		}
+		return arithmetic(a, sign, b)
+	}, nil
+}
+
+func (p *parser) operand() (operand, error) {
+	if p.peek().kind == "value" {
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		return func(map[string]types.AttributeValue) (types.AttributeValue, error) { return value, nil }, nil
+	}
+
+	if p.keyword("if_not_exists") {
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		name, err := p.path()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		fallback, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return func(item map[string]types.AttributeValue) (types.AttributeValue, error) {
+			if value, ok := item[name]; ok {
+				return value, nil
+			}
+			return fallback(item)
+		}, nil
+	}
+
+	name, err := p.path()
+	if err != nil {
+		return nil, err
+	}
+	return func(item map[string]types.AttributeValue) (types.AttributeValue, error) {
+		value, ok := item[name]
+		if !ok {
+			return nil, validationError(fmt.Sprintf("The provided expression refers to an attribute that does not exist in the item: %s", name))
+		}
+		return value, nil
+	}, nil
+}
+
+func arithmetic(a types.AttributeValue, sign string, b types.AttributeValue) (types.AttributeValue, error) {
+	an, aok := a.(*types.AttributeValueMemberN)
+	bn, bok := b.(*types.AttributeValueMemberN)
+	if !aok || !bok {
+		return nil, validationError("An operand in the update expression has an incorrect data type")
+	}
+
+	x, xok := parseNumber(an.Value)
+	y, yok := parseNumber(bn.Value)
+	if !xok || !yok {
+		return nil, validationError("An operand in the update expression is not a valid number")
+	}
+
+	if sign == "+" {
+		x.Add(x, y)
+	} else {
+		x.Sub(x, y)
+	}
+	return &types.AttributeValueMemberN{Value: formatNumber(x)}, nil
+}
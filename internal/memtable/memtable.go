@@ -0,0 +1,754 @@
+// Package memtable holds DynamoDB tables in memory and answers the DynamoDB
+// operations bouncingbeaver uses, so serve-dynamodb can serve items loaded
+// from files and live features can be tested without a network.
+// It covers key conditions, Limit and 1 MB pagination, parallel Scan
+// segments, consumed capacity, and UnprocessedItems from BatchWriteItem.
+// Key condition, condition, filter and projection expressions are evaluated
+// with internal/expression.
+// Secondary indexes are not supported.
+package memtable
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/gkwa/bouncingbeaver/internal/expression"
+	"github.com/gkwa/bouncingbeaver/internal/itemsize"
+)
+
+const (
+	defaultPageBytes = 1 << 20
+	maxBatchWrites   = 25
+)
+
+// DB holds tables in memory and implements the subset of the DynamoDB API
+// that internal/dynamodb depends on.
+type DB struct {
+	// BatchWriteLimit caps how many write requests a BatchWriteItem call
+	// applies; the rest are returned as UnprocessedItems. Zero applies all.
+	BatchWriteLimit int
+
+	// MaxPageBytes is the size at which Scan and Query end a page, 1 MB
+	// when zero.
+	MaxPageBytes int
+
+	// Fail is called before each operation with its name and input. A
+	// non-nil error is returned in place of running the operation. It runs
+	// with the DB locked, so it must not call the DB's methods.
+	Fail func(operation string, input interface{}) error
+
+	mu     sync.Mutex
+	tables map[string]*table
+	calls  map[string]int
+}
+
+type table struct {
+	name         string
+	partitionKey string
+	sortKey      string
+	items        map[string]map[string]types.AttributeValue
+	created      time.Time
+}
+
+// New returns a DB with no tables.
+func New() *DB {
+	return &DB{
+		tables: make(map[string]*table),
+		calls:  make(map[string]int),
+	}
+}
+
+// CreateTable adds an empty table keyed on partitionKey and, unless it is
+// empty, sortKey.
+func (db *DB) CreateTable(name, partitionKey, sortKey string) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.tables[name] = &table{
+		name:         name,
+		partitionKey: partitionKey,
+		sortKey:      sortKey,
+		items:        make(map[string]map[string]types.AttributeValue),
+		created:      time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+// Put stores item in the named table, replacing any item with the same key.
+func (db *DB) Put(name string, item map[string]types.AttributeValue) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	t, err := db.table(name)
+	if err != nil {
+		return err
+	}
+	return t.put(item)
+}
+
+// Items returns every item in the named table in key order.
+func (db *DB) Items(name string) []map[string]types.AttributeValue {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	t, err := db.table(name)
+	if err != nil {
+		return nil
+	}
+
+	var items []map[string]types.AttributeValue
+	for _, item := range t.sorted() {
+		items = append(items, copyItem(item))
+	}
+	return items
+}
+
+// Calls returns how many times operation has been called, including calls
+// that Fail rejected.
+func (db *DB) Calls(operation string) int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.calls[operation]
+}
+
+func (db *DB) begin(operation string, input interface{}) error {
+	db.calls[operation]++
+	if db.Fail != nil {
+		return db.Fail(operation, input)
+	}
+	return nil
+}
+
+func (db *DB) table(name string) (*table, error) {
+	t, ok := db.tables[name]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{Message: aws.String(fmt.Sprintf("Requested resource not found: Table: %s not found", name))}
+	}
+	return t, nil
+}
+
+func (db *DB) Scan(ctx context.Context, input *awsdynamodb.ScanInput, optFns ...func(*awsdynamodb.Options)) (*awsdynamodb.ScanOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if err := db.begin("Scan", input); err != nil {
+		return nil, err
+	}
+
+	t, err := db.table(aws.ToString(input.TableName))
+	if err != nil {
+		return nil, err
+	}
+	if err := unsupported(input.IndexName); err != nil {
+		return nil, err
+	}
+	read, err := parseRead(input.FilterExpression, input.ProjectionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+	if err != nil {
+		return nil, err
+	}
+
+	items := t.sorted()
+
+	if input.TotalSegments != nil || input.Segment != nil {
+		segment, total := aws.ToInt32(input.Segment), aws.ToInt32(input.TotalSegments)
+		if input.Segment == nil || input.TotalSegments == nil || total < 1 || segment < 0 || segment >= total {
+			return nil, validationError("Segment and TotalSegments must be given together with 0 <= Segment < TotalSegments")
+		}
+
+		var inSegment []map[string]types.AttributeValue
+		for _, item := range items {
+			if t.segment(item, total) == segment {
+				inSegment = append(inSegment, item)
+			}
+		}
+		items = inSegment
+	}
+
+	items, err = t.after(items, input.ExclusiveStartKey, true)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := db.page(t, items, input.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := read.apply(page.items)
+	output := &awsdynamodb.ScanOutput{
+		Items:            matched,
+		Count:            int32(len(matched)),
+		ScannedCount:     int32(len(page.items)),
+		LastEvaluatedKey: page.lastKey,
+	}
+	if wantsCapacity(input.ReturnConsumedCapacity) {
+		output.ConsumedCapacity = readCapacity(t.name, page.bytes, aws.ToBool(input.ConsistentRead))
+	}
+
+	return output, nil
+}
+
+func (db *DB) Query(ctx context.Context, input *awsdynamodb.QueryInput, optFns ...func(*awsdynamodb.Options)) (*awsdynamodb.QueryOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if err := db.begin("Query", input); err != nil {
+		return nil, err
+	}
+
+	t, err := db.table(aws.ToString(input.TableName))
+	if err != nil {
+		return nil, err
+	}
+	if err := unsupported(input.IndexName); err != nil {
+		return nil, err
+	}
+	read, err := parseRead(input.FilterExpression, input.ProjectionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+	if err != nil {
+		return nil, err
+	}
+
+	condition, err := t.keyCondition(aws.ToString(input.KeyConditionExpression), input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []map[string]types.AttributeValue
+	for _, item := range t.sorted() {
+		if condition.Matches(item) {
+			items = append(items, item)
+		}
+	}
+
+	forward := input.ScanIndexForward == nil || *input.ScanIndexForward
+	if !forward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+
+	items, err = t.after(items, input.ExclusiveStartKey, forward)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := db.page(t, items, input.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := read.apply(page.items)
+	output := &awsdynamodb.QueryOutput{
+		Items:            matched,
+		Count:            int32(len(matched)),
+		ScannedCount:     int32(len(page.items)),
+		LastEvaluatedKey: page.lastKey,
+	}
+	if wantsCapacity(input.ReturnConsumedCapacity) {
+		output.ConsumedCapacity = readCapacity(t.name, page.bytes, aws.ToBool(input.ConsistentRead))
+	}
+
+	return output, nil
+}
+
+func (db *DB) GetItem(ctx context.Context, input *awsdynamodb.GetItemInput, optFns ...func(*awsdynamodb.Options)) (*awsdynamodb.GetItemOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if err := db.begin("GetItem", input); err != nil {
+		return nil, err
+	}
+
+	t, err := db.table(aws.ToString(input.TableName))
+	if err != nil {
+		return nil, err
+	}
+	read, err := parseRead(nil, input.ProjectionExpression, input.ExpressionAttributeNames, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := t.keyOf(input.Key, true)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &awsdynamodb.GetItemOutput{}
+	item, ok := t.items[key]
+	if ok {
+		output.Item = read.apply([]map[string]types.AttributeValue{copyItem(item)})[0]
+	}
+	if wantsCapacity(input.ReturnConsumedCapacity) {
+		output.ConsumedCapacity = readCapacity(t.name, itemsize.Item(item), aws.ToBool(input.ConsistentRead))
+	}
+
+	return output, nil
+}
+
+func (db *DB) BatchWriteItem(ctx context.Context, input *awsdynamodb.BatchWriteItemInput, optFns ...func(*awsdynamodb.Options)) (*awsdynamodb.BatchWriteItemOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if err := db.begin("BatchWriteItem", input); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(input.RequestItems))
+	total := 0
+	for name, requests := range input.RequestItems {
+		if _, err := db.table(name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		total += len(requests)
+	}
+	if total == 0 || total > maxBatchWrites {
+		return nil, validationError(fmt.Sprintf("BatchWriteItem takes 1 to %d write requests, got %d", maxBatchWrites, total))
+	}
+	sort.Strings(names)
+
+	output := &awsdynamodb.BatchWriteItemOutput{}
+	capacity := make(map[string]float64)
+	applied := 0
+
+	for _, name := range names {
+		t := db.tables[name]
+		for _, request := range input.RequestItems[name] {
+			if db.BatchWriteLimit > 0 && applied >= db.BatchWriteLimit {
+				if output.UnprocessedItems == nil {
+					output.UnprocessedItems = make(map[string][]types.WriteRequest)
+				}
+				output.UnprocessedItems[name] = append(output.UnprocessedItems[name], request)
+				continue
+			}
+
+			size, err := t.write(request)
+			if err != nil {
+				return nil, err
+			}
+			capacity[name] += writeUnits(size)
+			applied++
+		}
+	}
+
+	if wantsCapacity(input.ReturnConsumedCapacity) {
+		for _, name := range names {
+			output.ConsumedCapacity = append(output.ConsumedCapacity, types.ConsumedCapacity{
+				TableName:     aws.String(name),
+				CapacityUnits: aws.Float64(capacity[name]),
+			})
+		}
+	}
+
+	return output, nil
+}
+
+func (db *DB) UpdateItem(ctx context.Context, input *awsdynamodb.UpdateItemInput, optFns ...func(*awsdynamodb.Options)) (*awsdynamodb.UpdateItemOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if err := db.begin("UpdateItem", input); err != nil {
+		return nil, err
+	}
+
+	t, err := db.table(aws.ToString(input.TableName))
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := t.keyOf(input.Key, true)
+	if err != nil {
+		return nil, err
+	}
+
+	old := t.items[key]
+
+	if input.ConditionExpression != nil {
+		condition, err := expression.ParseCondition(*input.ConditionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+		if err != nil {
+			return nil, validationError(err.Error())
+		}
+		if !condition.Matches(old) {
+			return nil, &types.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+		}
+	}
+
+	updated := copyItem(old)
+	if updated == nil {
+		updated = copyItem(input.Key)
+	}
+
+	if input.UpdateExpression != nil {
+		actions, err := parseUpdate(*input.UpdateExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+		if err != nil {
+			return nil, err
+		}
+		for _, action := range actions {
+			if action.name == t.partitionKey || action.name == t.sortKey {
+				return nil, validationError(fmt.Sprintf("Cannot update attribute %s. This attribute is part of the key", action.name))
+			}
+			if err := action.apply(updated); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := t.put(updated); err != nil {
+		return nil, err
+	}
+
+	output := &awsdynamodb.UpdateItemOutput{}
+	switch input.ReturnValues {
+	case "", types.ReturnValueNone:
+	case types.ReturnValueAllNew:
+		output.Attributes = copyItem(updated)
+	case types.ReturnValueAllOld:
+		output.Attributes = copyItem(old)
+	default:
+		return nil, validationError(fmt.Sprintf("ReturnValues %s is not supported by UpdateItem", input.ReturnValues))
+	}
+
+	if wantsCapacity(input.ReturnConsumedCapacity) {
+		output.ConsumedCapacity = &types.ConsumedCapacity{
+			TableName:     aws.String(t.name),
+			CapacityUnits: aws.Float64(writeUnits(max(itemsize.Item(old), itemsize.Item(updated)))),
+		}
+	}
+
+	return output, nil
+}
+
+func (db *DB) DeleteItem(ctx context.Context, input *awsdynamodb.DeleteItemInput, optFns ...func(*awsdynamodb.Options)) (*awsdynamodb.DeleteItemOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if err := db.begin("DeleteItem", input); err != nil {
+		return nil, err
+	}
+
+	t, err := db.table(aws.ToString(input.TableName))
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := t.keyOf(input.Key, true)
+	if err != nil {
+		return nil, err
+	}
+
+	old := t.items[key]
+
+	if input.ConditionExpression != nil {
+		condition, err := expression.ParseCondition(*input.ConditionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+		if err != nil {
+			return nil, validationError(err.Error())
+		}
+		if !condition.Matches(old) {
+			return nil, &types.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+		}
+	}
+
+	delete(t.items, key)
+
+	output := &awsdynamodb.DeleteItemOutput{}
+	switch input.ReturnValues {
+	case "", types.ReturnValueNone:
+	case types.ReturnValueAllOld:
+		output.Attributes = copyItem(old)
+	default:
+		return nil, validationError(fmt.Sprintf("ReturnValues %s is not supported by DeleteItem", input.ReturnValues))
+	}
+
+	if wantsCapacity(input.ReturnConsumedCapacity) {
+		output.ConsumedCapacity = &types.ConsumedCapacity{
+			TableName:     aws.String(t.name),
+			CapacityUnits: aws.Float64(writeUnits(itemsize.Item(old))),
+		}
+	}
+
+	return output, nil
+}
+
+// DescribeTable reports the key schema and current size of a table. Key
+// attribute types are taken from the stored items, S when there are none.
+func (db *DB) DescribeTable(ctx context.Context, input *awsdynamodb.DescribeTableInput, optFns ...func(*awsdynamodb.Options)) (*awsdynamodb.DescribeTableOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if err := db.begin("DescribeTable", input); err != nil {
+		return nil, err
+	}
+
+	t, err := db.table(aws.ToString(input.TableName))
+	if err != nil {
+		return nil, err
+	}
+
+	keySchema := []types.KeySchemaElement{{AttributeName: aws.String(t.partitionKey), KeyType: types.KeyTypeHash}}
+	definitions := []types.AttributeDefinition{{AttributeName: aws.String(t.partitionKey), AttributeType: t.keyType(t.partitionKey)}}
+	if t.sortKey != "" {
+		keySchema = append(keySchema, types.KeySchemaElement{AttributeName: aws.String(t.sortKey), KeyType: types.KeyTypeRange})
+		definitions = append(definitions, types.AttributeDefinition{AttributeName: aws.String(t.sortKey), AttributeType: t.keyType(t.sortKey)})
+	}
+
+	size := 0
+	for _, item := range t.items {
+		size += itemsize.Item(item)
+	}
+
+	return &awsdynamodb.DescribeTableOutput{
+		Table: &types.TableDescription{
+			TableName:            aws.String(t.name),
+			TableArn:             aws.String("arn:aws:dynamodb:local:000000000000:table/" + t.name),
+			TableStatus:          types.TableStatusActive,
+			CreationDateTime:     aws.Time(t.created),
+			KeySchema:            keySchema,
+			AttributeDefinitions: definitions,
+			ItemCount:            aws.Int64(int64(len(t.items))),
+			TableSizeBytes:       aws.Int64(int64(size)),
+			BillingModeSummary:   &types.BillingModeSummary{BillingMode: types.BillingModePayPerRequest},
+		},
+	}, nil
+}
+
+type page struct {
+	items   []map[string]types.AttributeValue
+	lastKey map[string]types.AttributeValue
+	bytes   int
+}
+
+// page takes items until Limit or the page size is reached. Like DynamoDB,
+// a page always holds at least one item when any remain, and a page that
+// stops at Limit carries a LastEvaluatedKey even when it took the last
+// item, so the next request comes back empty.
+func (db *DB) page(t *table, items []map[string]types.AttributeValue, limit *int32) (page, error) {
+	if limit != nil && *limit < 1 {
+		return page{}, validationError("Limit must be at least 1")
+	}
+
+	maxBytes := db.MaxPageBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultPageBytes
+	}
+
+	var p page
+	for i, item := range items {
+		size := itemsize.Item(item)
+		if len(p.items) > 0 && p.bytes+size > maxBytes {
+			p.lastKey = t.key(items[i-1])
+			break
+		}
+
+		p.items = append(p.items, copyItem(item))
+		p.bytes += size
+
+		if limit != nil && int32(len(p.items)) >= *limit {
+			p.lastKey = t.key(item)
+			break
+		}
+	}
+
+	return p, nil
+}
+
+func (t *table) put(item map[string]types.AttributeValue) error {
+	key, err := t.keyOf(item, false)
+	if err != nil {
+		return err
+	}
+	t.items[key] = copyItem(item)
+	return nil
+}
+
+func (t *table) write(request types.WriteRequest) (int, error) {
+	switch {
+	case request.PutRequest != nil:
+		item := request.PutRequest.Item
+		return itemsize.Item(item), t.put(item)
+	case request.DeleteRequest != nil:
+		key, err := t.keyOf(request.DeleteRequest.Key, true)
+		if err != nil {
+			return 0, err
+		}
+		size := itemsize.Item(t.items[key])
+		delete(t.items, key)
+		return size, nil
+	default:
+		return 0, validationError("a write request needs a PutRequest or a DeleteRequest")
+	}
+}
+
+// sorted returns the table's items ordered by partition key and then sort
+// key.
+func (t *table) sorted() []map[string]types.AttributeValue {
+	items := make([]map[string]types.AttributeValue, 0, len(t.items))
+	for _, item := range t.items {
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return t.compare(items[i], items[j]) < 0
+	})
+
+	return items
+}
+
+func (t *table) compare(a, b map[string]types.AttributeValue) int {
+	if c, _ := expression.Compare(a[t.partitionKey], b[t.partitionKey]); c != 0 || t.sortKey == "" {
+		return c
+	}
+	c, _ := expression.Compare(a[t.sortKey], b[t.sortKey])
+	return c
+}
+
+// after drops the items up to and including start, which need not still be
+// in the table.
+func (t *table) after(items []map[string]types.AttributeValue, start map[string]types.AttributeValue, forward bool) ([]map[string]types.AttributeValue, error) {
+	if start == nil {
+		return items, nil
+	}
+	if _, err := t.keyOf(start, true); err != nil {
+		return nil, validationError("The provided starting key is invalid: " + err.Error())
+	}
+
+	for i, item := range items {
+		c := t.compare(item, start)
+		if (forward && c > 0) || (!forward && c < 0) {
+			return items[i:], nil
+		}
+	}
+	return nil, nil
+}
+
+func (t *table) segment(item map[string]types.AttributeValue, total int32) int32 {
+	h := fnv.New32a()
+	h.Write([]byte(encodeKeyValue(item[t.partitionKey])))
+	return int32(h.Sum32() % uint32(total))
+}
+
+func (t *table) key(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	key := map[string]types.AttributeValue{t.partitionKey: item[t.partitionKey]}
+	if t.sortKey != "" {
+		key[t.sortKey] = item[t.sortKey]
+	}
+	return key
+}
+
+// keyOf returns the map key the item is stored under. With exact set the
+// item must hold nothing but the key attributes.
+func (t *table) keyOf(item map[string]types.AttributeValue, exact bool) (string, error) {
+	names := []string{t.partitionKey}
+	if t.sortKey != "" {
+		names = append(names, t.sortKey)
+	}
+
+	if exact && len(item) != len(names) {
+		return "", validationError("The provided key element does not match the schema")
+	}
+
+	var key string
+	for _, name := range names {
+		value, ok := item[name]
+		if !ok {
+			return "", validationError(fmt.Sprintf("One or more parameter values were invalid: Missing the key %s in the item", name))
+		}
+		encoded := encodeKeyValue(value)
+		if encoded == "" {
+			return "", validationError(fmt.Sprintf("One or more parameter values were invalid: key %s must be a string, number or binary", name))
+		}
+		key += encoded + "\x00"
+	}
+
+	return key, nil
+}
+
+// keyType returns the type of a key attribute as the stored items hold it.
+func (t *table) keyType(name string) types.ScalarAttributeType {
+	for _, item := range t.items {
+		switch item[name].(type) {
+		case *types.AttributeValueMemberN:
+			return types.ScalarAttributeTypeN
+		case *types.AttributeValueMemberB:
+			return types.ScalarAttributeTypeB
+		}
+		return types.ScalarAttributeTypeS
+	}
+	return types.ScalarAttributeTypeS
+}
+
+func copyItem(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	if item == nil {
+		return nil
+	}
+
+	copied := make(map[string]types.AttributeValue, len(item))
+	for name, value := range item {
+		copied[name] = value
+	}
+	return copied
+}
+
+// readExpressions holds the filter and projection expressions of a read;
+// either is nil when the request did not give it.
+type readExpressions struct {
+	filter     *expression.Condition
+	projection *expression.Projection
+}
+
+func parseRead(filter, projection *string, names map[string]string, values map[string]types.AttributeValue) (readExpressions, error) {
+	var read readExpressions
+
+	if filter != nil {
+		condition, err := expression.ParseCondition(*filter, names, values)
+		if err != nil {
+			return read, validationError(err.Error())
+		}
+		read.filter = condition
+	}
+
+	if projection != nil {
+		parsed, err := expression.ParseProjection(*projection, names)
+		if err != nil {
+			return read, validationError(err.Error())
+		}
+		read.projection = parsed
+	}
+
+	return read, nil
+}
+
+// apply drops the items the filter rejects and projects the rest. Like
+// DynamoDB, it runs after a page has been read, so a page may come back
+// with fewer items than Limit, or none.
+func (r readExpressions) apply(items []map[string]types.AttributeValue) []map[string]types.AttributeValue {
+	var kept []map[string]types.AttributeValue
+	for _, item := range items {
+		if r.filter != nil && !r.filter.Matches(item) {
+			continue
+		}
+		if r.projection != nil {
+			item = r.projection.Apply(item)
+		}
+		kept = append(kept, item)
+	}
+	return kept
+}
+
+func unsupported(index *string) error {
+	if index != nil {
+		return validationError("Secondary indexes are not supported by this in-memory table")
+	}
+	return nil
+}
+
+func validationError(message string) error {
+	return &smithy.GenericAPIError{Code: "ValidationException", Message: message, Fault: smithy.FaultClient}
+}
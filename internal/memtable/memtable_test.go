@@ -0,0 +1,413 @@
+package memtable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+)
+
+func s(value string) types.AttributeValue { return &types.AttributeValueMemberS{Value: value} }
+func n(value string) types.AttributeValue { return &types.AttributeValueMemberN{Value: value} }
+
+func newOrders(t *testing.T) *DB {
+	t.Helper()
+
+	fake := New()
+	fake.CreateTable("orders", "customer", "placed")
+	for _, customer := range []string{"alice", "bob"} {
+		for day := 1; day <= 5; day++ {
+			item := map[string]types.AttributeValue{
+				"customer": s(customer),
+				"placed":   n(fmt.Sprint(day * 10)),
+				"total":    n("1.5"),
+			}
+			if err := fake.Put("orders", item); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return fake
+}
+
+func placed(items []map[string]types.AttributeValue) []string {
+	var days []string
+	for _, item := range items {
+		days = append(days, item["placed"].(*types.AttributeValueMemberN).Value)
+	}
+	return days
+}
+
+func TestQuery_KeyConditions(t *testing.T) {
+	fake := newOrders(t)
+
+	tests := []struct {
+		name      string
+		condition string
+		values    map[string]types.AttributeValue
+		forward   bool
+		expected  string
+	}{
+		{"partition only", "customer = :c", map[string]types.AttributeValue{":c": s("bob")}, true, "[10 20 30 40 50]"},
+		{"sort greater than", "customer = :c AND placed > :p", map[string]types.AttributeValue{":c": s("bob"), ":p": n("30")}, true, "[40 50]"},
+		{"between", "#c = :c AND placed BETWEEN :lo AND :hi", map[string]types.AttributeValue{":c": s("alice"), ":lo": n("15"), ":hi": n("40")}, true, "[20 30 40]"},
+		{"reversed", "customer = :c AND placed <= :p", map[string]types.AttributeValue{":c": s("alice"), ":p": n("2e1")}, false, "[20 10]"},
+		{"no match", "customer = :c", map[string]types.AttributeValue{":c": s("carol")}, true, "[]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := fake.Query(context.Background(), &awsdynamodb.QueryInput{
+				TableName:                 aws.String("orders"),
+				KeyConditionExpression:    aws.String(tt.condition),
+				ExpressionAttributeNames:  map[string]string{"#c": "customer"},
+				ExpressionAttributeValues: tt.values,
+				ScanIndexForward:          aws.Bool(tt.forward),
+			})
+			if err != nil {
+				t.Fatalf("Query failed: %v", err)
+			}
+			if got := fmt.Sprint(placed(output.Items)); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestQuery_RejectsNonKeyConditions(t *testing.T) {
+	fake := newOrders(t)
+
+	for _, condition := range []string{"placed = :p", "customer = :c AND total = :p", "customer < :c", "customer = :c OR placed = :p", "customer = :c AND placed > :p AND placed < :p"} {
+		_, err := fake.Query(context.Background(), &awsdynamodb.QueryInput{
+			TableName:                 aws.String("orders"),
+			KeyConditionExpression:    aws.String(condition),
+			ExpressionAttributeValues: map[string]types.AttributeValue{":c": s("bob"), ":p": n("10")},
+		})
+		if err == nil {
+			t.Errorf("Expected %q to be rejected", condition)
+		}
+	}
+}
+
+func TestScan_Pagination(t *testing.T) {
+	fake := newOrders(t)
+
+	var all []map[string]types.AttributeValue
+	var startKey map[string]types.AttributeValue
+	pages := 0
+
+	for {
+		output, err := fake.Scan(context.Background(), &awsdynamodb.ScanInput{
+			TableName:         aws.String("orders"),
+			Limit:             aws.Int32(3),
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			t.Fatalf("Scan failed: %v", err)
+		}
+		pages++
+		all = append(all, output.Items...)
+
+		if output.LastEvaluatedKey == nil {
+			break
+		}
+		if len(output.LastEvaluatedKey) != 2 {
+			t.Errorf("Expected LastEvaluatedKey to hold only the key, got %v", output.LastEvaluatedKey)
+		}
+		startKey = output.LastEvaluatedKey
+	}
+
+	if pages != 4 || len(all) != 10 {
+		t.Errorf("Expected 10 items over 4 pages, got %d over %d", len(all), pages)
+	}
+}
+
+func TestScan_EmptyFinalPage(t *testing.T) {
+	fake := newOrders(t)
+
+	// Ten items in pages of five: the second page ends on the last item but
+	// still carries a key, and the third comes back empty
+	var counts []int
+	var startKey map[string]types.AttributeValue
+	for {
+		output, err := fake.Scan(context.Background(), &awsdynamodb.ScanInput{
+			TableName:         aws.String("orders"),
+			Limit:             aws.Int32(5),
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			t.Fatalf("Scan failed: %v", err)
+		}
+		counts = append(counts, len(output.Items))
+
+		if output.LastEvaluatedKey == nil {
+			break
+		}
+		startKey = output.LastEvaluatedKey
+	}
+
+	if fmt.Sprint(counts) != "[5 5 0]" {
+		t.Errorf("Expected pages of 5, 5 and 0 items, got %v", counts)
+	}
+}
+
+func TestScan_PageSize(t *testing.T) {
+	fake := newOrders(t)
+	fake.MaxPageBytes = 60
+
+	output, err := fake.Scan(context.Background(), &awsdynamodb.ScanInput{TableName: aws.String("orders")})
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	if len(output.Items) == 0 || len(output.Items) == 10 || output.LastEvaluatedKey == nil {
+		t.Errorf("Expected a partial page, got %d items and key %v", len(output.Items), output.LastEvaluatedKey)
+	}
+}
+
+func TestScan_FilterAndProjection(t *testing.T) {
+	fake := newOrders(t)
+
+	output, err := fake.Scan(context.Background(), &awsdynamodb.ScanInput{
+		TableName:                 aws.String("orders"),
+		Limit:                     aws.Int32(4),
+		FilterExpression:          aws.String("placed >= :p AND customer = :c"),
+		ProjectionExpression:      aws.String("placed, #t"),
+		ExpressionAttributeNames:  map[string]string{"#t": "total"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":p": n("30"), ":c": s("alice")},
+	})
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	if got := fmt.Sprint(placed(output.Items)); got != "[30 40]" {
+		t.Errorf("Expected the filter to run on the page of 4, got %s", got)
+	}
+	if output.Count != 2 || output.ScannedCount != 4 || output.LastEvaluatedKey == nil {
+		t.Errorf("Unexpected counts: Count %d, ScannedCount %d, LastEvaluatedKey %v", output.Count, output.ScannedCount, output.LastEvaluatedKey)
+	}
+	if _, ok := output.Items[0]["customer"]; ok || len(output.Items[0]) != 2 {
+		t.Errorf("Expected only the projected attributes, got %v", output.Items[0])
+	}
+
+	_, err = fake.Scan(context.Background(), &awsdynamodb.ScanInput{
+		TableName:        aws.String("orders"),
+		FilterExpression: aws.String("placed = :undefined"),
+	})
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) || apiErr.ErrorCode() != "ValidationException" {
+		t.Errorf("Expected a ValidationException, got %v", err)
+	}
+}
+
+func TestScan_Segments(t *testing.T) {
+	fake := New()
+	fake.CreateTable("products", "id", "")
+	for i := 0; i < 50; i++ {
+		fake.Put("products", map[string]types.AttributeValue{"id": s(fmt.Sprintf("product-%d", i))})
+	}
+
+	seen := make(map[string]int)
+	for segment := int32(0); segment < 4; segment++ {
+		output, err := fake.Scan(context.Background(), &awsdynamodb.ScanInput{
+			TableName:     aws.String("products"),
+			Segment:       aws.Int32(segment),
+			TotalSegments: aws.Int32(4),
+		})
+		if err != nil {
+			t.Fatalf("Scan of segment %d failed: %v", segment, err)
+		}
+		if len(output.Items) == 50 {
+			t.Errorf("Expected segment %d to hold part of the table", segment)
+		}
+		for _, item := range output.Items {
+			seen[item["id"].(*types.AttributeValueMemberS).Value]++
+		}
+	}
+
+	if len(seen) != 50 {
+		t.Errorf("Expected every item in exactly one segment, saw %d items", len(seen))
+	}
+	for id, count := range seen {
+		if count != 1 {
+			t.Errorf("Expected %s in one segment, found in %d", id, count)
+		}
+	}
+}
+
+func TestScan_ConsumedCapacity(t *testing.T) {
+	fake := newOrders(t)
+
+	output, err := fake.Scan(context.Background(), &awsdynamodb.ScanInput{
+		TableName:              aws.String("orders"),
+		ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+	})
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	if output.ConsumedCapacity == nil || *output.ConsumedCapacity.CapacityUnits != 0.5 {
+		t.Errorf("Expected 0.5 units for a small eventually consistent scan, got %+v", output.ConsumedCapacity)
+	}
+}
+
+func TestBatchWriteItem_UnprocessedItems(t *testing.T) {
+	fake := New()
+	fake.CreateTable("products", "id", "")
+	fake.BatchWriteLimit = 2
+
+	var requests []types.WriteRequest
+	for i := 0; i < 5; i++ {
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{
+			Item: map[string]types.AttributeValue{"id": s(fmt.Sprint(i))},
+		}})
+	}
+
+	output, err := fake.BatchWriteItem(context.Background(), &awsdynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]types.WriteRequest{"products": requests},
+	})
+	if err != nil {
+		t.Fatalf("BatchWriteItem failed: %v", err)
+	}
+
+	if len(fake.Items("products")) != 2 {
+		t.Errorf("Expected 2 items written, got %d", len(fake.Items("products")))
+	}
+	if len(output.UnprocessedItems["products"]) != 3 {
+		t.Errorf("Expected 3 unprocessed items, got %v", output.UnprocessedItems)
+	}
+
+	if _, err := fake.BatchWriteItem(context.Background(), &awsdynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]types.WriteRequest{"products": {}},
+	}); err == nil {
+		t.Error("Expected an empty batch to be rejected")
+	}
+}
+
+func TestUpdateItem(t *testing.T) {
+	fake := newOrders(t)
+	key := map[string]types.AttributeValue{"customer": s("alice"), "placed": n("10")}
+
+	output, err := fake.UpdateItem(context.Background(), &awsdynamodb.UpdateItemInput{
+		TableName:                 aws.String("orders"),
+		Key:                       key,
+		UpdateExpression:          aws.String("SET #t = #t + :inc, note = if_not_exists(note, :note) REMOVE missing"),
+		ConditionExpression:       aws.String("attribute_exists(customer) AND #t = :old"),
+		ExpressionAttributeNames:  map[string]string{"#t": "total"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":inc": n("2.25"), ":note": s("gift"), ":old": n("1.50")},
+		ReturnValues:              types.ReturnValueAllNew,
+	})
+	if err != nil {
+		t.Fatalf("UpdateItem failed: %v", err)
+	}
+
+	if total := output.Attributes["total"].(*types.AttributeValueMemberN).Value; total != "3.75" {
+		t.Errorf("Expected total 3.75, got %s", total)
+	}
+	if note := output.Attributes["note"].(*types.AttributeValueMemberS).Value; note != "gift" {
+		t.Errorf("Expected note gift, got %s", note)
+	}
+
+	_, err = fake.UpdateItem(context.Background(), &awsdynamodb.UpdateItemInput{
+		TableName:                 aws.String("orders"),
+		Key:                       key,
+		UpdateExpression:          aws.String("SET total = :v"),
+		ConditionExpression:       aws.String("total = :v"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":v": n("1.5")},
+	})
+	var failed *types.ConditionalCheckFailedException
+	if !errors.As(err, &failed) {
+		t.Errorf("Expected a conditional check failure, got %v", err)
+	}
+
+	_, err = fake.UpdateItem(context.Background(), &awsdynamodb.UpdateItemInput{
+		TableName:                 aws.String("orders"),
+		Key:                       key,
+		UpdateExpression:          aws.String("SET placed = :v"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":v": n("1")},
+	})
+	if err == nil {
+		t.Error("Expected updating a key attribute to fail")
+	}
+}
+
+func TestGetItem(t *testing.T) {
+	fake := newOrders(t)
+
+	output, err := fake.GetItem(context.Background(), &awsdynamodb.GetItemInput{
+		TableName: aws.String("orders"),
+		Key:       map[string]types.AttributeValue{"customer": s("bob"), "placed": n("30.0")},
+	})
+	if err != nil {
+		t.Fatalf("GetItem failed: %v", err)
+	}
+	if output.Item == nil {
+		t.Error("Expected the item, matching numbers by value")
+	}
+
+	_, err = fake.GetItem(context.Background(), &awsdynamodb.GetItemInput{
+		TableName: aws.String("missing"),
+		Key:       map[string]types.AttributeValue{"customer": s("bob")},
+	})
+	var notFound *types.ResourceNotFoundException
+	if !errors.As(err, &notFound) {
+		t.Errorf("Expected ResourceNotFoundException, got %v", err)
+	}
+}
+
+func TestFail(t *testing.T) {
+	fake := newOrders(t)
+	fake.Fail = func(operation string, input interface{}) error {
+		if operation == "Scan" {
+			return &types.ProvisionedThroughputExceededException{}
+		}
+		return nil
+	}
+
+	_, err := fake.Scan(context.Background(), &awsdynamodb.ScanInput{TableName: aws.String("orders")})
+	var exceeded *types.ProvisionedThroughputExceededException
+	if !errors.As(err, &exceeded) {
+		t.Errorf("Expected the injected error, got %v", err)
+	}
+	if fake.Calls("Scan") != 1 {
+		t.Errorf("Expected one Scan call, got %d", fake.Calls("Scan"))
+	}
+}
+
+func TestDeleteItem(t *testing.T) {
+	fake := newOrders(t)
+	key := map[string]types.AttributeValue{"customer": s("alice"), "placed": n("10")}
+
+	_, err := fake.DeleteItem(context.Background(), &awsdynamodb.DeleteItemInput{
+		TableName:                 aws.String("orders"),
+		Key:                       key,
+		ConditionExpression:       aws.String("total = :t"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":t": n("2")},
+	})
+	var failed *types.ConditionalCheckFailedException
+	if !errors.As(err, &failed) {
+		t.Errorf("Expected a conditional check failure, got %v", err)
+	}
+
+	output, err := fake.DeleteItem(context.Background(), &awsdynamodb.DeleteItemInput{
+		TableName:                 aws.String("orders"),
+		Key:                       key,
+		ConditionExpression:       aws.String("total = :t"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":t": n("1.5")},
+		ReturnValues:              types.ReturnValueAllOld,
+	})
+	if err != nil {
+		t.Fatalf("DeleteItem failed: %v", err)
+	}
+	if output.Attributes == nil {
+		t.Error("Expected the deleted item to be returned")
+	}
+	if len(fake.Items("orders")) != 9 {
+		t.Errorf("Expected 9 items left, got %d", len(fake.Items("orders")))
+	}
+}
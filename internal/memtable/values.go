@@ -0,0 +1,65 @@
+package memtable
+
+import (
+	"encoding/base64"
+	"math"
+	"math/big"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func parseNumber(s string) (*big.Rat, bool) {
+	return new(big.Rat).SetString(s)
+}
+
+func formatNumber(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+
+	s := r.FloatString(38)
+	for s[len(s)-1] == '0' {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// encodeKeyValue returns a string that is equal for equal key values, or ""
+// for a type that cannot be part of a key.
+func encodeKeyValue(value types.AttributeValue) string {
+	switch v := value.(type) {
+	case *types.AttributeValueMemberS:
+		return "S" + v.Value
+	case *types.AttributeValueMemberN:
+		if r, ok := parseNumber(v.Value); ok {
+			return "N" + r.RatString()
+		}
+	case *types.AttributeValueMemberB:
+		return "B" + base64.StdEncoding.EncodeToString(v.Value)
+	}
+	return ""
+}
+
+func wantsCapacity(mode types.ReturnConsumedCapacity) bool {
+	return mode == types.ReturnConsumedCapacityTotal || mode == types.ReturnConsumedCapacityIndexes
+}
+
+// readCapacity charges one unit per 4 KB read, or half that for eventually
+// consistent reads, with a minimum of one 4 KB block.
+func readCapacity(table string, bytes int, consistent bool) *types.ConsumedCapacity {
+	units := math.Max(math.Ceil(float64(bytes)/4096), 1)
+	if !consistent {
+		units /= 2
+	}
+
+	return &types.ConsumedCapacity{
+		TableName:     aws.String(table),
+		CapacityUnits: aws.Float64(units),
+	}
+}
+
+// writeUnits charges one unit per 1 KB written, with a minimum of one.
+func writeUnits(bytes int) float64 {
+	return math.Max(math.Ceil(float64(bytes)/1024), 1)
+}
@@ -13,6 +13,36 @@ type Product struct {
 	URL              string `dynamodbav:"url"`
 	RawTextContent   string `dynamodbav:"rawTextContent"`
 	RawHTML          string `dynamodbav:"rawHtml"`
-	RawHTMLExtracted string `json:"RawHTMLExtracted"`
+	RawHTMLExtracted string `dynamodbav:"-" json:"RawHTMLExtracted"`
 	TTL              int64  `dynamodbav:"ttl"`
+
+	// The codecs rawHtml was decoded with, outermost first, such as
+	// ["base64", "zlib"]; empty when it held plain HTML
+	RawHTMLChain []string `dynamodbav:"-" json:",omitempty"`
+
+	// Parsed from the product card in RawHTMLExtracted; empty when the HTML
+	// could not be extracted or holds no card
+	ParsedName    string `dynamodbav:"-" json:",omitempty"`
+	CurrentPrice  string `dynamodbav:"-" json:",omitempty"`
+	OriginalPrice string `dynamodbav:"-" json:",omitempty"`
+	SizeText      string `dynamodbav:"-" json:",omitempty"`
+	ImageSrcset   string `dynamodbav:"-" json:",omitempty"`
+	ProductLink   string `dynamodbav:"-" json:",omitempty"`
+
+	// Parsed from rawTextContent. TextUncertain is set when the text did not
+	// follow the expected layout and the fields may be incomplete
+	TextCurrentPrice  string `dynamodbav:"-" json:",omitempty"`
+	TextOriginalPrice string `dynamodbav:"-" json:",omitempty"`
+	Quantity          string `dynamodbav:"-" json:",omitempty"`
+	Unit              string `dynamodbav:"-" json:",omitempty"`
+	StockText         string `dynamodbav:"-" json:",omitempty"`
+	CallToAction      string `dynamodbav:"-" json:",omitempty"`
+	TextUncertain     bool   `dynamodbav:"-" json:",omitempty"`
+
+	// Set for products read from DynamoDB stream records
+	EventName   string `dynamodbav:"-" json:",omitempty"`
+	StreamImage string `dynamodbav:"-" json:",omitempty"`
+
+	// Set for products read from BatchGetItem output
+	SourceTable string `dynamodbav:"-" json:",omitempty"`
 }
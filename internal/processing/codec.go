@@ -0,0 +1,325 @@
+package processing
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Codec decodes one layer that rawHtml may be wrapped in, such as base64 text
+// or a compression format, and recognises data written in that layer.
+type Codec interface {
+	Name() string
+
+	// Detect reports whether data looks like the output of this codec.
+	Detect(data []byte) bool
+
+	Decode(data []byte) ([]byte, error)
+}
+
+// VariantDecoder is implemented by codecs that accept several variants of
+// their format. Extract decodes with DecodeVariant and records the variant
+// that worked in the chain in place of the codec's name.
+type VariantDecoder interface {
+	DecodeVariant(data []byte) ([]byte, string, error)
+}
+
+// CodecRegistry holds the codecs an HTMLExtractor can unwrap. Detection
+// tries them in the order they were registered, so codecs recognised by a
+// magic number belong before those that can only be recognised by decoding.
+type CodecRegistry struct {
+	codecs []Codec
+}
+
+// NewCodecRegistry returns a registry holding codecs.
+func NewCodecRegistry(codecs ...Codec) *CodecRegistry {
+	r := &CodecRegistry{}
+	for _, codec := range codecs {
+		r.Register(codec)
+	}
+	return r
+}
+
+// DefaultCodecs returns every format the scraper has written, in detection
+// order: gzip, zstd and zlib by their headers, then base64, then raw deflate
+// and brotli, which have no header and are recognised by decoding them.
+func DefaultCodecs() []Codec {
+	return []Codec{gzipCodec, zstdCodec, zlibCodec{}, base64Codec{}, deflateCodec, brotliCodec}
+}
+
+// Register adds codec, replacing any registered codec with the same name in
+// its place in the detection order.
+func (r *CodecRegistry) Register(codec Codec) {
+	for i, registered := range r.codecs {
+		if registered.Name() == codec.Name() {
+			r.codecs[i] = codec
+			return
+		}
+	}
+	r.codecs = append(r.codecs, codec)
+}
+
+// Lookup returns the codec registered under name.
+func (r *CodecRegistry) Lookup(name string) (Codec, bool) {
+	for _, codec := range r.codecs {
+		if codec.Name() == name {
+			return codec, true
+		}
+	}
+	return nil, false
+}
+
+// Detect returns the first codec that recognises data.
+func (r *CodecRegistry) Detect(data []byte) (Codec, bool) {
+	for _, codec := range r.codecs {
+		if codec.Detect(data) {
+			return codec, true
+		}
+	}
+	return nil, false
+}
+
+// DetectAll returns every codec that recognises data, in detection order.
+// Headers are short enough for text to match them by chance, as base64
+// starting with hC passes the zlib header check, so a codec that fails to
+// decode data should give way to the next one.
+func (r *CodecRegistry) DetectAll(data []byte) []Codec {
+	var codecs []Codec
+	for _, codec := range r.codecs {
+		if codec.Detect(data) {
+			codecs = append(codecs, codec)
+		}
+	}
+	return codecs
+}
+
+// Names lists the registered codecs in detection order.
+func (r *CodecRegistry) Names() []string {
+	names := make([]string, len(r.codecs))
+	for i, codec := range r.codecs {
+		names[i] = codec.Name()
+	}
+	return names
+}
+
+// magicCodec is a compression format whose streams start with a fixed magic
+// number.
+type magicCodec struct {
+	name  string
+	magic []byte
+	open  func(io.Reader) (io.ReadCloser, error)
+}
+
+var (
+	gzipCodec = magicCodec{"gzip", []byte{0x1f, 0x8b, 0x08}, func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	}}
+	zstdCodec = magicCodec{"zstd", []byte{0x28, 0xb5, 0x2f, 0xfd}, func(r io.Reader) (io.ReadCloser, error) {
+		decoder, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	}}
+)
+
+func (c magicCodec) Name() string { return c.name }
+
+func (c magicCodec) Detect(data []byte) bool { return bytes.HasPrefix(data, c.magic) }
+
+func (c magicCodec) Decode(data []byte) ([]byte, error) {
+	reader, err := c.open(bytes.NewReader(data))
+	return readAll(c.name, reader, err)
+}
+
+// zlibCodec recognises the two-byte zlib header: deflate with a window of at
+// most 32 KB (0x78 for the usual 32 KB) and a check value making the header
+// a multiple of 31. pako.deflate() writes zlib, not raw deflate.
+type zlibCodec struct{}
+
+func (zlibCodec) Name() string { return "zlib" }
+
+func (zlibCodec) Detect(data []byte) bool {
+	if len(data) < 2 {
+		return false
+	}
+	return data[0]&0x0f == 8 && data[0]>>4 <= 7 && (uint16(data[0])<<8|uint16(data[1]))%31 == 0
+}
+
+func (zlibCodec) Decode(data []byte) ([]byte, error) {
+	reader, err := zlib.NewReader(bytes.NewReader(data))
+	return readAll("zlib", reader, err)
+}
+
+// base64Codec recognises base64 text. By default it accepts the URL-safe
+// alphabet, missing padding and line breaks, as left by copying a value out
+// of the console; strict accepts only what StdEncoding decodes.
+type base64Codec struct {
+	strict bool
+}
+
+// base64Variants are the encodings a tolerant base64Codec tries, in order,
+// under the names it reports them by.
+var base64Variants = []struct {
+	name     string
+	encoding *base64.Encoding
+}{
+	{"base64", base64.StdEncoding},
+	{"base64url", base64.URLEncoding},
+	{"base64-unpadded", base64.RawStdEncoding},
+	{"base64url-unpadded", base64.RawURLEncoding},
+}
+
+func (base64Codec) Name() string { return "base64" }
+
+func (c base64Codec) Detect(data []byte) bool {
+	if c.strict {
+		return detectStdBase64(data)
+	}
+
+	// Spaces inside the text are more likely words than wrapped base64, so
+	// only line breaks are skipped when recognising it
+	text := strings.NewReplacer("\r", "", "\n", "").Replace(strings.TrimSpace(string(data)))
+	if text == "" {
+		return false
+	}
+
+	unpadded := strings.TrimRight(text, "=")
+	if len(text)-len(unpadded) > 2 {
+		return false
+	}
+
+	var std, url bool
+	for i := 0; i < len(unpadded); i++ {
+		c := unpadded[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+		case c == '+' || c == '/':
+			std = true
+		case c == '-' || c == '_':
+			url = true
+		default:
+			return false
+		}
+	}
+	if std && url {
+		return false
+	}
+
+	if len(unpadded) < len(text) {
+		return len(text)%4 == 0
+	}
+	return len(text)%4 != 1
+}
+
+// detectStdBase64 recognises text made only of the standard base64
+// alphabet, in whole four-character groups.
+func detectStdBase64(data []byte) bool {
+	text := strings.TrimSpace(string(data))
+	if text == "" {
+		return false
+	}
+
+	length := 0
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '+', c == '/', c == '=':
+			length++
+		case c == '\r' || c == '\n':
+		default:
+			return false
+		}
+	}
+	return length%4 == 0
+}
+
+func (c base64Codec) Decode(data []byte) ([]byte, error) {
+	decoded, _, err := c.DecodeVariant(data)
+	return decoded, err
+}
+
+// DecodeVariant decodes data with the first base64 variant that accepts it
+// and names that variant.
+func (c base64Codec) DecodeVariant(data []byte) ([]byte, string, error) {
+	if c.strict {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to decode base64: %w", err)
+		}
+		return decoded, "base64", nil
+	}
+
+	text := stripSpace(data)
+	var firstErr error
+	for _, variant := range base64Variants {
+		decoded, err := variant.encoding.DecodeString(text)
+		if err == nil {
+			return decoded, variant.name, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, "", fmt.Errorf("failed to decode base64: %w", firstErr)
+}
+
+// stripSpace returns data as a string with all ASCII whitespace removed.
+func stripSpace(data []byte) string {
+	var b strings.Builder
+	b.Grow(len(data))
+	for _, c := range data {
+		switch c {
+		case ' ', '\t', '\r', '\n', '\v', '\f':
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// probeCodec is a format without a header, recognised by decoding the data
+// without error.
+type probeCodec struct {
+	name string
+	open func(io.Reader) io.ReadCloser
+}
+
+var (
+	deflateCodec = probeCodec{"deflate", flate.NewReader}
+	brotliCodec  = probeCodec{"brotli", func(r io.Reader) io.ReadCloser {
+		return io.NopCloser(brotli.NewReader(r))
+	}}
+)
+
+func (c probeCodec) Name() string { return c.name }
+
+func (c probeCodec) Detect(data []byte) bool {
+	decoded, err := c.Decode(data)
+	return err == nil && len(decoded) > 0
+}
+
+func (c probeCodec) Decode(data []byte) ([]byte, error) {
+	return readAll(c.name, c.open(bytes.NewReader(data)), nil)
+}
+
+func readAll(format string, reader io.ReadCloser, err error) ([]byte, error) {
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s reader: %w", format, err)
+	}
+	defer reader.Close()
+
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s data: %w", format, err)
+	}
+	return decompressed, nil
+}
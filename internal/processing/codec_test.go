@@ -0,0 +1,277 @@
+package processing
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+func compressWith(t *testing.T, format string, data []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	var writer io.WriteCloser
+	var err error
+	switch format {
+	case "gzip":
+		writer = gzip.NewWriter(&buf)
+	case "zlib":
+		writer = zlib.NewWriter(&buf)
+	case "deflate":
+		writer, err = flate.NewWriter(&buf, flate.BestCompression)
+	case "brotli":
+		writer = brotli.NewWriter(&buf)
+	case "zstd":
+		writer, err = zstd.NewWriter(&buf)
+	default:
+		t.Fatalf("unknown format %s", format)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := writer.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestHTMLExtractor_Extract(t *testing.T) {
+	extractor := NewHTMLExtractor()
+	html := `<div class="product"><span>Ice Cream</span></div>`
+
+	for _, format := range []string{"gzip", "zstd", "zlib", "deflate", "brotli"} {
+		t.Run(format, func(t *testing.T) {
+			compressed := compressWith(t, format, []byte(html))
+
+			result, chain, err := extractor.Extract(compressed)
+			if err != nil {
+				t.Fatalf("Extract failed: %v", err)
+			}
+			if result != html || strings.Join(chain, ",") != format {
+				t.Errorf("Expected %s via %s, got %q via %v", html, format, result, chain)
+			}
+
+			encoded := base64.StdEncoding.EncodeToString(compressed)
+			result, chain, err = extractor.Extract([]byte(encoded))
+			if err != nil {
+				t.Fatalf("Extract of base64 failed: %v", err)
+			}
+			if result != html || strings.Join(chain, ",") != "base64,"+format {
+				t.Errorf("Expected %s via base64,%s, got %q via %v", html, format, result, chain)
+			}
+		})
+	}
+
+	t.Run("plain", func(t *testing.T) {
+		result, chain, err := extractor.Extract([]byte("\xef\xbb\xbf\n" + html))
+		if err != nil || len(chain) != 0 || !strings.HasSuffix(result, html) {
+			t.Errorf("Expected plain HTML back unchanged, got %q via %v, %v", result, chain, err)
+		}
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		data := compressWith(t, "gzip", []byte(base64.StdEncoding.EncodeToString(compressWith(t, "zlib", []byte(html)))))
+
+		result, chain, err := extractor.Extract(data)
+		if err != nil {
+			t.Fatalf("Extract failed: %v", err)
+		}
+		if result != html || strings.Join(chain, ",") != "gzip,base64,zlib" {
+			t.Errorf("Unexpected result %q via %v", result, chain)
+		}
+	})
+}
+
+func TestHTMLExtractor_Extract_Unknown(t *testing.T) {
+	extractor := NewHTMLExtractor()
+
+	_, chain, err := extractor.Extract([]byte(base64.StdEncoding.EncodeToString([]byte("not html at all"))))
+	if err == nil {
+		t.Fatal("Expected an error for data that is not HTML")
+	}
+	if strings.Join(chain, ",") != "base64" || !strings.Contains(err.Error(), "after base64") {
+		t.Errorf("Expected the error to name the base64 layer, got %v via %v", err, chain)
+	}
+}
+
+type reverseCodec struct{}
+
+func (reverseCodec) Name() string { return "reverse" }
+
+func (reverseCodec) Detect(data []byte) bool {
+	return bytes.HasSuffix(bytes.TrimSpace(data), []byte("<"))
+}
+
+func (reverseCodec) Decode(data []byte) ([]byte, error) {
+	reversed := make([]byte, len(data))
+	for i, b := range data {
+		reversed[len(data)-1-i] = b
+	}
+	return reversed, nil
+}
+
+func TestCodecRegistry(t *testing.T) {
+	registry := NewCodecRegistry(DefaultCodecs()...)
+
+	expected := "gzip,zstd,zlib,base64,deflate,brotli"
+	if names := strings.Join(registry.Names(), ","); names != expected {
+		t.Errorf("Expected %s, got %s", expected, names)
+	}
+
+	if _, ok := registry.Lookup("reverse"); ok {
+		t.Error("Expected reverse not to be registered")
+	}
+	registry.Register(reverseCodec{})
+	if _, ok := registry.Lookup("reverse"); !ok {
+		t.Error("Expected reverse to be registered")
+	}
+
+	extractor := &HTMLExtractor{codecs: registry}
+	result, chain, err := extractor.Extract([]byte(">p/<olleh>p<"))
+	if err != nil || result != "<p>hello</p>" || strings.Join(chain, ",") != "reverse" {
+		t.Errorf("Expected the registered codec to be used, got %q via %v, %v", result, chain, err)
+	}
+
+	registry.Register(zlibCodec{})
+	if names := strings.Join(registry.Names(), ","); names != expected+",reverse" {
+		t.Errorf("Expected re-registering zlib to keep its place, got %s", names)
+	}
+}
+
+func TestZlibCodec_Detect(t *testing.T) {
+	tests := []struct {
+		data     []byte
+		expected bool
+	}{
+		{[]byte{0x78, 0x01}, true},
+		{[]byte{0x78, 0x9c}, true},
+		{[]byte{0x78, 0xda}, true},
+		{[]byte{0x78, 0x00}, false},
+		{[]byte{0x1f, 0x8b}, false},
+		{[]byte{0x78}, false},
+	}
+
+	for _, tt := range tests {
+		if got := (zlibCodec{}).Detect(tt.data); got != tt.expected {
+			t.Errorf("Detect(% x): expected %v, got %v", tt.data, tt.expected, got)
+		}
+	}
+}
+
+// prefixCodec recognises data starting with its prefix and strips it.
+type prefixCodec []byte
+
+func (prefixCodec) Name() string { return "prefix" }
+
+func (c prefixCodec) Detect(data []byte) bool { return bytes.HasPrefix(data, c) }
+
+func (c prefixCodec) Decode(data []byte) ([]byte, error) {
+	return bytes.TrimPrefix(data, c), nil
+}
+
+func TestHTMLExtractor_Base64LookingLikeZlib(t *testing.T) {
+	// 0x84 0x20 encodes as hC, which passes the zlib header check
+	prefix := prefixCodec{0x84, 0x20}
+	encoded := base64.StdEncoding.EncodeToString(append([]byte(prefix), "<p>hello</p>"...))
+	if !(zlibCodec{}).Detect([]byte(encoded)) {
+		t.Fatalf("Expected %s to look like a zlib header", encoded[:2])
+	}
+
+	extractor := NewHTMLExtractor()
+	extractor.Codecs().Register(prefix)
+
+	result, chain, err := extractor.Extract([]byte(encoded))
+	if err != nil || result != "<p>hello</p>" || strings.Join(chain, ",") != "base64,prefix" {
+		t.Errorf("Expected the failed zlib decode to fall through to base64, got %q via %v, %v", result, chain, err)
+	}
+}
+
+func TestBase64Codec_Variants(t *testing.T) {
+	// 0xfb 0xff encodes to characters that differ between the alphabets
+	payload := []byte{0xfb, 0xff, 0x01, 0xfb}
+
+	tests := []struct {
+		text    string
+		variant string
+		strict  bool
+	}{
+		{"+/8B+w==", "base64", true},
+		{"+/8B\n+w==\n", "base64", true},
+		{"-_8B-w==", "base64url", false},
+		{"+/8B+w", "base64-unpadded", false},
+		{"-_8B-w", "base64url-unpadded", false},
+		{"-_8B\r\n-w", "base64url-unpadded", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			codec := base64Codec{}
+			if !codec.Detect([]byte(tt.text)) {
+				t.Fatal("Expected the text to be detected")
+			}
+			decoded, variant, err := codec.DecodeVariant([]byte(tt.text))
+			if err != nil {
+				t.Fatalf("DecodeVariant failed: %v", err)
+			}
+			if !bytes.Equal(decoded, payload) || variant != tt.variant {
+				t.Errorf("Expected % x as %s, got % x as %s", payload, tt.variant, decoded, variant)
+			}
+
+			strict := base64Codec{strict: true}
+			_, err = strict.Decode([]byte(tt.text))
+			if detected := strict.Detect([]byte(tt.text)); (detected && err == nil) != tt.strict {
+				t.Errorf("Expected strict acceptance to be %v, detected %v, error %v", tt.strict, detected, err)
+			}
+		})
+	}
+
+	for _, text := range []string{"not html at all", "+/8B-_", "abcde", "ab===", ""} {
+		if (base64Codec{}).Detect([]byte(text)) {
+			t.Errorf("Expected %q not to be detected as base64", text)
+		}
+	}
+}
+
+func TestHTMLExtractor_SetStrictBase64(t *testing.T) {
+	html := "<p>hello</p>"
+	compressed := compressWith(t, "zlib", []byte(html))
+	encoded := base64.RawURLEncoding.EncodeToString(compressed)
+	if encoded == base64.StdEncoding.EncodeToString(compressed) {
+		t.Fatal("Expected the URL-safe, unpadded text to differ from standard base64")
+	}
+
+	extractor := NewHTMLExtractor()
+	if result, err := extractor.ExtractHTML(encoded); err != nil || result != html {
+		t.Errorf("Expected unpadded URL-safe base64 to decode, got %q, %v", result, err)
+	}
+
+	extractor.SetStrictBase64(true)
+	if _, err := extractor.ExtractHTML(encoded); err == nil {
+		t.Error("Expected strict mode to reject URL-safe base64")
+	}
+	if _, chain, err := extractor.Extract([]byte(base64.StdEncoding.EncodeToString(compressed))); err != nil || strings.Join(chain, ",") != "base64,zlib" {
+		t.Errorf("Expected strict mode to decode standard base64 over zlib, got %v, %v", chain, err)
+	}
+	if _, chain, err := extractor.Extract(compressed); err != nil || strings.Join(chain, ",") != "zlib" {
+		t.Errorf("Expected strict mode to decode binary zlib, got %v, %v", chain, err)
+	}
+
+	gzipped := base64.StdEncoding.EncodeToString(compressWith(t, "gzip", []byte(html)))
+	for _, data := range []string{gzipped, html} {
+		if _, err := extractor.ExtractHTML(data); err == nil {
+			t.Errorf("Expected strict mode to reject %q", data)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package processing
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// HTMLEncoding is a way of storing HTML in the rawHtml attribute.
+type HTMLEncoding string
+
+const (
+	// EncodingBase64Zlib is the scraper's original format: a string holding
+	// the base64 text of zlib data, as written by pako.deflate().
+	EncodingBase64Zlib HTMLEncoding = "base64-zlib"
+
+	// EncodingZlib, EncodingGzip and EncodingZstd store compressed bytes in
+	// a binary attribute, avoiding the third that base64 adds.
+	EncodingZlib HTMLEncoding = "zlib"
+	EncodingGzip HTMLEncoding = "gzip"
+	EncodingZstd HTMLEncoding = "zstd"
+
+	// EncodingText stores the HTML itself in a string attribute.
+	EncodingText HTMLEncoding = "text"
+)
+
+var htmlEncodings = []HTMLEncoding{EncodingBase64Zlib, EncodingZlib, EncodingGzip, EncodingZstd, EncodingText}
+
+// ParseHTMLEncoding checks that name is one of the supported encodings.
+func ParseHTMLEncoding(name string) (HTMLEncoding, error) {
+	for _, encoding := range htmlEncodings {
+		if string(encoding) == name {
+			return encoding, nil
+		}
+	}
+
+	names := make([]string, len(htmlEncodings))
+	for i, encoding := range htmlEncodings {
+		names[i] = string(encoding)
+	}
+	return "", fmt.Errorf("unknown HTML encoding %q, expected one of %s", name, strings.Join(names, ", "))
+}
+
+// Binary reports whether the encoding is stored in a binary (B) attribute
+// rather than a string (S).
+func (e HTMLEncoding) Binary() bool {
+	return e == EncodingZlib || e == EncodingGzip || e == EncodingZstd
+}
+
+// DetectHTMLEncoding works out how a stored rawHtml value was written from
+// its first bytes. Binary values are recognised by their compression
+// header; strings are either HTML markup or base64.
+func DetectHTMLEncoding(data []byte, binary bool) HTMLEncoding {
+	if binary {
+		switch {
+		case gzipCodec.Detect(data):
+			return EncodingGzip
+		case zstdCodec.Detect(data):
+			return EncodingZstd
+		default:
+			return EncodingZlib
+		}
+	}
+
+	if isMarkup(data) {
+		return EncodingText
+	}
+	return EncodingBase64Zlib
+}
+
+// EncodeHTML stores html in the given encoding. For the string encodings the
+// result is the attribute's text.
+func (e *HTMLExtractor) EncodeHTML(html string, encoding HTMLEncoding) ([]byte, error) {
+	var buf bytes.Buffer
+
+	switch encoding {
+	case EncodingText:
+		return []byte(html), nil
+	case EncodingZlib, EncodingBase64Zlib:
+		writer, err := zlib.NewWriterLevel(&buf, zlib.BestCompression)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeAndClose(writer, html); err != nil {
+			return nil, fmt.Errorf("failed to compress with zlib: %w", err)
+		}
+	case EncodingGzip:
+		writer, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeAndClose(writer, html); err != nil {
+			return nil, fmt.Errorf("failed to compress with gzip: %w", err)
+		}
+	case EncodingZstd:
+		writer, err := zstd.NewWriter(&buf, zstd.WithEncoderLevel(zstd.SpeedBestCompression))
+		if err != nil {
+			return nil, err
+		}
+		if err := writeAndClose(writer, html); err != nil {
+			return nil, fmt.Errorf("failed to compress with zstd: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown HTML encoding %q", encoding)
+	}
+
+	if encoding == EncodingBase64Zlib {
+		return []byte(base64.StdEncoding.EncodeToString(buf.Bytes())), nil
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeHTML reverses EncodeHTML.
+func (e *HTMLExtractor) DecodeHTML(data []byte, encoding HTMLEncoding) (string, error) {
+	switch encoding {
+	case EncodingText:
+		return string(data), nil
+	case EncodingBase64Zlib:
+		return e.ExtractHTML(string(data))
+	case EncodingZlib:
+		return decodeString(zlibCodec{}, data)
+	case EncodingGzip:
+		return decodeString(gzipCodec, data)
+	case EncodingZstd:
+		return decodeString(zstdCodec, data)
+	default:
+		return "", fmt.Errorf("unknown HTML encoding %q", encoding)
+	}
+}
+
+func decodeString(codec Codec, data []byte) (string, error) {
+	decoded, err := codec.Decode(data)
+	return string(decoded), err
+}
+
+func writeAndClose(writer io.WriteCloser, html string) error {
+	if _, err := io.WriteString(writer, html); err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
+}
@@ -0,0 +1,68 @@
+package processing
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestHTMLExtractor_EncodeDecodeHTML(t *testing.T) {
+	extractor := NewHTMLExtractor()
+	originalHTML := "<html><body>Hello World</body></html>"
+
+	for _, encoding := range htmlEncodings {
+		t.Run(string(encoding), func(t *testing.T) {
+			encoded, err := extractor.EncodeHTML(originalHTML, encoding)
+			if err != nil {
+				t.Fatalf("EncodeHTML failed: %v", err)
+			}
+
+			if detected := DetectHTMLEncoding(encoded, encoding.Binary()); detected != encoding {
+				t.Errorf("Expected %s to be detected, got %s", encoding, detected)
+			}
+
+			decoded, err := extractor.DecodeHTML(encoded, encoding)
+			if err != nil {
+				t.Fatalf("DecodeHTML failed: %v", err)
+			}
+			if decoded != originalHTML {
+				t.Errorf("Expected %s, got %s", originalHTML, decoded)
+			}
+		})
+	}
+}
+
+func TestHTMLExtractor_ExtractHTML_MigratedEncodings(t *testing.T) {
+	extractor := NewHTMLExtractor()
+	originalHTML := "<html><body>Hello World</body></html>"
+
+	// Plain text is returned as it is
+	result, err := extractor.ExtractHTML(originalHTML)
+	if err != nil || result != originalHTML {
+		t.Errorf("Expected plain HTML back, got %q, %v", result, err)
+	}
+
+	// Binary attributes reach the extractor as base64 of their bytes
+	for _, encoding := range []HTMLEncoding{EncodingGzip, EncodingZstd} {
+		encoded, err := extractor.EncodeHTML(originalHTML, encoding)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := extractor.ExtractHTML(base64.StdEncoding.EncodeToString(encoded))
+		if err != nil {
+			t.Errorf("Failed to extract %s data: %v", encoding, err)
+		}
+		if result != originalHTML {
+			t.Errorf("Expected %s, got %s", originalHTML, result)
+		}
+	}
+}
+
+func TestParseHTMLEncoding(t *testing.T) {
+	if encoding, err := ParseHTMLEncoding("zstd"); err != nil || encoding != EncodingZstd {
+		t.Errorf("Expected zstd, got %q, %v", encoding, err)
+	}
+	if _, err := ParseHTMLEncoding("brotli"); err == nil {
+		t.Error("Expected an unknown encoding to be rejected")
+	}
+}
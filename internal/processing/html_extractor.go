@@ -2,16 +2,36 @@ package processing
 
 import (
 	"bytes"
-	"compress/zlib"
-	"encoding/base64"
 	"fmt"
-	"io"
+	"strings"
 )
 
-type HTMLExtractor struct{}
+// maxCodecChain bounds how many layers Extract unwraps before giving up.
+const maxCodecChain = 8
+
+type HTMLExtractor struct {
+	codecs *CodecRegistry
+	strict bool
+}
 
 func NewHTMLExtractor() *HTMLExtractor {
-	return &HTMLExtractor{}
+	return &HTMLExtractor{
+		codecs: NewCodecRegistry(DefaultCodecs()...),
+	}
+}
+
+// SetStrictBase64 makes Extract accept only the format the scraper writes:
+// zlib, wrapped in base64 that StdEncoding decodes unless it is stored as
+// binary. The URL-safe and unpadded base64 variants, the other codecs and
+// plain HTML are all rejected. Validation should use it.
+func (e *HTMLExtractor) SetStrictBase64(strict bool) {
+	e.strict = strict
+}
+
+// Codecs returns the registry Extract detects layers with, to which further
+// codecs can be added.
+func (e *HTMLExtractor) Codecs() *CodecRegistry {
+	return e.codecs
 }
 
 func (e *HTMLExtractor) ExtractHTML(rawHTML string) (string, error) {
@@ -19,23 +39,98 @@ func (e *HTMLExtractor) ExtractHTML(rawHTML string) (string, error) {
 		return "", fmt.Errorf("empty rawHTML string")
 	}
 
-	// Step 1: Decode from base64
-	compressedData, err := base64.StdEncoding.DecodeString(rawHTML)
-	if err != nil {
-		return "", fmt.Errorf("failed to decode base64: %w", err)
+	html, _, err := e.Extract([]byte(rawHTML))
+	return html, err
+}
+
+// Extract unwraps data one layer at a time, detecting each layer's codec
+// from its first bytes, until HTML markup is reached. It returns the HTML
+// and the names of the codecs it decoded, outermost first; HTML stored as
+// plain text comes back with an empty chain.
+func (e *HTMLExtractor) Extract(data []byte) (string, []string, error) {
+	if e.strict {
+		return extractStrict(data)
 	}
 
-	// Step 2: Decompress using zlib (this is what pako uses)
-	reader, err := zlib.NewReader(bytes.NewReader(compressedData))
-	if err != nil {
-		return "", fmt.Errorf("failed to create zlib reader: %w", err)
+	var chain []string
+
+	for {
+		if isMarkup(data) {
+			return string(data), chain, nil
+		}
+		if len(chain) == maxCodecChain {
+			return "", chain, fmt.Errorf("no HTML found after %d layers (%s)", len(chain), strings.Join(chain, ", "))
+		}
+
+		codecs := e.codecs.DetectAll(data)
+		if len(codecs) == 0 {
+			return "", chain, fmt.Errorf("failed to detect encoding%s: data starts with % x", describeChain(chain), data[:min(len(data), 8)])
+		}
+
+		decoded, name, err := decodeFirst(codecs, data)
+		if err != nil {
+			return "", chain, err
+		}
+
+		chain = append(chain, name)
+		data = decoded
+	}
+}
+
+// extractStrict decodes zlib data, first taking off a standard base64 layer
+// unless data already starts with a zlib header.
+func extractStrict(data []byte) (string, []string, error) {
+	var chain []string
+	if !(zlibCodec{}).Detect(data) {
+		decoded, err := base64Codec{strict: true}.Decode(data)
+		if err != nil {
+			return "", chain, err
+		}
+		chain = append(chain, "base64")
+		data = decoded
 	}
-	defer reader.Close()
 
-	decompressed, err := io.ReadAll(reader)
+	html, err := zlibCodec{}.Decode(data)
 	if err != nil {
-		return "", fmt.Errorf("failed to decompress zlib data: %w", err)
+		return "", chain, err
+	}
+	return string(html), append(chain, "zlib"), nil
+}
+
+// decodeFirst decodes data with the first of codecs that succeeds,
+// returning the first codec's error when none do.
+func decodeFirst(codecs []Codec, data []byte) ([]byte, string, error) {
+	var firstErr error
+	for _, codec := range codecs {
+		decoded, name, err := decodeLayer(codec, data)
+		if err == nil {
+			return decoded, name, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
 	}
+	return nil, "", firstErr
+}
+
+// decodeLayer decodes one layer with codec and names it for the chain.
+func decodeLayer(codec Codec, data []byte) ([]byte, string, error) {
+	if variant, ok := codec.(VariantDecoder); ok {
+		return variant.DecodeVariant(data)
+	}
+	decoded, err := codec.Decode(data)
+	return decoded, codec.Name(), err
+}
 
-	return string(decompressed), nil
+// isMarkup reports whether data is HTML text rather than an encoded layer.
+func isMarkup(data []byte) bool {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("<"))
+}
+
+func describeChain(chain []string) string {
+	if len(chain) == 0 {
+		return ""
+	}
+	return " after " + strings.Join(chain, ", ")
 }
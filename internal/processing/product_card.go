@@ -0,0 +1,165 @@
+package processing
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	currentPriceLabel  = "Current price:"
+	originalPriceLabel = "Original Price"
+)
+
+// ProductCard holds the fields of a product card as rendered by the store:
+// a group labelled "Product" wrapping a link to the product page, its image,
+// the prices, a name heading and the size.
+type ProductCard struct {
+	Name          string
+	CurrentPrice  string
+	OriginalPrice string // empty unless the product is on sale
+	Size          string
+	ImageSrcset   string
+	Link          string
+}
+
+// ParseProductCard reads the fields of the product card in markup. The
+// store's class names are generated, so fields are found by role, label and
+// visible text instead: the name is the heading, the current price is read
+// from the screen-reader text "Current price: $8.99", the original price
+// follows the "Original Price" label, and the size is the element whose
+// title repeats its text, such as <div title="1 each">1 each</div>.
+func ParseProductCard(markup string) (ProductCard, error) {
+	doc, err := html.Parse(strings.NewReader(markup))
+	if err != nil {
+		return ProductCard{}, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	group := findNode(doc, func(n *html.Node) bool {
+		return attr(n, "role") == "group" && attr(n, "aria-label") == "Product"
+	})
+	if group == nil {
+		return ProductCard{}, fmt.Errorf("no product card found")
+	}
+
+	var card ProductCard
+	findNode(group, func(n *html.Node) bool {
+		card.read(n)
+		return false
+	})
+	return card, nil
+}
+
+// read fills in the fields n provides, keeping the first value found for
+// each.
+func (c *ProductCard) read(n *html.Node) {
+	if n.Type == html.TextNode {
+		text := collapseSpace(n.Data)
+		switch {
+		case c.CurrentPrice == "" && strings.HasPrefix(text, currentPriceLabel):
+			c.CurrentPrice = strings.TrimSpace(strings.TrimPrefix(text, currentPriceLabel))
+		case c.OriginalPrice == "" && strings.EqualFold(text, originalPriceLabel) && n.Parent != nil:
+			if price := nextElement(n.Parent); price != nil {
+				c.OriginalPrice = textContent(price)
+			}
+		}
+		return
+	}
+	if n.Type != html.ElementNode {
+		return
+	}
+
+	switch {
+	case n.DataAtom == atom.A && c.Link == "":
+		c.Link = attr(n, "href")
+	case n.DataAtom == atom.Img && (c.ImageSrcset == "" || attr(n, "data-testid") == "item-card-image"):
+		if srcset := attr(n, "srcset"); srcset != "" {
+			c.ImageSrcset = srcset
+		}
+	case attr(n, "role") == "heading" && c.Name == "":
+		c.Name = textContent(n)
+	case c.Size == "" && attr(n, "title") != "" && collapseSpace(attr(n, "title")) == textContent(n):
+		c.Size = textContent(n)
+	}
+}
+
+// findNode walks the tree under n depth first and returns the first node
+// match accepts.
+func findNode(n *html.Node, match func(*html.Node) bool) *html.Node {
+	if match(n) {
+		return n
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if found := findNode(child, match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func nextElement(n *html.Node) *html.Node {
+	for sibling := n.NextSibling; sibling != nil; sibling = sibling.NextSibling {
+		if sibling.Type == html.ElementNode {
+			return sibling
+		}
+	}
+	return nil
+}
+
+// textContent returns the text under n with runs of whitespace collapsed.
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	findNode(n, func(n *html.Node) bool {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		return false
+	})
+	return collapseSpace(b.String())
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// SrcsetURLs returns the image URLs of a srcset attribute in order, without
+// their width or density descriptors. Candidates are separated by commas,
+// but the store's image URLs contain commas of their own inside
+// parentheses, as in filters:fill(FFFFFF,true), so those do not split.
+func SrcsetURLs(srcset string) []string {
+	var urls []string
+	add := func(candidate string) {
+		if fields := strings.Fields(candidate); len(fields) > 0 {
+			urls = append(urls, fields[0])
+		}
+	}
+
+	depth, start := 0, 0
+	for i, c := range srcset {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth = max(depth-1, 0)
+		case ',':
+			if depth == 0 {
+				add(srcset[start:i])
+				start = i + 1
+			}
+		}
+	}
+	add(srcset[start:])
+
+	return urls
+}
@@ -0,0 +1,76 @@
+package processing
+
+import "testing"
+
+func TestParseProductCard(t *testing.T) {
+	markup := `<div class="e-13udsys"><h3><div aria-label="Product" role="group" class="e-fsno8i">
+		<a role="button" href="https://store.example/products/1-ice">
+			<img srcset="https://img.example/ice-197.png, https://img.example/ice-394.png 2x" data-testid="item-card-image" alt="">
+			<span class="screen-reader-only">Current price: $2.29</span>
+			<span aria-hidden="true">$</span><span aria-hidden="true">2</span><span aria-hidden="true">29</span>
+			<div><span>Original Price</span><p><span>$2.99</span></p></div>
+			<div role="heading" aria-level="4"><div>Arctic   Glacier
+				Bag of Ice</div></div>
+			<div><div title="7 lb">7 lb</div></div>
+		</a>
+		<button aria-label="Add 1 item Arctic Glacier Bag of Ice" title="Add">Add</button>
+	</div></h3></div>`
+
+	card, err := ParseProductCard(markup)
+	if err != nil {
+		t.Fatalf("ParseProductCard failed: %v", err)
+	}
+
+	expected := ProductCard{
+		Name:          "Arctic Glacier Bag of Ice",
+		CurrentPrice:  "$2.29",
+		OriginalPrice: "$2.99",
+		Size:          "7 lb",
+		ImageSrcset:   "https://img.example/ice-197.png, https://img.example/ice-394.png 2x",
+		Link:          "https://store.example/products/1-ice",
+	}
+	if card != expected {
+		t.Errorf("Expected %+v, got %+v", expected, card)
+	}
+}
+
+func TestParseProductCard_NotOnSale(t *testing.T) {
+	card, err := ParseProductCard(`<div role="group" aria-label="Product"><a href="/p/2"><span>Current price: $1.00</span><div role="heading">Lime</div></a></div>`)
+	if err != nil {
+		t.Fatalf("ParseProductCard failed: %v", err)
+	}
+	if card.Name != "Lime" || card.CurrentPrice != "$1.00" || card.OriginalPrice != "" || card.Size != "" || card.Link != "/p/2" {
+		t.Errorf("Unexpected card: %+v", card)
+	}
+}
+
+func TestParseProductCard_NoCard(t *testing.T) {
+	for _, markup := range []string{"", "<html><body>Hello World</body></html>", `<div role="group" aria-label="Cart"></div>`} {
+		if _, err := ParseProductCard(markup); err == nil {
+			t.Errorf("Expected %q to have no product card", markup)
+		}
+	}
+}
+
+func TestSrcsetURLs(t *testing.T) {
+	srcset := "https://img.example/197x197/filters:fill(FFFFFF,true):format(jpg)/a.png,https://img.example/296x296/filters:fill(FFFFFF,true):format(jpg)/a.png 1.5x, /b.png 2x"
+
+	urls := SrcsetURLs(srcset)
+	expected := []string{
+		"https://img.example/197x197/filters:fill(FFFFFF,true):format(jpg)/a.png",
+		"https://img.example/296x296/filters:fill(FFFFFF,true):format(jpg)/a.png",
+		"/b.png",
+	}
+	if len(urls) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, urls)
+	}
+	for i := range expected {
+		if urls[i] != expected[i] {
+			t.Errorf("URL %d: expected %s, got %s", i, expected[i], urls[i])
+		}
+	}
+
+	if urls := SrcsetURLs(" "); len(urls) != 0 {
+		t.Errorf("Expected no URLs, got %v", urls)
+	}
+}
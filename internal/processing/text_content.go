@@ -0,0 +1,296 @@
+package processing
+
+import (
+	"regexp"
+	"strings"
+)
+
+// TextContent holds the fields of a product card's flattened accessibility
+// text, as stored in rawTextContent:
+//
+//	HEADING : Current price : $ 8.99 $ 8 99 Original Price $ 9.99 Bass Comb 1 each Add
+type TextContent struct {
+	CurrentPrice  string
+	OriginalPrice string // empty unless the product is on sale
+	Name          string
+	Quantity      string
+	Unit          string
+	Stock         string // such as "Many in stock"
+	CallToAction  string // such as "Add"
+
+	// Uncertain is set when part of the text did not follow the expected
+	// layout, so some fields may be missing or hold the wrong words.
+	Uncertain bool
+}
+
+type textTokenKind int
+
+const (
+	textWord textTokenKind = iota
+	textColon
+	textCurrency
+	textNumber
+)
+
+type textToken struct {
+	kind textTokenKind
+	text string
+}
+
+var numberPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// tokenizeText splits text at whitespace, separating colons and currency
+// symbols from the words they are attached to.
+func tokenizeText(text string) []textToken {
+	var tokens []textToken
+	for _, field := range strings.Fields(text) {
+		for field != "" {
+			switch {
+			case field[0] == ':':
+				tokens = append(tokens, textToken{textColon, ":"})
+				field = field[1:]
+			case field[0] == '$':
+				tokens = append(tokens, textToken{textCurrency, "$"})
+				field = field[1:]
+			default:
+				word := field
+				if i := strings.IndexAny(field, ":$"); i > 0 {
+					word = field[:i]
+				}
+				kind := textWord
+				if numberPattern.MatchString(word) {
+					kind = textNumber
+				}
+				tokens = append(tokens, textToken{kind, word})
+				field = field[len(word):]
+			}
+		}
+	}
+	return tokens
+}
+
+// callsToAction are the button labels that end a card, longest first.
+var callsToAction = [][]string{
+	{"Add", "to", "cart"},
+	{"Choose", "options"},
+	{"Notify", "me"},
+	{"Add"},
+	{"Choose"},
+}
+
+// units are the words that may follow a size's quantity, as in "7 lb",
+// "1 each" or "12 fl oz".
+var units = map[string]bool{
+	"each": true, "ct": true, "count": true, "pk": true, "pack": true, "dozen": true, "bunch": true,
+	"lb": true, "lbs": true, "oz": true, "fl": true, "g": true, "kg": true, "mg": true,
+	"ml": true, "l": true, "gal": true, "qt": true, "pt": true, "in": true, "ft": true,
+	"x": true, "sq": true, "per": true,
+}
+
+// textParser consumes tokens from both ends: the labelled prices from the
+// front and the call to action, stock and size from the back, leaving the
+// name in between.
+type textParser struct {
+	tokens []textToken
+	start  int
+	end    int
+	result TextContent
+}
+
+// ParseTextContent reads the fields of a rawTextContent string. Words it
+// cannot place are left in the name, and the result is marked Uncertain
+// when the text does not follow the card's layout.
+func ParseTextContent(text string) TextContent {
+	tokens := tokenizeText(text)
+	p := &textParser{tokens: tokens, end: len(tokens)}
+
+	p.acceptWords("HEADING")
+	p.accept(textColon)
+
+	if !p.acceptWords("Current", "price") {
+		p.result.Uncertain = true
+	}
+	p.accept(textColon)
+	if price, ok := p.price(); ok {
+		p.result.CurrentPrice = price
+		p.renderedPrice(price)
+	} else {
+		p.result.Uncertain = true
+	}
+
+	if p.acceptWords("Original", "Price") {
+		price, ok := p.price()
+		if !ok {
+			p.result.Uncertain = true
+		}
+		p.result.OriginalPrice = price
+	}
+
+	p.callToAction()
+	p.stock()
+	p.size()
+
+	name := p.tokens[p.start:p.end]
+	words := make([]string, len(name))
+	for i, token := range name {
+		if token.kind == textCurrency || token.kind == textColon {
+			p.result.Uncertain = true
+		}
+		words[i] = token.text
+	}
+	p.result.Name = strings.Join(words, " ")
+	if p.result.Name == "" {
+		p.result.Uncertain = true
+	}
+
+	return p.result
+}
+
+func (p *textParser) peek(offset int) (textToken, bool) {
+	if p.start+offset >= p.end {
+		return textToken{}, false
+	}
+	return p.tokens[p.start+offset], true
+}
+
+func (p *textParser) accept(kind textTokenKind) bool {
+	if token, ok := p.peek(0); ok && token.kind == kind {
+		p.start++
+		return true
+	}
+	return false
+}
+
+// acceptWords consumes words if the next tokens are exactly those words,
+// ignoring case.
+func (p *textParser) acceptWords(words ...string) bool {
+	for i, word := range words {
+		token, ok := p.peek(i)
+		if !ok || token.kind != textWord || !strings.EqualFold(token.text, word) {
+			return false
+		}
+	}
+	p.start += len(words)
+	return true
+}
+
+// price consumes a currency symbol and amount, returning them as "$8.99".
+func (p *textParser) price() (string, bool) {
+	currency, ok := p.peek(0)
+	if !ok || currency.kind != textCurrency {
+		return "", false
+	}
+	amount, ok := p.peek(1)
+	if !ok || amount.kind != textNumber {
+		return "", false
+	}
+	p.start += 2
+	return currency.text + amount.text, true
+}
+
+// renderedPrice consumes the visual form of price that follows the screen
+// reader's, dollars and cents as separate numbers: "$ 8 99" for $8.99.
+func (p *textParser) renderedPrice(price string) {
+	currency, ok := p.peek(0)
+	if !ok || currency.kind != textCurrency {
+		return
+	}
+	dollars, _ := p.peek(1)
+	cents, _ := p.peek(2)
+	if dollars.kind != textNumber || cents.kind != textNumber || "$"+dollars.text+"."+cents.text != price {
+		// Another price where the rendered one belongs
+		p.result.Uncertain = true
+		return
+	}
+	p.start += 3
+}
+
+// fromEnd returns the token offset places before the end of the unparsed
+// tokens.
+func (p *textParser) fromEnd(offset int) (textToken, bool) {
+	if p.end-1-offset < p.start {
+		return textToken{}, false
+	}
+	return p.tokens[p.end-1-offset], true
+}
+
+// endsWith reports whether the unparsed tokens end with words, ignoring
+// case.
+func (p *textParser) endsWith(words ...string) bool {
+	for i := range words {
+		token, ok := p.fromEnd(len(words) - 1 - i)
+		if !ok || !strings.EqualFold(token.text, words[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *textParser) takeEnd(n int) string {
+	words := make([]string, n)
+	for i := range words {
+		words[i] = p.tokens[p.end-n+i].text
+	}
+	p.end -= n
+	return strings.Join(words, " ")
+}
+
+func (p *textParser) callToAction() {
+	for _, words := range callsToAction {
+		if p.endsWith(words...) {
+			p.result.CallToAction = p.takeEnd(len(words))
+			return
+		}
+	}
+}
+
+// stockLevels are the words that may qualify "in stock", as in "Many in
+// stock".
+var stockLevels = map[string]bool{"many": true, "few": true, "some": true, "likely": true}
+
+// stock consumes a stock level such as "Many in stock", "Only 3 in stock",
+// "In stock", "Only 3 left", "Low stock" or "Out of stock".
+func (p *textParser) stock() {
+	switch {
+	case p.endsWith("in", "stock"):
+		n := 2
+		if level, ok := p.fromEnd(2); ok && level.kind == textWord && stockLevels[strings.ToLower(level.text)] {
+			n = 3
+		} else if ok && level.kind == textNumber {
+			if only, ok := p.fromEnd(3); ok && strings.EqualFold(only.text, "Only") {
+				n = 4
+			}
+		}
+		p.result.Stock = p.takeEnd(n)
+	case p.endsWith("Out", "of", "stock"):
+		p.result.Stock = p.takeEnd(3)
+	case p.endsWith("Low", "stock"):
+		p.result.Stock = p.takeEnd(2)
+	case p.endsWith("left"):
+		if count, ok := p.fromEnd(1); ok && count.kind == textNumber {
+			if only, ok := p.fromEnd(2); ok && strings.EqualFold(only.text, "Only") {
+				p.result.Stock = p.takeEnd(3)
+			}
+		}
+	}
+}
+
+// size consumes a quantity and the unit words after it at the end of the
+// unparsed tokens, such as "7 lb" or "12 fl oz".
+func (p *textParser) size() {
+	n := 0
+	for {
+		token, ok := p.fromEnd(n)
+		if !ok || token.kind != textWord || !units[strings.ToLower(token.text)] {
+			break
+		}
+		n++
+	}
+	quantity, ok := p.fromEnd(n)
+	if n == 0 || !ok || quantity.kind != textNumber {
+		return
+	}
+
+	p.result.Unit = p.takeEnd(n)
+	p.result.Quantity = p.takeEnd(1)
+}
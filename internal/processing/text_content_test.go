@@ -0,0 +1,69 @@
+package processing
+
+import "testing"
+
+func TestParseTextContent(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected TextContent
+	}{
+		{
+			"HEADING : Current price : $ 8.99 $ 8 99 Original Price $ 9.99 Bass Comb-Large Combination-Wood 1 each Add",
+			TextContent{CurrentPrice: "$8.99", OriginalPrice: "$9.99", Name: "Bass Comb-Large Combination-Wood", Quantity: "1", Unit: "each", CallToAction: "Add"},
+		},
+		{
+			"HEADING : Current price : $ 2.29 $ 2 29 Arctic Glacier Bag of Ice 7 lb Many in stock Add",
+			TextContent{CurrentPrice: "$2.29", Name: "Arctic Glacier Bag of Ice", Quantity: "7", Unit: "lb", Stock: "Many in stock", CallToAction: "Add"},
+		},
+		{
+			"HEADING : Current price : $ 2.29 $ 2 29 Arctic Glacier Bag of Ice 7 lb In stock Add",
+			TextContent{CurrentPrice: "$2.29", Name: "Arctic Glacier Bag of Ice", Quantity: "7", Unit: "lb", Stock: "In stock", CallToAction: "Add"},
+		},
+		{
+			"HEADING : Current price : $ 1.99 $ 1 99 Lemons 6 ct Only 2 in stock Add",
+			TextContent{CurrentPrice: "$1.99", Name: "Lemons", Quantity: "6", Unit: "ct", Stock: "Only 2 in stock", CallToAction: "Add"},
+		},
+		{
+			"Current price: $4.50 $4 50 Organic Whole Milk 0.5 gal Only 3 left Add to cart",
+			TextContent{CurrentPrice: "$4.50", Name: "Organic Whole Milk", Quantity: "0.5", Unit: "gal", Stock: "Only 3 left", CallToAction: "Add to cart"},
+		},
+		{
+			"HEADING : Current price : $ 3.99 $ 3 99 Sparkling Water 12 fl oz Out of stock",
+			TextContent{CurrentPrice: "$3.99", Name: "Sparkling Water", Quantity: "12", Unit: "fl oz", Stock: "Out of stock"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := ParseTextContent(tt.text); got != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseTextContent_Uncertain(t *testing.T) {
+	tests := []string{
+		"",
+		"Bass Comb 1 each Add",
+		"HEADING : Current price : 8.99 Bass Comb Add",
+		"HEADING : Current price : $ 8.99 $ 7 99 Bass Comb Add",
+		"HEADING : Current price : $ 8.99 Original Price Bass Comb Add",
+		"HEADING : Current price : $ 8.99 1 each Add",
+		"HEADING : Current price : $ 8.99 Bass Comb $ 2 off Add",
+	}
+
+	for _, text := range tests {
+		t.Run(text, func(t *testing.T) {
+			if result := ParseTextContent(text); !result.Uncertain {
+				t.Errorf("Expected the result to be uncertain, got %+v", result)
+			}
+		})
+	}
+
+	// Fields before the point of confusion are still read
+	result := ParseTextContent("HEADING : Current price : $ 8.99 $ 7 99 Bass Comb Add")
+	if result.CurrentPrice != "$8.99" || result.CallToAction != "Add" {
+		t.Errorf("Expected the price and call to action to be read, got %+v", result)
+	}
+}